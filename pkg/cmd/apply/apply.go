@@ -1,6 +1,8 @@
 package apply
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"k8s.io/kubectl/pkg/util/templates"
 
@@ -35,6 +37,21 @@ func NewCmdApply() *cobra.Command {
 	
 		# Skip interactive approval of plan details before applying
 		kusion apply --yes
+
+		# Revert resources applied successfully if any resource fails
+		kusion apply --rollback-on-failure
+
+		# Apply exactly the plan saved by "kusion preview --out plan.kplan"
+		kusion apply --plan plan.kplan
+
+		# Wait for resources to be ready before applying resources depending on them
+		kusion apply --wait --wait-timeout=10m
+
+		# Overwrite fields managed by others when Kubernetes resources are applied server-side
+		kusion apply --force-conflicts
+
+		# Apply only the matched resources and their dependencies
+		kusion apply --target "apps/v1:Deployment:default:*"
 		
 		# Apply without output style and color
 		kusion apply --no-style=true`)
@@ -65,6 +82,16 @@ func NewCmdApply() *cobra.Command {
 		i18n.T("Preview the execution effect (always successful) without actually applying the changes"))
 	cmd.Flags().BoolVarP(&o.Watch, "watch", "", false,
 		i18n.T("After creating/updating/deleting the requested object, watch for changes"))
+	cmd.Flags().BoolVarP(&o.Wait, "wait", "", false,
+		i18n.T("Wait for each resource to be ready, such as a Deployment completing its rollout, before applying resources depending on it"))
+	cmd.Flags().DurationVarP(&o.WaitTimeout, "wait-timeout", "", 5*time.Minute,
+		i18n.T("The max time to wait for each resource to be ready, combined use with flag `--wait`"))
+	cmd.Flags().BoolVarP(&o.RollbackOnFailure, "rollback-on-failure", "", false,
+		i18n.T("Revert resources applied successfully to the prior state in reverse dependency order if any resource fails"))
+	cmd.Flags().BoolVarP(&o.ForceConflicts, "force-conflicts", "", false,
+		i18n.T("Take the ownership of fields managed by others when applying Kubernetes resources server-side"))
+	cmd.Flags().StringVarP(&o.Plan, "plan", "", "",
+		i18n.T("Apply exactly the changes in the plan file saved by `kusion preview --out`, and refuse if the state has been modified since then"))
 
 	return cmd
 }
@@ -1,11 +1,14 @@
 package apply
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/pterm/pterm"
@@ -23,18 +26,27 @@ import (
 	"kusionstack.io/kusion/pkg/projectstack"
 	"kusionstack.io/kusion/pkg/status"
 	"kusionstack.io/kusion/pkg/util/pretty"
+	"kusionstack.io/kusion/pkg/util/signals"
 )
 
 // Options defines flags for the `apply` command
 type Options struct {
 	previewcmd.Options
 	Flag
+
+	// plan is the saved plan loaded from the file specified by the flag `--plan`
+	plan *opsmodels.Plan
 }
 
 type Flag struct {
-	Yes    bool
-	DryRun bool
-	Watch  bool
+	Yes               bool
+	DryRun            bool
+	Watch             bool
+	Wait              bool
+	WaitTimeout       time.Duration
+	Plan              string
+	RollbackOnFailure bool
+	ForceConflicts    bool
 }
 
 // NewApplyOptions returns a new ApplyOptions instance
@@ -49,7 +61,24 @@ func (o *Options) Complete(args []string) {
 }
 
 func (o *Options) Validate() error {
-	return o.Options.Validate()
+	if err := o.Options.Validate(); err != nil {
+		return err
+	}
+	if o.Wait && o.WaitTimeout <= 0 {
+		return errors.New("wait-timeout must be positive when waiting for resources to be ready")
+	}
+	if o.Plan != "" && (o.SpecFile != "" || len(o.Targets) != 0) {
+		return errors.New("--spec-file and --target can't be used with --plan, they are decided by the plan")
+	}
+	return nil
+}
+
+// readinessTimeout returns the max time to wait for each resource to be ready, and 0 means not to wait
+func (o *Options) readinessTimeout() time.Duration {
+	if !o.Wait {
+		return 0
+	}
+	return o.WaitTimeout
 }
 
 func (o *Options) Run() error {
@@ -79,7 +108,12 @@ func (o *Options) Run() error {
 
 	// Generate Spec
 	var sp *models.Spec
-	if o.SpecFile != "" {
+	if o.Plan != "" {
+		o.plan, err = loadPlan(o.Plan, project, stack)
+		if err == nil {
+			sp = o.plan.Spec
+		}
+	} else if o.SpecFile != "" {
 		sp, err = spec.GenerateSpecFromFile(o.SpecFile)
 	} else {
 		sp, err = spec.GenerateSpecWithSpinner(options, project, stack)
@@ -100,10 +134,19 @@ func (o *Options) Run() error {
 		return err
 	}
 
-	// Compute changes for preview
-	changes, err := previewcmd.Preview(&o.Options, stateStorage, sp, project, stack)
-	if err != nil {
-		return err
+	// Compute changes for preview, or use the changes in the plan directly
+	var changes *opsmodels.Changes
+	if o.plan != nil {
+		changes = opsmodels.NewChanges(project, stack, o.plan.ChangeOrder)
+		// policies may have changed since the plan was saved
+		if err = previewcmd.CheckPolicies(&o.Options, sp, changes); err != nil {
+			return err
+		}
+	} else {
+		changes, err = previewcmd.Preview(&o.Options, stateStorage, sp, project, stack)
+		if err != nil {
+			return err
+		}
 	}
 
 	if allUnChange(changes) {
@@ -200,16 +243,44 @@ func Apply(
 		return fmt.Errorf("no secret store is provided")
 	}
 
+	// Cancel the apply operation on interrupts, and resources completed are still saved in the State
+	ctx, stop := signals.NotifyContext(context.Background())
+	defer stop()
+
 	// Construct the apply operation
 	ac := &operation.ApplyOperation{
 		Operation: opsmodels.Operation{
-			Stack:        changes.Stack(),
-			StateStorage: storage,
-			MsgCh:        make(chan opsmodels.Message),
-			SecretStores: project.SecretStores,
-			IgnoreFields: o.IgnoreFields,
+			Stack:             changes.Stack(),
+			StateStorage:      storage,
+			MsgCh:             make(chan opsmodels.Message),
+			SecretStores:      project.SecretStores,
+			IgnoreFields:      o.IgnoreFields,
+			Parallelism:       o.Parallelism,
+			FailurePolicy:     opsmodels.FailurePolicy(o.FailurePolicy),
+			ReadinessTimeout:  o.readinessTimeout(),
+			RollbackOnFailure: o.RollbackOnFailure,
+			ForceConflicts:    o.ForceConflicts,
+			Ctx:               ctx,
+		},
+	}
+	request := &operation.ApplyRequest{
+		Request: opsmodels.Request{
+			Tenant:   changes.Project().Tenant,
+			Project:  changes.Project(),
+			Stack:    changes.Stack(),
+			Cluster:  o.Arguments["cluster"],
+			Operator: o.Operator,
+			Spec:     planResources,
+			Targets:  o.Targets,
 		},
 	}
+	// Apply exactly the changes in the plan if the State has not been modified since it was saved
+	if o.plan != nil {
+		ac.ChangeOrder = o.plan.ChangeOrder
+		request.Cluster = o.plan.Cluster
+		request.Targets = o.plan.Targets
+		request.ExpectedSerial = &o.plan.Serial
+	}
 
 	// Line summary
 	var ls lineSummary
@@ -248,13 +319,13 @@ func Apply(
 					var title string
 					if changeStep.Action == opsmodels.UnChanged {
 						title = fmt.Sprintf("%s %s, %s",
-							changeStep.Action.String(),
+							changeStep.ActionString(),
 							pterm.Bold.Sprint(changeStep.ID),
 							strings.ToLower(string(opsmodels.Skip)),
 						)
 					} else {
 						title = fmt.Sprintf("%s %s %s",
-							changeStep.Action.String(),
+							changeStep.ActionString(),
 							pterm.Bold.Sprint(changeStep.ID),
 							strings.ToLower(string(msg.OpResult)),
 						)
@@ -262,14 +333,25 @@ func Apply(
 					pterm.Success.WithWriter(out).Println(title)
 					progressbar.UpdateTitle(title)
 					progressbar.Increment()
-					ls.Count(changeStep.Action)
+					if msg.OpResult == opsmodels.Success {
+						ls.Count(changeStep.Action)
+					}
 				case opsmodels.Failed:
 					title := fmt.Sprintf("%s %s %s",
-						changeStep.Action.String(),
+						changeStep.ActionString(),
 						pterm.Bold.Sprint(changeStep.ID),
 						strings.ToLower(string(msg.OpResult)),
 					)
 					pterm.Error.WithWriter(out).Printf("%s\n", title)
+				case opsmodels.Retry:
+					title := fmt.Sprintf("%s %s failed with a transient error, %v",
+						changeStep.Action.Ing(),
+						pterm.Bold.Sprint(changeStep.ID),
+						msg.OpErr,
+					)
+					pterm.Warning.WithWriter(out).Println(title)
+				case opsmodels.Progress:
+					pterm.Info.WithWriter(out).Printfln("%s: %s", pterm.Bold.Sprint(changeStep.ID), msg.Detail)
 				default:
 					title := fmt.Sprintf("%s %s %s",
 						changeStep.Action.Ing(),
@@ -284,6 +366,10 @@ func Apply(
 
 	if o.DryRun {
 		for _, r := range planResources.Resources {
+			// resources out of targets are not in changes
+			if changes.Get(r.ResourceKey()) == nil {
+				continue
+			}
 			ac.MsgCh <- opsmodels.Message{
 				ResourceID: r.ResourceKey(),
 				OpResult:   opsmodels.Success,
@@ -292,18 +378,7 @@ func Apply(
 		}
 		close(ac.MsgCh)
 	} else {
-		// parse cluster in arguments
-		cluster := o.Arguments["cluster"]
-		_, st := ac.Apply(&operation.ApplyRequest{
-			Request: opsmodels.Request{
-				Tenant:   changes.Project().Tenant,
-				Project:  changes.Project(),
-				Stack:    changes.Stack(),
-				Cluster:  cluster,
-				Operator: o.Operator,
-				Spec:     planResources,
-			},
-		})
+		_, st := ac.Apply(request)
 		if status.IsErr(st) {
 			return fmt.Errorf("apply failed, status:\n%v", st)
 		}
@@ -312,7 +387,8 @@ func Apply(
 	// Wait for msgCh closed
 	wg.Wait()
 	// Print summary
-	pterm.Fprintln(out, fmt.Sprintf("Apply complete! Resources: %d created, %d updated, %d deleted.", ls.created, ls.updated, ls.deleted))
+	pterm.Fprintln(out, fmt.Sprintf("Apply complete! Resources: %d created, %d updated, %d replaced, %d deleted.",
+		ls.created, ls.updated, ls.replaced, ls.deleted))
 	return nil
 }
 
@@ -344,7 +420,7 @@ func Watch(
 	// Filter out unchanged resources
 	toBeWatched := models.Resources{}
 	for _, res := range planResources.Resources {
-		if changes.ChangeOrder.ChangeSteps[res.ResourceKey()].Action != opsmodels.UnChanged {
+		if step, ok := changes.ChangeOrder.ChangeSteps[res.ResourceKey()]; ok && step.Action != opsmodels.UnChanged {
 			toBeWatched = append(toBeWatched, res)
 		}
 	}
@@ -365,8 +441,21 @@ func Watch(
 	return nil
 }
 
+// loadPlan loads the plan file, and makes sure it is saved for the stack to apply
+func loadPlan(path string, project *projectstack.Project, stack *projectstack.Stack) (*opsmodels.Plan, error) {
+	plan, err := opsmodels.LoadPlan(path)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Project != project.Name || plan.Stack != stack.Name {
+		return nil, fmt.Errorf("the plan is saved for stack %s/%s, but the current stack is %s/%s",
+			plan.Project, plan.Stack, project.Name, stack.Name)
+	}
+	return plan, nil
+}
+
 type lineSummary struct {
-	created, updated, deleted int
+	created, updated, deleted, replaced int
 }
 
 func (ls *lineSummary) Count(op opsmodels.ActionType) {
@@ -377,6 +466,8 @@ func (ls *lineSummary) Count(op opsmodels.ActionType) {
 		ls.updated++
 	case opsmodels.Delete:
 		ls.deleted++
+	case opsmodels.Replace:
+		ls.replaced++
 	}
 }
 
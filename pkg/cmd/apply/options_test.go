@@ -1,22 +1,27 @@
 package apply
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/bytedance/mockey"
 	"github.com/stretchr/testify/assert"
 
+	previewcmd "kusionstack.io/kusion/pkg/cmd/preview"
 	"kusionstack.io/kusion/pkg/cmd/spec"
 	"kusionstack.io/kusion/pkg/engine"
 	"kusionstack.io/kusion/pkg/engine/operation"
 	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
 	"kusionstack.io/kusion/pkg/engine/runtime"
+	runtimeinit "kusionstack.io/kusion/pkg/engine/runtime/init"
 	"kusionstack.io/kusion/pkg/engine/runtime/kubernetes"
 	"kusionstack.io/kusion/pkg/engine/states/local"
 	"kusionstack.io/kusion/pkg/generator"
@@ -249,6 +254,67 @@ func Test_apply(t *testing.T) {
 	})
 }
 
+// syncBuffer is a buffer safe to be read while applying
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// progressRuntime reports the progress while applying, and records the output seen before the apply returns
+type progressRuntime struct {
+	fakerRuntime
+	out           *syncBuffer
+	outWhileApply string
+}
+
+func (p *progressRuntime) Read(ctx context.Context, request *runtime.ReadRequest) *runtime.ReadResponse {
+	return &runtime.ReadResponse{}
+}
+
+func (p *progressRuntime) Apply(ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+	request.Progress("Creating...")
+	// the progress is received after the first one is printed
+	request.Progress("Creation complete after 1s")
+	p.outWhileApply = p.out.String()
+	return &runtime.ApplyResponse{Resource: request.PlanResource}
+}
+
+func Test_applyProgress(t *testing.T) {
+	mockey.PatchConvey("print the progress while applying", t, func() {
+		out := &syncBuffer{}
+		rt := &progressRuntime{out: out}
+		mockey.Mock(runtimeinit.Runtimes).Return(map[models.Type]runtime.Runtime{runtime.Kubernetes: rt}, nil).Build()
+
+		planResources := &models.Spec{Resources: []models.Resource{sa1}}
+		order := &opsmodels.ChangeOrder{
+			StepKeys: []string{sa1.ID},
+			ChangeSteps: map[string]*opsmodels.ChangeStep{
+				sa1.ID: {ID: sa1.ID, Action: opsmodels.Create, From: &sa1},
+			},
+		}
+		changes := opsmodels.NewChanges(project, stack, order)
+		stateStorage := &local.FileSystemState{Path: filepath.Join(t.TempDir(), local.KusionState)}
+
+		err := Apply(NewApplyOptions(), stateStorage, planResources, changes, out)
+		assert.Nil(t, err)
+		assert.Contains(t, rt.outWhileApply, sa1.ID+": Creating...")
+		assert.NotContains(t, rt.outWhileApply, "Apply complete!")
+		assert.Contains(t, out.String(), sa1.ID+": Creation complete after 1s")
+	})
+}
+
 func mockOperationApply(res opsmodels.OpResult) {
 	mockey.Mock((*operation.ApplyOperation).Apply).To(
 		func(o *operation.ApplyOperation, request *operation.ApplyRequest) (*operation.ApplyResponse, status.Status) {
@@ -297,3 +363,82 @@ func mockPromptOutput(res string) {
 		return nil
 	}).Build()
 }
+
+func TestApplyOptions_Validate(t *testing.T) {
+	mockey.PatchConvey("wait timeout", t, func() {
+		mockey.Mock((*previewcmd.Options).Validate).Return(nil).Build()
+
+		o := NewApplyOptions()
+		assert.NoError(t, o.Validate())
+		assert.Equal(t, time.Duration(0), o.readinessTimeout())
+
+		o.Wait = true
+		assert.Error(t, o.Validate())
+
+		o.WaitTimeout = time.Minute
+		assert.NoError(t, o.Validate())
+		assert.Equal(t, time.Minute, o.readinessTimeout())
+	})
+}
+
+func TestApplyOptions_ValidatePlan(t *testing.T) {
+	mockey.PatchConvey("plan conflicts", t, func() {
+		mockey.Mock((*previewcmd.Options).Validate).Return(nil).Build()
+
+		o := NewApplyOptions()
+		o.Plan = "plan.kplan"
+		assert.NoError(t, o.Validate())
+
+		o.Targets = []string{"*"}
+		assert.Error(t, o.Validate())
+	})
+}
+
+func Test_loadPlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.kplan")
+	plan := &opsmodels.Plan{
+		Project:     project.Name,
+		Stack:       "prod",
+		Serial:      1,
+		Spec:        &models.Spec{Resources: []models.Resource{sa1}},
+		ChangeOrder: &opsmodels.ChangeOrder{StepKeys: []string{}, ChangeSteps: map[string]*opsmodels.ChangeStep{}},
+	}
+	assert.NoError(t, plan.Save(path))
+	_, err := loadPlan(path, project, stack)
+	assert.ErrorContains(t, err, "the plan is saved for stack testdata/prod")
+
+	plan.Stack = stack.Name
+	assert.NoError(t, plan.Save(path))
+	loaded, err := loadPlan(path, project, stack)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), loaded.Serial)
+}
+
+func Test_applyPlan(t *testing.T) {
+	stateStorage := &local.FileSystemState{Path: filepath.Join("", local.KusionState)}
+	mockey.PatchConvey("apply with plan", t, func() {
+		var got *operation.ApplyRequest
+		var order *opsmodels.ChangeOrder
+		mockey.Mock((*operation.ApplyOperation).Apply).To(
+			func(o *operation.ApplyOperation, request *operation.ApplyRequest) (*operation.ApplyResponse, status.Status) {
+				got, order = request, o.ChangeOrder
+				close(o.MsgCh)
+				return &operation.ApplyResponse{}, nil
+			}).Build()
+
+		sp := &models.Spec{Resources: []models.Resource{sa1}}
+		changes := opsmodels.NewChanges(project, stack, &opsmodels.ChangeOrder{
+			StepKeys:    []string{sa1.ID},
+			ChangeSteps: map[string]*opsmodels.ChangeStep{sa1.ID: {ID: sa1.ID, Action: opsmodels.Create}},
+		})
+		o := NewApplyOptions()
+		o.plan = &opsmodels.Plan{Serial: 3, Cluster: "default", Targets: []string{sa1.ID}, Spec: sp, ChangeOrder: changes.ChangeOrder}
+
+		err := Apply(o, stateStorage, sp, changes, os.Stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(3), *got.ExpectedSerial)
+		assert.Equal(t, "default", got.Cluster)
+		assert.Equal(t, []string{sa1.ID}, got.Targets)
+		assert.Equal(t, changes.ChangeOrder, order)
+	})
+}
@@ -13,10 +13,15 @@ import (
 	"kusionstack.io/kusion/pkg/cmd/compile"
 	"kusionstack.io/kusion/pkg/cmd/deps"
 	"kusionstack.io/kusion/pkg/cmd/destroy"
+	"kusionstack.io/kusion/pkg/cmd/drift"
 	"kusionstack.io/kusion/pkg/cmd/env"
+	"kusionstack.io/kusion/pkg/cmd/imports"
 	cmdinit "kusionstack.io/kusion/pkg/cmd/init"
 	"kusionstack.io/kusion/pkg/cmd/ls"
+	"kusionstack.io/kusion/pkg/cmd/output"
 	"kusionstack.io/kusion/pkg/cmd/preview"
+	"kusionstack.io/kusion/pkg/cmd/state"
+	"kusionstack.io/kusion/pkg/cmd/unlock"
 	"kusionstack.io/kusion/pkg/cmd/version"
 	"kusionstack.io/kusion/pkg/util/i18n"
 )
@@ -84,6 +89,11 @@ func NewKusionctlCmd(in io.Reader, out, err io.Writer) *cobra.Command {
 				preview.NewCmdPreview(),
 				apply.NewCmdApply(),
 				destroy.NewCmdDestroy(),
+				imports.NewCmdImport(),
+				drift.NewCmdDrift(),
+				output.NewCmdOutput(),
+				unlock.NewCmdForceUnlock(),
+				state.NewCmdState(),
 			},
 		},
 	}
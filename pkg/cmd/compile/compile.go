@@ -17,7 +17,12 @@ func NewCmdCompile() *cobra.Command {
 	
 		The command must be executed in a Stack or specifying a Stack dir with the -w flag. 
 		You can specify a list of arguments to replace the placeholders defined in KCL,
-		and output the compiled results to a file when using --output flag.`)
+		and output the compiled results to a file when using --output flag.
+	
+		The compiled results can also be exported with the --format flag, so that other tools
+		can consume what Kusion would deploy: Kubernetes resources as a multi-document YAML
+		bundle (k8s-yaml) or a Helm chart (helm), and Terraform resources as a Terraform module
+		in HCL json (hcl). Resources of other types are skipped.`)
 
 		compileExample = i18n.T(`
 	
@@ -37,7 +42,16 @@ func NewCmdCompile() *cobra.Command {
 		kusion compile -o output.yaml
 		
 		# Compile without output style and color
-		kusion compile --no-style=true`)
+		kusion compile --no-style=true
+	
+		# Export Kubernetes resources as a multi-document YAML bundle
+		kusion compile --format k8s-yaml -o manifests.yaml
+	
+		# Export Kubernetes resources as a Helm chart in the charts directory
+		kusion compile --format helm -o charts
+	
+		# Export Terraform resources as a Terraform module in the terraform directory
+		kusion compile --format hcl -o terraform`)
 	)
 
 	o := NewCompileOptions()
@@ -58,7 +72,9 @@ func NewCmdCompile() *cobra.Command {
 
 	o.AddCompileFlags(cmd)
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "",
-		i18n.T("Specify the output file"))
+		i18n.T("Specify the output file, or the output directory of the helm and hcl formats"))
+	cmd.Flags().StringVar(&o.Format, "format", SpecFormat,
+		i18n.T("Specify the format of the compiled results: spec, k8s-yaml, helm or hcl"))
 	cmd.Flags().BoolVarP(&o.DisableNone, "disable-none", "n", false,
 		i18n.T("Disable dumping None values"))
 	cmd.Flags().BoolVarP(&o.OverrideAST, "override-AST", "a", false,
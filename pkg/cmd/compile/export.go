@@ -0,0 +1,287 @@
+package compile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+	yamlv2 "gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/engine/runtime/terraform/tfops"
+	"kusionstack.io/kusion/pkg/models"
+	jsonutil "kusionstack.io/kusion/pkg/util/json"
+)
+
+// Formats of the compiled result
+const (
+	// SpecFormat is the Spec of Kusion, which is the default format
+	SpecFormat = "spec"
+
+	// K8sYAMLFormat is a multi-document YAML bundle of Kubernetes resources
+	K8sYAMLFormat = "k8s-yaml"
+
+	// HelmFormat is a Helm chart whose templates are Kubernetes resources
+	HelmFormat = "helm"
+
+	// HCLFormat is a Terraform module of Terraform resources in HCL json
+	HCLFormat = "hcl"
+)
+
+// Formats are all supported formats of the compiled result
+var Formats = []string{SpecFormat, K8sYAMLFormat, HelmFormat, HCLFormat}
+
+const (
+	defaultChartName    = "kusion"
+	defaultChartVersion = "0.1.0"
+	hclModuleFile       = "main.tf.json"
+)
+
+// warningPrinter prints warnings to stderr, so that the compiled result written to stdout is kept clean
+var warningPrinter = pterm.Warning.WithWriter(os.Stderr)
+
+// resourcesOf returns resources of the runtime type in the Spec, and warns about resources of other types which are
+// left out of the compiled result
+func resourcesOf(sp *models.Spec, t models.Type, format string) (models.Resources, error) {
+	var resources models.Resources
+	for _, r := range sp.Resources {
+		if r.Type == t {
+			resources = append(resources, r)
+			continue
+		}
+		warningPrinter.Printfln("resource %s of type %s is skipped in the %s format", r.ID, r.Type, format)
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("no %s resources in the Spec to compile in the %s format", t, format)
+	}
+	return resources, nil
+}
+
+// KubernetesYAML returns Kubernetes resources in the Spec as a multi-document YAML bundle, in the order of resources
+// in the Spec
+func KubernetesYAML(sp *models.Spec) ([]byte, error) {
+	resources, err := resourcesOf(sp, runtime.Kubernetes, K8sYAMLFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, r := range resources {
+		out, err := yamlv2.Marshal(r.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("marshal resource %s failed: %v", r.ID, err)
+		}
+		buf.WriteString("---\n")
+		buf.Write(out)
+	}
+	return buf.Bytes(), nil
+}
+
+// HelmChart returns a Helm chart named by the name, whose templates render Kubernetes resources in the Spec exactly.
+// Each resource is a template file, and Helm orders them by their kinds at installation
+func HelmChart(sp *models.Spec, name string) (*chart.Chart, error) {
+	resources, err := resourcesOf(sp, runtime.Kubernetes, HelmFormat)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = defaultChartName
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:  chart.APIVersionV2,
+			Name:        name,
+			Description: "Kubernetes resources compiled by Kusion",
+			Type:        "application",
+			Version:     defaultChartVersion,
+		},
+		Raw: []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte{}}},
+	}
+	for _, r := range resources {
+		out, err := yamlv2.Marshal(r.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("marshal resource %s failed: %v", r.ID, err)
+		}
+		c.Templates = append(c.Templates, &chart.File{
+			Name: filepath.Join(chartutil.TemplatesDir, templateName(&r)),
+			// Escape delimiters of actions, which are literals in resources instead of templates
+			Data: []byte(strings.ReplaceAll(string(out), "{{", `{{ "{{" }}`)),
+		})
+	}
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// templateName returns the name of the template file of the Kubernetes resource, like "deployment-default-nginx.yaml"
+func templateName(r *models.Resource) string {
+	u := &unstructured.Unstructured{Object: r.Attributes}
+	gvk := u.GroupVersionKind()
+	parts := []string{strings.ToLower(gvk.Kind)}
+	if gvk.Group != "" {
+		parts = append(parts, strings.ReplaceAll(gvk.Group, ".", "-"))
+	}
+	if ns := u.GetNamespace(); ns != "" {
+		parts = append(parts, ns)
+	}
+	parts = append(parts, u.GetName())
+	return strings.Join(parts, "-") + ".yaml"
+}
+
+// WriteHelmChart writes the Helm chart of the Spec into the directory of the chart under dest. Templates of a chart
+// written before are removed, so that resources deleted from the Spec are not left in the chart
+func WriteHelmChart(sp *models.Spec, name, dest string) error {
+	c, err := HelmChart(sp, name)
+	if err != nil {
+		return err
+	}
+	if err = os.RemoveAll(filepath.Join(dest, c.Name(), chartutil.TemplatesDir)); err != nil {
+		return err
+	}
+	return chartutil.SaveDir(c, dest)
+}
+
+// HCLModule returns Terraform resources in the Spec as a Terraform module in HCL json. Blocks of each resource are
+// built the same as what the Terraform runtime writes, and are merged into one module. References to attributes of
+// other Terraform resources, like "$kusion_path.hashicorp:aws:aws_vpc:main.id", are converted to Terraform
+// expressions like "${aws_vpc.main.id}"
+func HCLModule(sp *models.Spec) (map[string]interface{}, error) {
+	resources, err := resourcesOf(sp, runtime.Terraform, HCLFormat)
+	if err != nil {
+		return nil, err
+	}
+	addresses := map[string]string{}
+	for _, r := range resources {
+		address, err := terraformAddress(&r)
+		if err != nil {
+			return nil, err
+		}
+		addresses[r.ID] = address
+	}
+
+	module := map[string]interface{}{}
+	ws := tfops.NewWorkSpace(afero.Afero{Fs: afero.NewMemMapFs()})
+	for _, r := range resources {
+		resource := r.DeepCopy()
+		resource.Attributes = convertRefs(resource.Attributes, addresses).(map[string]interface{})
+		ws.SetResource(resource)
+		blocks, err := ws.HCL()
+		if err != nil {
+			return nil, err
+		}
+		if err = mergeBlocks(module, blocks, ""); err != nil {
+			return nil, fmt.Errorf("merge HCL of resource %s failed: %v", r.ID, err)
+		}
+	}
+	return module, nil
+}
+
+// WriteHCLModule writes the Terraform module of the Spec into the directory
+func WriteHCLModule(sp *models.Spec, dir string) error {
+	module, err := HCLModule(sp)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, hclModuleFile), []byte(jsonutil.Marshal2PrettyString(module)), 0o666)
+}
+
+// terraformAddress returns the address of the Terraform resource in the module, like "aws_vpc.main"
+func terraformAddress(r *models.Resource) (string, error) {
+	resourceType, ok := r.Extensions["resourceType"].(string)
+	if !ok || resourceType == "" {
+		return "", fmt.Errorf("no resourceType in extensions of Terraform resource %s", r.ID)
+	}
+	if _, ok = r.Extensions["provider"].(string); !ok {
+		return "", fmt.Errorf("no provider in extensions of Terraform resource %s", r.ID)
+	}
+	names := strings.Split(r.ID, ":")
+	return resourceType + "." + names[len(names)-1], nil
+}
+
+// convertRefs returns the value with references to Terraform resources in the module converted to Terraform
+// expressions. Other references are kept, which can't be resolved in the module
+func convertRefs(v interface{}, addresses map[string]string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			out[k] = convertRefs(item, addresses)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, item := range value {
+			out[i] = convertRefs(item, addresses)
+		}
+		return out
+	case string:
+		if !strings.HasPrefix(value, graph.ImplicitRefPrefix) {
+			return value
+		}
+		ref := strings.Split(strings.TrimPrefix(value, graph.ImplicitRefPrefix), ".")
+		address, ok := addresses[ref[0]]
+		if !ok {
+			warningPrinter.Printfln("reference %s is not resolvable in the %s format", value, HCLFormat)
+			return value
+		}
+		expr := address
+		for _, attr := range ref[1:] {
+			if _, err := strconv.Atoi(attr); err == nil {
+				expr += "[" + attr + "]"
+			} else {
+				expr += "." + attr
+			}
+		}
+		return "${" + expr + "}"
+	default:
+		return v
+	}
+}
+
+// mergeBlocks merges HCL json blocks of a resource into the module. Requirements and configurations of the same
+// provider are merged only if they are the same, and resources with the same address are not allowed
+func mergeBlocks(dst, src map[string]interface{}, path string) error {
+	for k, v := range src {
+		key := strings.TrimPrefix(path+"."+k, ".")
+		cur, ok := dst[k]
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		switch path {
+		case "", "terraform", "resource":
+			// Blocks of the module, of requirements and of each resource type are merged
+			curMap, curOK := cur.(map[string]interface{})
+			srcMap, srcOK := v.(map[string]interface{})
+			if !curOK || !srcOK {
+				return errors.New("conflicting definitions of " + key)
+			}
+			if err := mergeBlocks(curMap, srcMap, key); err != nil {
+				return err
+			}
+		case "terraform.required_providers", "provider":
+			if !reflect.DeepEqual(cur, v) {
+				return errors.New("conflicting definitions of " + key)
+			}
+		default:
+			return errors.New("duplicate definitions of " + key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package compile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chartutil"
+	helmengine "helm.sh/helm/v3/pkg/engine"
+
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/models"
+)
+
+func newTFResource(resourceType, name string, attributes map[string]interface{}) models.Resource {
+	return models.Resource{
+		ID:         "hashicorp:aws:" + resourceType + ":" + name,
+		Type:       runtime.Terraform,
+		Attributes: attributes,
+		Extensions: map[string]interface{}{
+			"provider":     "registry.terraform.io/hashicorp/aws/5.0.1",
+			"providerMeta": map[string]interface{}{"region": "us-east-1"},
+			"resourceType": resourceType,
+		},
+	}
+}
+
+func newExportSpec() *models.Spec {
+	cm := models.Resource{
+		ID:   "v1:ConfigMap:test-ns:tmpl",
+		Type: runtime.Kubernetes,
+		Attributes: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "tmpl", "namespace": namespace},
+			"data":       map[string]interface{}{"greeting": "hello {{ .name }}"},
+		},
+	}
+	vpc := newTFResource("aws_vpc", "main", map[string]interface{}{"cidr_block": "10.0.0.0/16"})
+	subnet := newTFResource("aws_subnet", "main", map[string]interface{}{
+		"vpc_id":     "$kusion_path.hashicorp:aws:aws_vpc:main.id",
+		"cidr_block": "10.0.1.0/24",
+		"tags":       map[string]interface{}{"sa": "$kusion_path." + sa1.ID + ".metadata.name"},
+	})
+	return &models.Spec{Resources: models.Resources{sa1, cm, vpc, subnet}}
+}
+
+func TestKubernetesYAML(t *testing.T) {
+	out, err := KubernetesYAML(&models.Spec{Resources: models.Resources{sa1, sa2}})
+	require.NoError(t, err)
+	assert.Equal(t, `---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: sa1
+  namespace: test-ns
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: sa2
+  namespace: test-ns
+`, string(out))
+
+	_, err = KubernetesYAML(&models.Spec{})
+	assert.EqualError(t, err, "no Kubernetes resources in the Spec to compile in the k8s-yaml format")
+}
+
+func TestHelmChart(t *testing.T) {
+	c, err := HelmChart(newExportSpec(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "kusion", c.Name())
+
+	// templates are rendered to resources exactly
+	values, err := chartutil.ToRenderValues(c, map[string]interface{}{}, chartutil.ReleaseOptions{Name: "demo"}, nil)
+	require.NoError(t, err)
+	rendered, err := helmengine.Render(c, values)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"kusion/templates/serviceaccount-test-ns-sa1.yaml": `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: sa1
+  namespace: test-ns
+`,
+		"kusion/templates/configmap-test-ns-tmpl.yaml": `apiVersion: v1
+data:
+  greeting: hello {{ .name }}
+kind: ConfigMap
+metadata:
+  name: tmpl
+  namespace: test-ns
+`,
+	}, rendered)
+}
+
+func TestWriteHelmChart(t *testing.T) {
+	dest := t.TempDir()
+	stale := filepath.Join(dest, "demo", "templates", "stale.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(stale), 0o750))
+	require.NoError(t, os.WriteFile(stale, []byte{}, 0o600))
+
+	require.NoError(t, WriteHelmChart(newExportSpec(), "demo", dest))
+	assert.FileExists(t, filepath.Join(dest, "demo", chartutil.ChartfileName))
+	assert.FileExists(t, filepath.Join(dest, "demo", chartutil.ValuesfileName))
+	assert.FileExists(t, filepath.Join(dest, "demo", "templates", "serviceaccount-test-ns-sa1.yaml"))
+	assert.NoFileExists(t, stale)
+}
+
+func TestHCLModule(t *testing.T) {
+	module, err := HCLModule(newExportSpec())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"terraform": map[string]interface{}{
+			"required_providers": map[string]interface{}{
+				"aws": map[string]string{"source": "registry.terraform.io/hashicorp/aws", "version": "5.0.1"},
+			},
+		},
+		"provider": map[string]interface{}{
+			"aws": map[string]interface{}{"region": "us-east-1"},
+		},
+		"resource": map[string]interface{}{
+			"aws_vpc": map[string]interface{}{
+				"main": map[string]interface{}{"cidr_block": "10.0.0.0/16"},
+			},
+			"aws_subnet": map[string]interface{}{
+				"main": map[string]interface{}{
+					"vpc_id":     "${aws_vpc.main.id}",
+					"cidr_block": "10.0.1.0/24",
+					"tags":       map[string]interface{}{"sa": "$kusion_path." + sa1.ID + ".metadata.name"},
+				},
+			},
+		},
+	}, module)
+
+	t.Run("conflicting providers", func(t *testing.T) {
+		sp := newExportSpec()
+		sp.Resources[3].Extensions["providerMeta"] = map[string]interface{}{"region": "us-west-1"}
+		_, err := HCLModule(sp)
+		assert.EqualError(t, err, "merge HCL of resource hashicorp:aws:aws_subnet:main failed: "+
+			"conflicting definitions of provider.aws")
+	})
+
+	t.Run("duplicate resources", func(t *testing.T) {
+		vpc := newTFResource("aws_vpc", "main", nil)
+		vpc.ID = "hashicorp:aws:aws_vpc:other:main"
+		sp := newExportSpec()
+		sp.Resources = append(sp.Resources, vpc)
+		_, err := HCLModule(sp)
+		assert.EqualError(t, err, "merge HCL of resource hashicorp:aws:aws_vpc:other:main failed: "+
+			"duplicate definitions of resource.aws_vpc.main")
+	})
+}
+
+func TestWriteHCLModule(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "terraform")
+	require.NoError(t, WriteHCLModule(newExportSpec(), dir))
+	content, err := os.ReadFile(filepath.Join(dir, "main.tf.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"vpc_id": "${aws_vpc.main.id}"`)
+
+	err = WriteHCLModule(&models.Spec{Resources: models.Resources{sa1}}, dir)
+	assert.EqualError(t, err, "no Terraform resources in the Spec to compile in the hcl format")
+}
+
+func TestConvertRefs(t *testing.T) {
+	addresses := map[string]string{"hashicorp:aws:aws_subnet:main": "aws_subnet.main"}
+	assert.Equal(t, []interface{}{"${aws_subnet.main.tags[0].value}", "plain"},
+		convertRefs([]interface{}{"$kusion_path.hashicorp:aws:aws_subnet:main.tags.0.value", "plain"}, addresses))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pterm/pterm"
 	yamlv2 "gopkg.in/yaml.v2"
@@ -24,6 +25,7 @@ type Options struct {
 
 type Flags struct {
 	Output      string
+	Format      string
 	WorkDir     string
 	Settings    []string
 	Arguments   map[string]string
@@ -61,6 +63,16 @@ func (o *Options) Validate() error {
 	if len(wrongFiles) != 0 {
 		return fmt.Errorf("you can only compile files with suffix .k, these are wrong files: %v", wrongFiles)
 	}
+
+	switch o.Format {
+	case "", SpecFormat, K8sYAMLFormat:
+	case HelmFormat, HCLFormat:
+		if o.Output == Stdout || o.Output == "" {
+			return fmt.Errorf("the %s format must be written into a directory specified by --output", o.Format)
+		}
+	default:
+		return fmt.Errorf("unsupported format %s, supported formats are %s", o.Format, strings.Join(Formats, ", "))
+	}
 	return nil
 }
 
@@ -98,18 +110,24 @@ func (o *Options) Run() error {
 		}
 	}
 
-	yaml, err := yamlv2.Marshal(sp)
+	var yaml []byte
+	switch o.Format {
+	case HelmFormat:
+		return WriteHelmChart(sp, project.Name, o.outputPath())
+	case HCLFormat:
+		return WriteHCLModule(sp, o.outputPath())
+	case K8sYAMLFormat:
+		yaml, err = KubernetesYAML(sp)
+	default:
+		yaml, err = yamlv2.Marshal(sp)
+	}
 	if err != nil {
 		return err
 	}
 	if o.Output == Stdout || o.Output == "" {
 		fmt.Print(string(yaml))
 	} else {
-		if o.WorkDir != "" {
-			o.Output = filepath.Join(o.WorkDir, o.Output)
-		}
-
-		err = os.WriteFile(o.Output, yaml, 0o666)
+		err = os.WriteFile(o.outputPath(), yaml, 0o666)
 		if err != nil {
 			return err
 		}
@@ -117,6 +135,14 @@ func (o *Options) Run() error {
 	return nil
 }
 
+// outputPath returns the path of the output, which is relative to the work directory
+func (o *Options) outputPath() string {
+	if o.WorkDir != "" {
+		return filepath.Join(o.WorkDir, o.Output)
+	}
+	return o.Output
+}
+
 func (o *Options) PreSet(preCheck func(cur string) bool) error {
 	curDir := o.WorkDir
 	if o.WorkDir == "" {
@@ -149,6 +175,10 @@ func (o *Options) PreSet(preCheck func(cur string) bool) error {
 		}
 	}
 
+	// Only the Spec is written into the golden file by default
+	if o.Output == "" && o.Format != "" && o.Format != SpecFormat {
+		o.Output = Stdout
+	}
 	if o.Output == "" {
 		absCiTestDir := filepath.Join(curDir, projectstack.CiTestDir)
 		_, err := os.Stat(absCiTestDir)
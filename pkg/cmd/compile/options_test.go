@@ -4,6 +4,8 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bytedance/mockey"
@@ -98,6 +100,55 @@ func TestCompileOptions_preSet(t *testing.T) {
 	}
 }
 
+func TestCompileOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		output  string
+		wantErr string
+	}{
+		{name: "default format", format: SpecFormat, output: Stdout},
+		{name: "k8s-yaml to stdout", format: K8sYAMLFormat, output: Stdout},
+		{name: "helm into a directory", format: HelmFormat, output: "charts"},
+		{
+			name:    "hcl to stdout",
+			format:  HCLFormat,
+			output:  Stdout,
+			wantErr: "the hcl format must be written into a directory specified by --output",
+		},
+		{
+			name:    "unsupported format",
+			format:  "json",
+			output:  Stdout,
+			wantErr: "unsupported format json, supported formats are spec, k8s-yaml, helm, hcl",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewCompileOptions()
+			o.Format = tt.format
+			o.Output = tt.output
+			err := o.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileOptions_preSetFormat(t *testing.T) {
+	o := NewCompileOptions()
+	o.WorkDir = t.TempDir()
+	o.Format = K8sYAMLFormat
+	o.Settings = []string{projectstack.KclFile}
+	assert.NoError(t, o.PreSet(func(cur string) bool {
+		return true
+	}))
+	assert.Equal(t, Stdout, o.Output)
+}
+
 func TestCompileOptions_Run(t *testing.T) {
 	defer func() {
 		os.Remove("kusion_state.json")
@@ -117,6 +168,22 @@ func TestCompileOptions_Run(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	mockey.PatchConvey("export Kubernetes resources", t, func() {
+		m1 := mockDetectProjectAndStack()
+		m2 := mockGenerateSpec()
+		defer m1.UnPatch()
+		defer m2.UnPatch()
+
+		o := NewCompileOptions()
+		o.NoStyle = true
+		o.Format = K8sYAMLFormat
+		o.Output = filepath.Join(t.TempDir(), "manifests.yaml")
+		assert.Nil(t, o.Run())
+		out, err := os.ReadFile(o.Output)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, strings.Count(string(out), "kind: ServiceAccount"))
+	})
+
 	mockey.PatchConvey("detect project and spec failed", t, func() {
 		m1 := mockDetectProjectAndStackFail()
 		defer m1.UnPatch()
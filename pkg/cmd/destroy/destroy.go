@@ -6,6 +6,7 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	"kusionstack.io/kusion/pkg/cmd/util"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
 )
 
 func NewCmdDestroy() *cobra.Command {
@@ -24,7 +25,10 @@ func NewCmdDestroy() *cobra.Command {
 
 		destroyExample = i18n.T(`
 		# Delete the configuration of current stack
-		kusion destroy`)
+		kusion destroy
+
+		# Delete only the matched resources and resources depending on them
+		kusion destroy --target "v1:Service:default:*"`)
 	)
 
 	o := NewDestroyOptions()
@@ -49,6 +53,12 @@ func NewCmdDestroy() *cobra.Command {
 		i18n.T("Automatically approve and perform the update after previewing it"))
 	cmd.Flags().BoolVarP(&o.Detail, "detail", "d", false,
 		i18n.T("Automatically show plan details after previewing it"))
+	cmd.Flags().StringSliceVarP(&o.Targets, "target", "", nil,
+		i18n.T("Only destroy resources matching these resource IDs or glob patterns, together with their dependents"))
+	cmd.Flags().IntVarP(&o.Parallelism, "parallelism", "", 0,
+		i18n.T("Limit the number of resources operated concurrently, 0 means no limit"))
+	cmd.Flags().StringVarP(&o.FailurePolicy, "failure-policy", "", string(opsmodels.ContinueOnFailure),
+		i18n.T("Specify the policy when a resource fails, supported policies: continue, fail-fast"))
 	o.AddBackendFlags(cmd)
 
 	return cmd
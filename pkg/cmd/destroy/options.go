@@ -1,6 +1,8 @@
 package destroy
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -24,9 +26,12 @@ import (
 
 type Options struct {
 	compilecmd.Options
-	Operator string
-	Yes      bool
-	Detail   bool
+	Operator      string
+	Yes           bool
+	Detail        bool
+	Targets       []string
+	Parallelism   int
+	FailurePolicy string
 	backend.BackendOps
 }
 
@@ -41,12 +46,16 @@ func (o *Options) Complete(args []string) {
 }
 
 func (o *Options) Validate() error {
-	return o.Options.Validate()
+	if err := o.Options.Validate(); err != nil {
+		return err
+	}
+	if o.Parallelism < 0 {
+		return errors.New("parallelism must not be negative")
+	}
+	return opsmodels.ValidateFailurePolicy(o.FailurePolicy)
 }
 
 func (o *Options) Run() error {
-	// listen for interrupts or the SIGTERM signal
-	signals.HandleInterrupt()
 	// Parse project and stack of work directory
 	project, stack, err := projectstack.DetectProjectAndStack(o.Options.WorkDir)
 	if err != nil {
@@ -136,6 +145,8 @@ func (o *Options) preview(
 			Stack:         stack,
 			StateStorage:  stateStorage,
 			ChangeOrder:   &opsmodels.ChangeOrder{StepKeys: []string{}, ChangeSteps: map[string]*opsmodels.ChangeStep{}},
+			Parallelism:   o.Parallelism,
+			FailurePolicy: opsmodels.FailurePolicy(o.FailurePolicy),
 		},
 	}
 
@@ -148,6 +159,7 @@ func (o *Options) preview(
 			Operator: o.Operator,
 			Stack:    stack,
 			Spec:     planResources,
+			Targets:  o.Targets,
 		},
 	})
 	if status.IsErr(s) {
@@ -158,11 +170,18 @@ func (o *Options) preview(
 }
 
 func (o *Options) destroy(planResources *models.Spec, changes *opsmodels.Changes, stateStorage states.StateStorage) error {
+	// Cancel the destroy operation on interrupts, and resources deleted are still removed from the State
+	ctx, stop := signals.NotifyContext(context.Background())
+	defer stop()
+
 	do := &operation.DestroyOperation{
 		Operation: opsmodels.Operation{
-			Stack:        changes.Stack(),
-			StateStorage: stateStorage,
-			MsgCh:        make(chan opsmodels.Message),
+			Stack:         changes.Stack(),
+			StateStorage:  stateStorage,
+			MsgCh:         make(chan opsmodels.Message),
+			Parallelism:   o.Parallelism,
+			FailurePolicy: opsmodels.FailurePolicy(o.FailurePolicy),
+			Ctx:           ctx,
 		},
 	}
 
@@ -199,13 +218,13 @@ func (o *Options) destroy(planResources *models.Spec, changes *opsmodels.Changes
 					var title string
 					if changeStep.Action == opsmodels.UnChanged {
 						title = fmt.Sprintf("%s %s, %s",
-							changeStep.Action.String(),
+							changeStep.ActionString(),
 							pterm.Bold.Sprint(changeStep.ID),
 							strings.ToLower(string(opsmodels.Skip)),
 						)
 					} else {
 						title = fmt.Sprintf("%s %s %s",
-							changeStep.Action.String(),
+							changeStep.ActionString(),
 							pterm.Bold.Sprint(changeStep.ID),
 							strings.ToLower(string(msg.OpResult)),
 						)
@@ -213,14 +232,23 @@ func (o *Options) destroy(planResources *models.Spec, changes *opsmodels.Changes
 					pterm.Success.Println(title)
 					progressbar.UpdateTitle(title)
 					progressbar.Increment()
-					deleted++
+					if msg.OpResult == opsmodels.Success {
+						deleted++
+					}
 				case opsmodels.Failed:
 					title := fmt.Sprintf("%s %s %s",
-						changeStep.Action.String(),
+						changeStep.ActionString(),
 						pterm.Bold.Sprint(changeStep.ID),
 						strings.ToLower(string(msg.OpResult)),
 					)
 					pterm.Error.Printf("%s\n", title)
+				case opsmodels.Retry:
+					title := fmt.Sprintf("%s %s failed with a transient error, %v",
+						changeStep.Action.Ing(),
+						pterm.Bold.Sprint(changeStep.ID),
+						msg.OpErr,
+					)
+					pterm.Warning.Println(title)
 				default:
 					title := fmt.Sprintf("%s %s %s",
 						changeStep.Action.Ing(),
@@ -240,6 +268,7 @@ func (o *Options) destroy(planResources *models.Spec, changes *opsmodels.Changes
 			Operator: o.Operator,
 			Stack:    changes.Stack(),
 			Spec:     planResources,
+			Targets:  o.Targets,
 		},
 	})
 	if status.IsErr(st) {
@@ -0,0 +1,62 @@
+package drift
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+func NewCmdDrift() *cobra.Command {
+	var (
+		driftShort = i18n.T(`Detect drift between the state and the actual infrastructure`)
+
+		driftLong = i18n.T(`
+		Detect drift of all resources in the current stack.
+
+		Every resource recorded in the latest state is compared with the live resource read from its runtime,
+		and fields added, removed or changed outside Kusion are reported as a table or JSON.
+		Fields maintained by the Kubernetes API server, such as status and metadata.resourceVersion, are ignored.
+
+		The command exits with a non-zero code if any drift is detected, so it can be used in scheduled jobs.`)
+
+		driftExample = i18n.T(`
+		# Detect drift of the current stack
+		kusion drift
+
+		# Detect drift with json format result
+		kusion drift -o json
+
+		# Detect drift and ignore differences of target fields
+		kusion drift --ignore-fields="metadata.annotations"`)
+	)
+
+	o := NewDriftOptions()
+	cmd := &cobra.Command{
+		Use:     "drift",
+		Short:   driftShort,
+		Long:    templates.LongDesc(driftLong),
+		Example: templates.Examples(driftExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.WorkDir, "workdir", "w", "",
+		i18n.T("Specify the work directory"))
+	cmd.Flags().StringVarP(&o.Cluster, "cluster", "", "",
+		i18n.T("Specify the cluster of the state"))
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "",
+		i18n.T("Specify the output format"))
+	cmd.Flags().StringSliceVarP(&o.IgnoreFields, "ignore-fields", "", nil,
+		i18n.T("Ignore differences of target fields"))
+	cmd.Flags().BoolVarP(&o.NoStyle, "no-style", "", false,
+		i18n.T("no-style sets to RawOutput mode and disables all of styling"))
+	o.AddBackendFlags(cmd)
+
+	return cmd
+}
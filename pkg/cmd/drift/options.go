@@ -0,0 +1,158 @@
+package drift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pterm/pterm"
+
+	"kusionstack.io/kusion/pkg/engine/backend"
+	_ "kusionstack.io/kusion/pkg/engine/backend/init"
+	"kusionstack.io/kusion/pkg/engine/operation"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+	jsonutil "kusionstack.io/kusion/pkg/util/json"
+	"kusionstack.io/kusion/pkg/util/pretty"
+)
+
+const jsonOutput = "json"
+
+// Options defines flags for the `drift` command
+type Options struct {
+	WorkDir      string
+	Cluster      string
+	Output       string
+	IgnoreFields []string
+	NoStyle      bool
+	backend.BackendOps
+}
+
+func NewDriftOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) Validate() error {
+	if o.Output != "" && o.Output != jsonOutput {
+		return errors.New("invalid output type, supported types: json")
+	}
+	return nil
+}
+
+func (o *Options) Run() error {
+	// Set no style
+	if o.NoStyle || o.Output == jsonOutput {
+		pterm.DisableStyling()
+		pterm.DisableColor()
+	}
+
+	// Parse project and stack of work directory
+	project, stack, err := projectstack.DetectProjectAndStack(o.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	// Get state storage from backend config to manage state
+	stateStorage, err := backend.BackendFromConfig(project.Backend, o.BackendOps, o.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	dro := &operation.DriftOperation{
+		Operation: opsmodels.Operation{
+			Stack:        stack,
+			StateStorage: stateStorage,
+			IgnoreFields: o.IgnoreFields,
+		},
+	}
+	rsp, st := dro.Drift(&operation.DriftRequest{
+		Request: opsmodels.Request{
+			Tenant:  project.Tenant,
+			Project: project,
+			Stack:   stack,
+			Cluster: o.Cluster,
+		},
+	})
+	if status.IsErr(st) {
+		return fmt.Errorf("detect drift failed, status:\n%v", st)
+	}
+
+	if o.Output == jsonOutput {
+		output, err := json.MarshalIndent(rsp, "", "  ")
+		if err != nil {
+			return fmt.Errorf("json marshal drift report failed as %w", err)
+		}
+		fmt.Println(string(output))
+	} else {
+		summary(stack, rsp)
+	}
+
+	if drifted := rsp.Drifted(); len(drifted) != 0 {
+		return fmt.Errorf("drift detected in %d of %d resources", len(drifted), len(rsp.Resources))
+	}
+	return nil
+}
+
+// summary prints the drift of each resource as tables
+func summary(stack *projectstack.Stack, rsp *operation.DriftResponse) {
+	if len(rsp.Resources) == 0 {
+		fmt.Println(pretty.GreenBold("No managed resource found in this stack."))
+		return
+	}
+
+	tableData := pterm.TableData{{fmt.Sprintf("Stack: %s", stack.Name), "ID", "Drift"}}
+	for i, d := range rsp.Resources {
+		itemPrefix := " * ├─"
+		if i == len(rsp.Resources)-1 {
+			itemPrefix = " * └─"
+		}
+		tableData = append(tableData, []string{itemPrefix, d.ID, driftString(d)})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().
+		WithHeaderStyle(&pterm.ThemeDefault.TableHeaderStyle).
+		WithLeftAlignment(true).
+		WithSeparator("  ").
+		WithData(tableData).
+		WithWriter(os.Stdout).
+		Render()
+	pterm.Println()
+
+	for _, d := range rsp.Drifted() {
+		if d.Deleted {
+			continue
+		}
+		pterm.DefaultSection.WithWriter(os.Stdout).Println(d.ID)
+		detailData := pterm.TableData{{"Path", "Change", "State", "Live"}}
+		for _, fd := range d.Added {
+			detailData = append(detailData, []string{fd.Path, "added", "", valueString(fd.To)})
+		}
+		for _, fd := range d.Removed {
+			detailData = append(detailData, []string{fd.Path, "removed", valueString(fd.From), ""})
+		}
+		for _, fd := range d.Changed {
+			detailData = append(detailData, []string{fd.Path, "changed", valueString(fd.From), valueString(fd.To)})
+		}
+		_ = pterm.DefaultTable.WithHasHeader().WithData(detailData).WithWriter(os.Stdout).Render()
+		pterm.Println()
+	}
+}
+
+func driftString(d *operation.ResourceDrift) string {
+	switch {
+	case d.Deleted:
+		return pretty.RedBold("deleted")
+	case d.Drifted():
+		return pretty.YellowBold("%d added, %d removed, %d changed", len(d.Added), len(d.Removed), len(d.Changed))
+	default:
+		return pretty.GreenBold("no drift")
+	}
+}
+
+func valueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return jsonutil.Marshal2String(v)
+}
@@ -0,0 +1,71 @@
+package drift
+
+import (
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/engine/operation"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+func mockDetectProjectAndStack() {
+	mockey.Mock(projectstack.DetectProjectAndStack).To(func(stackDir string) (*projectstack.Project, *projectstack.Stack, error) {
+		project := &projectstack.Project{ProjectConfiguration: projectstack.ProjectConfiguration{Name: "testdata"}}
+		stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "dev"}}
+		return project, stack, nil
+	}).Build()
+}
+
+func TestOptions_Validate(t *testing.T) {
+	o := NewDriftOptions()
+	assert.NoError(t, o.Validate())
+
+	o.Output = "yaml"
+	assert.Error(t, o.Validate())
+}
+
+func TestOptions_Run(t *testing.T) {
+	noDrift := &operation.ResourceDrift{ID: "v1:Namespace:default", Type: "Kubernetes"}
+	changed := &operation.ResourceDrift{
+		ID:   "apps/v1:Deployment:default:nginx",
+		Type: "Kubernetes",
+		Changed: []*operation.FieldDrift{
+			{Path: "/spec/replicas", From: 1, To: 3},
+		},
+	}
+	deleted := &operation.ResourceDrift{ID: "v1:Service:default:nginx", Type: "Kubernetes", Deleted: true}
+
+	mockey.PatchConvey("no drift", t, func() {
+		mockDetectProjectAndStack()
+		mockey.Mock((*operation.DriftOperation).Drift).To(func(
+			o *operation.DriftOperation,
+			request *operation.DriftRequest,
+		) (*operation.DriftResponse, status.Status) {
+			return &operation.DriftResponse{Resources: []*operation.ResourceDrift{noDrift}}, nil
+		}).Build()
+
+		o := NewDriftOptions()
+		o.NoStyle = true
+		assert.NoError(t, o.Run())
+	})
+
+	mockey.PatchConvey("drift detected", t, func() {
+		mockDetectProjectAndStack()
+		mockey.Mock((*operation.DriftOperation).Drift).To(func(
+			o *operation.DriftOperation,
+			request *operation.DriftRequest,
+		) (*operation.DriftResponse, status.Status) {
+			return &operation.DriftResponse{Resources: []*operation.ResourceDrift{noDrift, changed, deleted}}, nil
+		}).Build()
+
+		o := NewDriftOptions()
+		o.NoStyle = true
+		assert.EqualError(t, o.Run(), "drift detected in 2 of 3 resources")
+
+		o.Output = jsonOutput
+		assert.Error(t, o.Run())
+	})
+}
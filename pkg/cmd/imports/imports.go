@@ -0,0 +1,61 @@
+package imports
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+func NewCmdImport() *cobra.Command {
+	var (
+		importShort = i18n.T(`Import existing resources into the state`)
+
+		importLong = i18n.T(`
+		Import resources that already exist in the actual infrastructure into the state of the current stack.
+
+		The resource must be declared in the KCL files of the stack with the RESOURCE_ID, and CLOUD_ID is the
+		identifier of the resource in the actual infrastructure, such as the ID of a cloud resource.
+		If no argument is given, all Kubernetes resources declared in the stack but not managed in the state
+		are matched against live objects in the cluster by their IDs, and the existing ones are imported after approval.
+		Imported resources will NOT be modified, and they will be managed by Kusion after importing.`)
+
+		importExample = i18n.T(`
+		# Import an existing RDS instance into the state of current stack
+		kusion import aliyun:alicloud:alicloud_db_instance:my-rds rm-2ze0a1b2c3d4e5f6
+
+		# Import all Kubernetes resources of current stack which already exist in the cluster
+		kusion import
+
+		# Import with specifying work directory and arguments
+		kusion import aliyun:alicloud:alicloud_vpc:my-vpc vpc-2zeabcdefg -w /path/to/workdir -D region=cn-beijing`)
+	)
+
+	o := NewImportOptions()
+	cmd := &cobra.Command{
+		Use:     "import [RESOURCE_ID CLOUD_ID]",
+		Short:   importShort,
+		Long:    templates.LongDesc(importLong),
+		Example: templates.Examples(importExample),
+		Args:    cobra.MaximumNArgs(2),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			o.Complete(args)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	o.AddCompileFlags(cmd)
+	cmd.Flags().StringVarP(&o.Operator, "operator", "", "",
+		i18n.T("Specify the operator"))
+	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", false,
+		i18n.T("Automatically approve and import all existing Kubernetes resources"))
+	cmd.Flags().BoolVarP(&o.NoStyle, "no-style", "", false,
+		i18n.T("no-style sets to RawOutput mode and disables all of styling"))
+	o.AddBackendFlags(cmd)
+
+	return cmd
+}
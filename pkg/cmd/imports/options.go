@@ -0,0 +1,189 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/pterm/pterm"
+
+	compilecmd "kusionstack.io/kusion/pkg/cmd/compile"
+	"kusionstack.io/kusion/pkg/cmd/spec"
+	"kusionstack.io/kusion/pkg/engine/backend"
+	_ "kusionstack.io/kusion/pkg/engine/backend/init"
+	"kusionstack.io/kusion/pkg/engine/operation"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/generator"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+	"kusionstack.io/kusion/pkg/util/pretty"
+)
+
+// Options defines flags for the `import` command
+type Options struct {
+	compilecmd.Options
+	Operator   string
+	Yes        bool
+	ResourceID string
+	ImportID   string
+	backend.BackendOps
+}
+
+func NewImportOptions() *Options {
+	return &Options{
+		Options: *compilecmd.NewCompileOptions(),
+	}
+}
+
+func (o *Options) Complete(args []string) {
+	if len(args) > 0 {
+		o.ResourceID = args[0]
+	}
+	if len(args) > 1 {
+		o.ImportID = args[1]
+	}
+	o.Options.Complete([]string{})
+}
+
+func (o *Options) Validate() error {
+	if (o.ResourceID == "") != (o.ImportID == "") {
+		return errors.New("RESOURCE_ID and CLOUD_ID must be specified together")
+	}
+	return o.Options.Validate()
+}
+
+func (o *Options) Run() error {
+	// Set no style
+	if o.NoStyle {
+		pterm.DisableStyling()
+		pterm.DisableColor()
+	}
+
+	// Parse project and stack of work directory
+	project, stack, err := projectstack.DetectProjectAndStack(o.Options.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	// Generate Spec to find the declaration of resources to import
+	sp, err := spec.GenerateSpecWithSpinner(&generator.Options{
+		IsKclPkg:    o.IsKclPkg,
+		WorkDir:     o.WorkDir,
+		Filenames:   o.Filenames,
+		Settings:    o.Settings,
+		Arguments:   o.Arguments,
+		Overrides:   o.Overrides,
+		DisableNone: o.DisableNone,
+		OverrideAST: o.OverrideAST,
+		NoStyle:     o.NoStyle,
+	}, project, stack)
+	if err != nil {
+		return err
+	}
+
+	// Get state storage from backend config to manage state
+	stateStorage, err := backend.BackendFromConfig(project.Backend, o.BackendOps, o.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	if !project.SecretStores.IsValid() {
+		return fmt.Errorf("no secret store is provided")
+	}
+	imp := &operation.ImportOperation{
+		Operation: opsmodels.Operation{
+			Stack:        stack,
+			StateStorage: stateStorage,
+			SecretStores: project.SecretStores,
+		},
+	}
+	request := opsmodels.Request{
+		Tenant:   project.Tenant,
+		Project:  project,
+		Stack:    stack,
+		Cluster:  o.Arguments["cluster"],
+		Operator: o.Operator,
+		Spec:     sp,
+	}
+
+	importIDs := map[string]string{o.ResourceID: o.ImportID}
+	if o.ResourceID == "" {
+		// adopt all Kubernetes resources in the Spec which already exist in the cluster
+		importIDs, err = o.importableResources(imp, &request)
+		if err != nil || len(importIDs) == 0 {
+			return err
+		}
+	}
+
+	fmt.Println("Start importing resources ...")
+	rsp, st := imp.Import(&operation.ImportRequest{Request: request, ImportIDs: importIDs})
+	if status.IsErr(st) {
+		return fmt.Errorf("import failed, status:\n%v", st)
+	}
+
+	for _, res := range rsp.Resources {
+		pterm.Success.Printf("Import %s success\n", pterm.Bold.Sprint(res.ResourceKey()))
+	}
+	pterm.Printf("Import complete! Resources: %d imported.\n", len(rsp.Resources))
+	return nil
+}
+
+// importableResources shows all Kubernetes resources that can be adopted and asks for approval,
+// and returns nothing if there is no resource to import or the import is canceled
+func (o *Options) importableResources(imp *operation.ImportOperation, request *opsmodels.Request) (map[string]string, error) {
+	resources, st := imp.ImportableResources(request)
+	if status.IsErr(st) {
+		return nil, fmt.Errorf("detect importable resources failed, status:\n%v", st)
+	}
+	if len(resources) == 0 {
+		fmt.Println(pretty.GreenBold("No existing Kubernetes resource to import in this stack."))
+		return nil, nil
+	}
+
+	tableData := pterm.TableData{{fmt.Sprintf("Stack: %s", request.Stack.Name), "ID", "Action"}}
+	importIDs := make(map[string]string, len(resources))
+	for i, res := range resources {
+		itemPrefix := " * ├─"
+		if i == len(resources)-1 {
+			itemPrefix = " * └─"
+		}
+		tableData = append(tableData, []string{itemPrefix, res.ResourceKey(), "Import"})
+		// Kubernetes resources are located by their IDs in the Spec
+		importIDs[res.ResourceKey()] = ""
+	}
+	_ = pterm.DefaultTable.WithHasHeader().
+		WithHeaderStyle(&pterm.ThemeDefault.TableHeaderStyle).
+		WithLeftAlignment(true).
+		WithSeparator("  ").
+		WithData(tableData).
+		Render()
+	pterm.Println()
+
+	if !o.Yes {
+		input, err := prompt()
+		if err != nil {
+			return nil, err
+		}
+		if input != "yes" {
+			fmt.Println("Operation import canceled")
+			return nil, nil
+		}
+	}
+	return importIDs, nil
+}
+
+func prompt() (string, error) {
+	prompt := &survey.Select{
+		Message: `Do you want to import these resources?`,
+		Options: []string{"yes", "no"},
+		Default: "no",
+	}
+
+	var input string
+	err := survey.AskOne(prompt, &input)
+	if err != nil {
+		fmt.Printf("Prompt failed %v\n", err)
+		return "", err
+	}
+	return input, nil
+}
@@ -0,0 +1,140 @@
+package imports
+
+import (
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/cmd/spec"
+	"kusionstack.io/kusion/pkg/engine/operation"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/generator"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+var sa = models.Resource{
+	ID:   "aliyun:alicloud:alicloud_vpc:my-vpc",
+	Type: "Terraform",
+	Attributes: map[string]interface{}{
+		"cidr_block": "10.0.0.0/8",
+	},
+	Extensions: map[string]interface{}{
+		"provider":     "registry.terraform.io/aliyun/alicloud/1.153.0",
+		"resourceType": "alicloud_vpc",
+	},
+}
+
+var ns = models.Resource{
+	ID:   "v1:Namespace:default",
+	Type: "Kubernetes",
+	Attributes: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name": "default",
+		},
+	},
+}
+
+func TestOptions_Validate(t *testing.T) {
+	o := NewImportOptions()
+	o.Complete([]string{})
+	assert.NoError(t, o.Validate())
+
+	o.Complete([]string{sa.ID})
+	assert.Error(t, o.Validate())
+
+	o.Complete([]string{sa.ID, "vpc-123"})
+	assert.NoError(t, o.Validate())
+	assert.Equal(t, sa.ID, o.ResourceID)
+	assert.Equal(t, "vpc-123", o.ImportID)
+}
+
+func mockGenerateSpec() {
+	mockey.Mock(projectstack.DetectProjectAndStack).To(func(stackDir string) (*projectstack.Project, *projectstack.Stack, error) {
+		project := &projectstack.Project{ProjectConfiguration: projectstack.ProjectConfiguration{Name: "testdata"}}
+		stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "dev"}}
+		return project, stack, nil
+	}).Build()
+	mockey.Mock(spec.GenerateSpecWithSpinner).To(func(
+		o *generator.Options,
+		project *projectstack.Project,
+		stack *projectstack.Stack,
+	) (*models.Spec, error) {
+		return &models.Spec{Resources: []models.Resource{sa, ns}}, nil
+	}).Build()
+}
+
+func TestOptions_Run(t *testing.T) {
+	mockey.PatchConvey("import resource", t, func() {
+		mockGenerateSpec()
+		var importIDs map[string]string
+		mockey.Mock((*operation.ImportOperation).Import).To(func(
+			o *operation.ImportOperation,
+			request *operation.ImportRequest,
+		) (*operation.ImportResponse, status.Status) {
+			importIDs = request.ImportIDs
+			return &operation.ImportResponse{Resources: []*models.Resource{&sa}}, nil
+		}).Build()
+
+		o := NewImportOptions()
+		o.Complete([]string{sa.ID, "vpc-123"})
+		o.NoStyle = true
+		assert.NoError(t, o.Run())
+		assert.Equal(t, map[string]string{sa.ID: "vpc-123"}, importIDs)
+	})
+}
+
+func TestOptions_RunImportable(t *testing.T) {
+	mockey.PatchConvey("no importable resource", t, func() {
+		mockGenerateSpec()
+		mockey.Mock((*operation.ImportOperation).ImportableResources).To(func(
+			o *operation.ImportOperation,
+			request *opsmodels.Request,
+		) ([]*models.Resource, status.Status) {
+			return nil, nil
+		}).Build()
+		imported := false
+		mockey.Mock((*operation.ImportOperation).Import).To(func(
+			o *operation.ImportOperation,
+			request *operation.ImportRequest,
+		) (*operation.ImportResponse, status.Status) {
+			imported = true
+			return &operation.ImportResponse{}, nil
+		}).Build()
+
+		o := NewImportOptions()
+		o.Complete([]string{})
+		o.NoStyle = true
+		assert.NoError(t, o.Run())
+		assert.False(t, imported)
+	})
+
+	mockey.PatchConvey("import existing kubernetes resources", t, func() {
+		mockGenerateSpec()
+		mockey.Mock((*operation.ImportOperation).ImportableResources).To(func(
+			o *operation.ImportOperation,
+			request *opsmodels.Request,
+		) ([]*models.Resource, status.Status) {
+			return []*models.Resource{&ns}, nil
+		}).Build()
+		var importIDs map[string]string
+		mockey.Mock((*operation.ImportOperation).Import).To(func(
+			o *operation.ImportOperation,
+			request *operation.ImportRequest,
+		) (*operation.ImportResponse, status.Status) {
+			importIDs = request.ImportIDs
+			return &operation.ImportResponse{Resources: []*models.Resource{&ns}}, nil
+		}).Build()
+
+		o := NewImportOptions()
+		o.Complete([]string{})
+		o.NoStyle = true
+		o.Yes = true
+		assert.NoError(t, o.Run())
+		assert.Equal(t, map[string]string{ns.ID: ""}, importIDs)
+	})
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/pterm/pterm"
+
+	"kusionstack.io/kusion/pkg/engine/backend"
+	_ "kusionstack.io/kusion/pkg/engine/backend/init"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/projectstack"
+	jsonutil "kusionstack.io/kusion/pkg/util/json"
+)
+
+const jsonOutput = "json"
+
+// Options defines flags for the `output` command
+type Options struct {
+	Name    string
+	WorkDir string
+	Cluster string
+	Output  string
+	backend.BackendOps
+
+	out io.Writer
+}
+
+func NewOutputOptions() *Options {
+	return &Options{out: os.Stdout}
+}
+
+func (o *Options) Complete(args []string) {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+}
+
+func (o *Options) Validate() error {
+	if o.Output != "" && o.Output != jsonOutput {
+		return errors.New("invalid output type, supported types: json")
+	}
+	return nil
+}
+
+func (o *Options) Run() error {
+	// Parse project and stack of work directory
+	project, stack, err := projectstack.DetectProjectAndStack(o.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	// Get state storage from backend config to read outputs
+	stateStorage, err := backend.BackendFromConfig(project.Backend, o.BackendOps, o.WorkDir)
+	if err != nil {
+		return err
+	}
+	state, err := stateStorage.GetLatestState(&states.StateQuery{
+		Tenant:  project.Tenant,
+		Project: project.Name,
+		Stack:   stack.Name,
+		Cluster: o.Cluster,
+	})
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("can not find State of stack %s/%s, please apply it first", project.Name, stack.Name)
+	}
+
+	// Show the value of one output
+	if o.Name != "" {
+		value, ok := state.Outputs[o.Name]
+		if !ok {
+			return fmt.Errorf("can not find output %s in the State of stack %s/%s", o.Name, project.Name, stack.Name)
+		}
+		if o.Output == jsonOutput {
+			return o.printJSON(value)
+		}
+		fmt.Fprintln(o.out, valueString(value))
+		return nil
+	}
+
+	// Show all outputs
+	outputs := state.Outputs
+	if outputs == nil {
+		outputs = map[string]interface{}{}
+	}
+	if o.Output == jsonOutput {
+		return o.printJSON(outputs)
+	}
+	if len(outputs) == 0 {
+		fmt.Fprintf(o.out, "No output found in stack %s\n", stack.Name)
+		return nil
+	}
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tableData := pterm.TableData{{"Name", "Value"}}
+	for _, name := range names {
+		tableData = append(tableData, []string{name, valueString(outputs[name])})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(tableData).WithWriter(o.out).Render()
+}
+
+func (o *Options) printJSON(v interface{}) error {
+	output, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json marshal outputs failed as %w", err)
+	}
+	fmt.Fprintln(o.out, string(output))
+	return nil
+}
+
+func valueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return jsonutil.Marshal2String(v)
+}
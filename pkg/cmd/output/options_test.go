@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/engine/backend"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+func mockDetectProjectAndStack() {
+	mockey.Mock(projectstack.DetectProjectAndStack).To(func(stackDir string) (*projectstack.Project, *projectstack.Stack, error) {
+		project := &projectstack.Project{ProjectConfiguration: projectstack.ProjectConfiguration{Name: "testdata"}}
+		stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "dev"}}
+		return project, stack, nil
+	}).Build()
+}
+
+// localOptions returns Options using a local backend in a temp dir with a State of the given outputs
+func localOptions(t *testing.T, outputs map[string]interface{}) (*Options, *bytes.Buffer) {
+	path := filepath.Join(t.TempDir(), local.KusionState)
+	if outputs != nil {
+		storage := &local.FileSystemState{Path: path}
+		state := &states.State{Project: "testdata", Stack: "dev", Serial: 1, Outputs: outputs}
+		assert.NoError(t, storage.Apply(state))
+	}
+
+	out := &bytes.Buffer{}
+	o := NewOutputOptions()
+	o.BackendOps = backend.BackendOps{Type: "local", Config: []string{"path=" + path}}
+	o.out = out
+	return o, out
+}
+
+func TestOptions_Validate(t *testing.T) {
+	o := NewOutputOptions()
+	assert.NoError(t, o.Validate())
+
+	o.Output = "yaml"
+	assert.Error(t, o.Validate())
+}
+
+func TestOptions_Run(t *testing.T) {
+	outputs := map[string]interface{}{
+		"endpoint": "10.0.0.1",
+		"ports":    []interface{}{float64(80), float64(443)},
+	}
+
+	mockey.PatchConvey("no state", t, func() {
+		mockDetectProjectAndStack()
+
+		o, _ := localOptions(t, nil)
+		assert.ErrorContains(t, o.Run(), "can not find State of stack testdata/dev")
+	})
+
+	mockey.PatchConvey("show all outputs", t, func() {
+		mockDetectProjectAndStack()
+
+		o, out := localOptions(t, outputs)
+		assert.NoError(t, o.Run())
+		assert.Contains(t, out.String(), "endpoint")
+		assert.Contains(t, out.String(), "[80,443]")
+
+		out.Reset()
+		o.Output = jsonOutput
+		assert.NoError(t, o.Run())
+		assert.JSONEq(t, `{"endpoint": "10.0.0.1", "ports": [80, 443]}`, out.String())
+	})
+
+	mockey.PatchConvey("show an output", t, func() {
+		mockDetectProjectAndStack()
+
+		o, out := localOptions(t, outputs)
+		o.Complete([]string{"endpoint"})
+		assert.NoError(t, o.Run())
+		assert.Equal(t, "10.0.0.1\n", out.String())
+
+		out.Reset()
+		o.Output = jsonOutput
+		assert.NoError(t, o.Run())
+		assert.Equal(t, "\"10.0.0.1\"\n", out.String())
+
+		o.Complete([]string{"not-exist"})
+		assert.ErrorContains(t, o.Run(), "can not find output not-exist")
+	})
+}
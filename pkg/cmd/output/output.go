@@ -0,0 +1,59 @@
+package output
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+func NewCmdOutput() *cobra.Command {
+	var (
+		outputShort = i18n.T(`Show outputs of the stack`)
+
+		outputLong = i18n.T(`
+		Show outputs recorded in the latest state of the current stack.
+
+		Outputs are declared in stack.yaml as literals or references to resource attributes, such as
+		"$kusion_path.v1:Service:default:nginx.spec.clusterIP", and they are resolved after each apply.
+		All outputs are shown as a table if no name is given. Resources of other stacks can reference an output
+		with "$kusion_stack.project/stack.outputs.name".`)
+
+		outputExample = i18n.T(`
+		# Show all outputs of the current stack
+		kusion output
+
+		# Show the value of an output
+		kusion output endpoint
+
+		# Show all outputs with json format result
+		kusion output -o json`)
+	)
+
+	o := NewOutputOptions()
+	cmd := &cobra.Command{
+		Use:     "output [NAME]",
+		Short:   outputShort,
+		Long:    templates.LongDesc(outputLong),
+		Example: templates.Examples(outputExample),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			o.Complete(args)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.WorkDir, "workdir", "w", "",
+		i18n.T("Specify the work directory"))
+	cmd.Flags().StringVarP(&o.Cluster, "cluster", "", "",
+		i18n.T("Specify the cluster of the state"))
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "",
+		i18n.T("Specify the output format"))
+	o.AddBackendFlags(cmd)
+
+	return cmd
+}
@@ -15,6 +15,7 @@ import (
 	"kusionstack.io/kusion/pkg/engine/backend"
 	"kusionstack.io/kusion/pkg/engine/operation"
 	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/policy"
 	"kusionstack.io/kusion/pkg/engine/states"
 	"kusionstack.io/kusion/pkg/generator"
 	"kusionstack.io/kusion/pkg/log"
@@ -33,13 +34,17 @@ type Options struct {
 }
 
 type Flags struct {
-	Operator     string
-	Detail       bool
-	All          bool
-	NoStyle      bool
-	Output       string
-	SpecFile     string
-	IgnoreFields []string
+	Operator      string
+	Detail        bool
+	All           bool
+	NoStyle       bool
+	Output        string
+	SpecFile      string
+	IgnoreFields  []string
+	Targets       []string
+	Parallelism   int
+	FailurePolicy string
+	Out           string
 }
 
 func NewPreviewOptions() *Options {
@@ -62,6 +67,12 @@ func (o *Options) Validate() error {
 	if err := o.ValidateSpecFile(); err != nil {
 		return err
 	}
+	if o.Parallelism < 0 {
+		return errors.New("parallelism must not be negative")
+	}
+	if err := opsmodels.ValidateFailurePolicy(o.FailurePolicy); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -161,12 +172,32 @@ func (o *Options) Run() error {
 		return err
 	}
 
+	// Read the State serial before computing changes, so the saved plan will be refused if the State is modified
+	// during the preview
+	var serial uint64
+	if o.Out != "" {
+		serial, err = latestSerial(stateStorage, project, stack, o.Arguments["cluster"])
+		if err != nil {
+			return err
+		}
+	}
+
 	// Compute changes for preview
 	changes, err := Preview(o, stateStorage, sp, project, stack)
 	if err != nil {
 		return err
 	}
 
+	// Save the plan to be applied later
+	if o.Out != "" {
+		if err = SavePlan(o, sp, changes, serial); err != nil {
+			return err
+		}
+		if o.Output != jsonOutput {
+			fmt.Printf("Plan saved to %s. Apply it with: kusion apply --plan %s\n", o.Out, o.Out)
+		}
+	}
+
 	if o.Output == jsonOutput {
 		var previewChanges []byte
 		previewChanges, err = json.Marshal(changes)
@@ -202,6 +233,42 @@ func (o *Options) Run() error {
 	return nil
 }
 
+// latestSerial returns the serial of the latest State of the stack, and 0 if there is no State
+func latestSerial(storage states.StateStorage, project *projectstack.Project, stack *projectstack.Stack, cluster string) (uint64, error) {
+	state, err := storage.GetLatestState(&states.StateQuery{
+		Tenant:  project.Tenant,
+		Project: project.Name,
+		Stack:   stack.Name,
+		Cluster: cluster,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get the latest State failed: %w", err)
+	}
+	if state == nil {
+		return 0, nil
+	}
+	return state.Serial, nil
+}
+
+// SavePlan saves the Spec and the computed changes into the file specified by the flag `--out`, together with the
+// serial of the State the changes are computed from
+func SavePlan(o *Options, sp *models.Spec, changes *opsmodels.Changes, serial uint64) error {
+	plan := &opsmodels.Plan{
+		Tenant:      changes.Project().Tenant,
+		Project:     changes.Project().Name,
+		Stack:       changes.Stack().Name,
+		Cluster:     o.Arguments["cluster"],
+		Serial:      serial,
+		Targets:     o.Targets,
+		Spec:        sp,
+		ChangeOrder: changes.ChangeOrder,
+	}
+	if err := plan.Save(o.Out); err != nil {
+		return fmt.Errorf("save plan to %s failed: %w", o.Out, err)
+	}
+	return nil
+}
+
 // The Preview function calculates the upcoming actions of each resource
 // through the execution Kusion Engine, and you can customize the
 // runtime of engine and the state storage through `runtime` and
@@ -246,6 +313,8 @@ func Preview(
 			IgnoreFields:  o.IgnoreFields,
 			ChangeOrder:   &opsmodels.ChangeOrder{StepKeys: []string{}, ChangeSteps: map[string]*opsmodels.ChangeStep{}},
 			SecretStores:  project.SecretStores,
+			Parallelism:   o.Parallelism,
+			FailurePolicy: opsmodels.FailurePolicy(o.FailurePolicy),
 		},
 	}
 
@@ -261,11 +330,31 @@ func Preview(
 			Operator: o.Operator,
 			Spec:     planResources,
 			Cluster:  cluster,
+			Targets:  o.Targets,
 		},
 	})
 	if status.IsErr(s) {
 		return nil, fmt.Errorf("preview failed.\n%s", s.String())
 	}
 
-	return opsmodels.NewChanges(project, stack, rsp.Order), nil
+	changes := opsmodels.NewChanges(project, stack, rsp.Order)
+	if err := CheckPolicies(o, planResources, changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// CheckPolicies evaluates policies of the project against the Spec and changes. Warnings are displayed, and an
+// error is returned if any policy denies the changes
+func CheckPolicies(o *Options, sp *models.Spec, changes *opsmodels.Changes) error {
+	results, err := policy.Evaluate(changes.Project().GetPath(), policy.NewInput(sp, changes))
+	if err != nil {
+		return err
+	}
+	if o.Output != jsonOutput {
+		for _, r := range results.Warnings() {
+			pterm.Warning.Printfln("policy %s", r)
+		}
+	}
+	return results.Err()
 }
@@ -15,6 +15,7 @@ import (
 	"kusionstack.io/kusion/pkg/engine"
 	"kusionstack.io/kusion/pkg/engine/operation"
 	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/policy"
 	"kusionstack.io/kusion/pkg/engine/runtime"
 	"kusionstack.io/kusion/pkg/engine/runtime/kubernetes"
 	"kusionstack.io/kusion/pkg/engine/states/local"
@@ -358,9 +359,11 @@ func TestPreviewOptions_Validate(t *testing.T) {
 	m := mockey.Mock((*compilecmd.Options).Validate).Return(nil).Build()
 	defer m.UnPatch()
 	tests := []struct {
-		name    string
-		output  string
-		wantErr bool
+		name          string
+		output        string
+		parallelism   int
+		failurePolicy string
+		wantErr       bool
 	}{
 		{
 			name:    "test1",
@@ -382,11 +385,28 @@ func TestPreviewOptions_Validate(t *testing.T) {
 			output:  "",
 			wantErr: false,
 		},
+		{
+			name:          "fail-fast policy",
+			failurePolicy: "fail-fast",
+			wantErr:       false,
+		},
+		{
+			name:          "invalid policy",
+			failurePolicy: "abort",
+			wantErr:       true,
+		},
+		{
+			name:        "negative parallelism",
+			parallelism: -1,
+			wantErr:     true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			o := &Options{}
 			o.Output = tt.output
+			o.Parallelism = tt.parallelism
+			o.FailurePolicy = tt.failurePolicy
 			err := o.Validate()
 			if tt.wantErr {
 				require.Error(t, err)
@@ -396,3 +416,51 @@ func TestPreviewOptions_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestSavePlan(t *testing.T) {
+	o := NewPreviewOptions()
+	o.Out = filepath.Join(t.TempDir(), "plan.kplan")
+	o.Arguments = map[string]string{"cluster": "default"}
+	sp := &models.Spec{Resources: []models.Resource{sa1}}
+	order := &opsmodels.ChangeOrder{
+		StepKeys:    []string{sa1.ID},
+		ChangeSteps: map[string]*opsmodels.ChangeStep{sa1.ID: opsmodels.NewChangeStep(sa1.ID, opsmodels.Create, &sa1, nil)},
+	}
+	err := SavePlan(o, sp, opsmodels.NewChanges(project, stack, order), 5)
+	assert.Nil(t, err)
+
+	plan, err := opsmodels.LoadPlan(o.Out)
+	assert.Nil(t, err)
+	assert.Equal(t, "testdata", plan.Project)
+	assert.Equal(t, "dev", plan.Stack)
+	assert.Equal(t, "default", plan.Cluster)
+	assert.Equal(t, uint64(5), plan.Serial)
+	assert.Equal(t, opsmodels.Create, plan.ChangeOrder.Get(sa1.ID).Action)
+}
+
+func TestCheckPolicies(t *testing.T) {
+	sp := &models.Spec{Resources: []models.Resource{sa1}}
+	changes := opsmodels.NewChanges(project, stack, &opsmodels.ChangeOrder{
+		StepKeys:    []string{sa1.ID},
+		ChangeSteps: map[string]*opsmodels.ChangeStep{sa1.ID: opsmodels.NewChangeStep(sa1.ID, opsmodels.Create, &sa1, nil)},
+	})
+
+	mockey.PatchConvey("denied", t, func() {
+		mockey.Mock(policy.Evaluate).Return(policy.Results{
+			{Policy: "sa", Level: policy.Warn, Message: "ServiceAccount is created"},
+			{Policy: "sa", Level: policy.Deny, Message: "ServiceAccount is not allowed"},
+		}, nil).Build()
+
+		err := CheckPolicies(NewPreviewOptions(), sp, changes)
+		assert.EqualError(t, err, "denied by policies:\n[sa] ServiceAccount is not allowed")
+	})
+
+	mockey.PatchConvey("warned", t, func() {
+		mockey.Mock(policy.Evaluate).Return(policy.Results{
+			{Policy: "sa", Level: policy.Warn, Message: "ServiceAccount is created"},
+		}, nil).Build()
+
+		err := CheckPolicies(NewPreviewOptions(), sp, changes)
+		assert.Nil(t, err)
+	})
+}
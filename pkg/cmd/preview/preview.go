@@ -5,6 +5,7 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	"kusionstack.io/kusion/pkg/cmd/util"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
 	"kusionstack.io/kusion/pkg/util/i18n"
 )
 
@@ -37,6 +38,9 @@ func NewCmdPreview() *cobra.Command {
 		# Preview with json format result
 		kusion preview -o json
 
+		# Preview and save the plan, which can be applied later by "kusion apply --plan plan.kplan"
+		kusion preview --out plan.kplan
+
 		# Preview without output style and color
 		kusion preview --no-style=true`)
 	)
@@ -60,6 +64,10 @@ func NewCmdPreview() *cobra.Command {
 	o.AddPreviewFlags(cmd)
 	o.AddBackendFlags(cmd)
 
+	cmd.Flags().StringVarP(&o.Out, "out", "", "",
+		i18n.T("Save the spec and the previewed changes to the file, which can be applied exactly by `kusion apply --plan`. "+
+			"The file may contain resolved secrets in plain text, so keep it private and don't commit it"))
+
 	return cmd
 }
 
@@ -78,4 +86,10 @@ func (o *Options) AddPreviewFlags(cmd *cobra.Command) {
 		i18n.T("Specify the output format"))
 	cmd.Flags().StringVarP(&o.SpecFile, "spec-file", "", "",
 		i18n.T("Specify the spec file path as input, and the spec file must be located in the working directory or its subdirectories"))
+	cmd.Flags().StringSliceVarP(&o.Targets, "target", "", nil,
+		i18n.T("Only operate resources matching these resource IDs or glob patterns, together with their dependencies"))
+	cmd.Flags().IntVarP(&o.Parallelism, "parallelism", "", 0,
+		i18n.T("Limit the number of resources operated concurrently, 0 means no limit"))
+	cmd.Flags().StringVarP(&o.FailurePolicy, "failure-policy", "", string(opsmodels.ContinueOnFailure),
+		i18n.T("Specify the policy when a resource fails, supported policies: continue, fail-fast"))
 }
@@ -15,6 +15,7 @@ import (
 	"kusionstack.io/kusion/pkg/generator"
 	appgenerator "kusionstack.io/kusion/pkg/generator/appconfiguration/generator"
 	"kusionstack.io/kusion/pkg/generator/kcl"
+	"kusionstack.io/kusion/pkg/generator/manifest"
 	"kusionstack.io/kusion/pkg/log"
 	"kusionstack.io/kusion/pkg/models"
 	appmodel "kusionstack.io/kusion/pkg/models/appconfiguration"
@@ -75,6 +76,8 @@ func GenerateSpec(o *generator.Options, project *projectstack.Project, stack *pr
 				return nil, err
 			}
 			g = &appgenerator.AppsGenerator{Apps: appConfigs}
+		case projectstack.ManifestGenerator:
+			g = &manifest.Generator{}
 		default:
 			return nil, fmt.Errorf("unknow generator type:%s", gt)
 		}
@@ -0,0 +1,121 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+const jsonOutput = "json"
+
+type HistoryOptions struct {
+	Options
+	Output string
+}
+
+func NewHistoryOptions() *HistoryOptions {
+	return &HistoryOptions{}
+}
+
+func NewCmdHistory() *cobra.Command {
+	var (
+		historyShort = i18n.T(`List all versioned snapshots of the state`)
+
+		historyLong = i18n.T(`
+		List all versioned snapshots of the state in the current stack.
+
+		A snapshot is kept every time the state is modified, and the serial of the snapshot can be used
+		to roll back resources by the command 'kusion state rollback'.`)
+
+		historyExample = i18n.T(`
+		# List the state history of the current stack
+		kusion state history
+
+		# List the state history with json format result
+		kusion state history -o json`)
+	)
+
+	o := NewHistoryOptions()
+	cmd := &cobra.Command{
+		Use:     "history",
+		Short:   historyShort,
+		Long:    templates.LongDesc(historyLong),
+		Example: templates.Examples(historyExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	o.AddStateFlags(cmd)
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "",
+		i18n.T("Specify the output format"))
+
+	return cmd
+}
+
+func (o *HistoryOptions) Validate() error {
+	if o.Output != "" && o.Output != jsonOutput {
+		return errors.New("invalid output type, supported types: json")
+	}
+	return nil
+}
+
+func (o *HistoryOptions) Run() error {
+	_, _, storage, query, err := o.stateStorage()
+	if err != nil {
+		return err
+	}
+
+	history, err := storage.GetStateHistory(query)
+	if err != nil {
+		return err
+	}
+
+	if o.Output == jsonOutput {
+		output, err := json.MarshalIndent(history, "", "  ")
+		if err != nil {
+			return fmt.Errorf("json marshal state history failed as %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if len(history) == 0 {
+		fmt.Println("No state history found in this stack")
+		return nil
+	}
+	return renderHistory(history)
+}
+
+func renderHistory(history []*states.State) error {
+	tableHeader := []string{"Serial", "Operator", "Resources", "Kusion Version", "Modified Time"}
+	tableData := pterm.TableData{tableHeader}
+	for _, state := range history {
+		modifiedTime := state.ModifiedTime
+		if modifiedTime.IsZero() {
+			modifiedTime = state.CreateTime
+		}
+		tableData = append(tableData, []string{
+			strconv.FormatUint(state.Serial, 10),
+			state.Operator,
+			strconv.Itoa(len(state.Resources)),
+			state.KusionVersion,
+			modifiedTime.Format(time.RFC3339),
+		})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithData(tableData).WithWriter(os.Stdout).Render()
+}
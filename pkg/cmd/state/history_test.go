@@ -0,0 +1,46 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+func mockDetectProjectAndStack() {
+	mockey.Mock(projectstack.DetectProjectAndStack).To(func(stackDir string) (*projectstack.Project, *projectstack.Stack, error) {
+		project := &projectstack.Project{ProjectConfiguration: projectstack.ProjectConfiguration{Name: "testdata"}}
+		stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "dev"}}
+		return project, stack, nil
+	}).Build()
+}
+
+func TestHistoryOptions_Validate(t *testing.T) {
+	o := NewHistoryOptions()
+	assert.NoError(t, o.Validate())
+
+	o.Output = "yaml"
+	assert.Error(t, o.Validate())
+
+	o.Output = jsonOutput
+	assert.NoError(t, o.Validate())
+}
+
+func TestHistoryOptions_Run(t *testing.T) {
+	mockey.PatchConvey("list state history", t, func() {
+		mockDetectProjectAndStack()
+		mockey.Mock((*local.FileSystemState).GetStateHistory).To(func(f *local.FileSystemState, query *states.StateQuery) ([]*states.State, error) {
+			return []*states.State{{Project: query.Project, Stack: query.Stack, Serial: 2}, {Project: query.Project, Stack: query.Stack, Serial: 1}}, nil
+		}).Build()
+
+		o := NewHistoryOptions()
+		assert.NoError(t, o.Run())
+
+		o.Output = jsonOutput
+		assert.NoError(t, o.Run())
+	})
+}
@@ -0,0 +1,60 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+func NewCmdList() *cobra.Command {
+	var (
+		listShort = i18n.T(`List all resources in the state`)
+
+		listLong = i18n.T(`
+		List IDs of all resources recorded in the latest state of the current stack.`)
+
+		listExample = i18n.T(`
+		# List all resources in the state
+		kusion state list
+
+		# List all resources in the state of a specified stack
+		kusion state list -w ./dev`)
+	)
+
+	o := &Options{}
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   listShort,
+		Long:    templates.LongDesc(listLong),
+		Example: templates.Examples(listExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			util.CheckErr(o.RunList())
+			return
+		},
+	}
+
+	o.AddStateFlags(cmd)
+
+	return cmd
+}
+
+func (o *Options) RunList() error {
+	_, _, storage, query, err := o.stateStorage()
+	if err != nil {
+		return err
+	}
+
+	state, err := latestState(storage, query)
+	if err != nil {
+		return err
+	}
+	for _, res := range state.Resources {
+		fmt.Println(res.ResourceKey())
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+type MvOptions struct {
+	Options
+	Source      string
+	Destination string
+}
+
+func NewMvOptions() *MvOptions {
+	return &MvOptions{}
+}
+
+func NewCmdMv() *cobra.Command {
+	var (
+		mvShort = i18n.T(`Rename a resource in the state`)
+
+		mvLong = i18n.T(`
+		Rename a resource in the latest state of the current stack.
+
+		The ID of the resource is changed from SOURCE to DESTINATION, and all dependencies on SOURCE
+		are changed to DESTINATION as well. The actual resource is NOT modified.`)
+
+		mvExample = i18n.T(`
+		# Rename a resource in the state
+		kusion state mv aliyun:alicloud:alicloud_vpc:old aliyun:alicloud:alicloud_vpc:new`)
+	)
+
+	o := NewMvOptions()
+	cmd := &cobra.Command{
+		Use:     "mv SOURCE DESTINATION",
+		Short:   mvShort,
+		Long:    templates.LongDesc(mvLong),
+		Example: templates.Examples(mvExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			o.Complete(args)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	o.AddStateFlags(cmd)
+
+	return cmd
+}
+
+func (o *MvOptions) Complete(args []string) {
+	if len(args) == 2 {
+		o.Source = args[0]
+		o.Destination = args[1]
+	}
+}
+
+func (o *MvOptions) Validate() error {
+	if o.Source == "" || o.Destination == "" {
+		return errors.New("source and destination resource IDs are required")
+	}
+	if o.Source == o.Destination {
+		return errors.New("source and destination resource IDs are the same")
+	}
+	return nil
+}
+
+func (o *MvOptions) Run() error {
+	_, _, storage, query, err := o.stateStorage()
+	if err != nil {
+		return err
+	}
+
+	err = updateState(storage, query, "StateMv", func(state *states.State) error {
+		index := state.Resources.Index()
+		if _, ok := index[o.Destination]; ok {
+			return fmt.Errorf("resource %s already exists in the State", o.Destination)
+		}
+		res, ok := index[o.Source]
+		if !ok {
+			return fmt.Errorf("can not find resource %s in the State", o.Source)
+		}
+		res.ID = o.Destination
+
+		for i := range state.Resources {
+			dependsOn := state.Resources[i].DependsOn
+			for j := range dependsOn {
+				if dependsOn[j] == o.Source {
+					dependsOn[j] = o.Destination
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Moved %s to %s\n", o.Source, o.Destination)
+	return nil
+}
@@ -0,0 +1,84 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"kusionstack.io/kusion/pkg/engine/backend"
+	_ "kusionstack.io/kusion/pkg/engine/backend/init"
+	"kusionstack.io/kusion/pkg/engine/operation"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+// Options are the common options of all state subcommands
+type Options struct {
+	WorkDir string
+	Cluster string
+	backend.BackendOps
+}
+
+func (o *Options) AddStateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.WorkDir, "workdir", "w", "",
+		i18n.T("Specify the work directory"))
+	cmd.Flags().StringVarP(&o.Cluster, "cluster", "", "",
+		i18n.T("Specify the cluster of the state"))
+	o.AddBackendFlags(cmd)
+}
+
+// stateStorage detects the project and stack of the work directory, and returns the
+// state storage configured in the project with the query to locate the state of this stack
+func (o *Options) stateStorage() (*projectstack.Project, *projectstack.Stack, states.StateStorage, *states.StateQuery, error) {
+	project, stack, err := projectstack.DetectProjectAndStack(o.WorkDir)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	storage, err := backend.BackendFromConfig(project.Backend, o.BackendOps, o.WorkDir)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	query := &states.StateQuery{
+		Tenant:  project.Tenant,
+		Project: project.Name,
+		Stack:   stack.Name,
+		Cluster: o.Cluster,
+	}
+	return project, stack, storage, query, nil
+}
+
+// updateState holds the lock of the State while modifying the latest State by the function update,
+// and saves the modified State with a new serial
+func updateState(storage states.StateStorage, query *states.StateQuery, operationName string, update func(state *states.State) error) error {
+	unlock, info, err := operation.LockState(storage, query, operationName, "")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := latestState(storage, query)
+	if err != nil {
+		return err
+	}
+	if err = update(state); err != nil {
+		return err
+	}
+	state.Serial += 1
+	state.Operator = info.Holder
+	return storage.Apply(state)
+}
+
+// latestState returns the latest state of this stack, and returns an error if there is no state
+func latestState(storage states.StateStorage, query *states.StateQuery) (*states.State, error) {
+	state, err := storage.GetLatestState(query)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("can not find State of stack %s/%s", query.Project, query.Stack)
+	}
+	return state, nil
+}
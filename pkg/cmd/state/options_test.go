@@ -0,0 +1,137 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/engine/backend"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/models"
+)
+
+// localOptions returns Options using a local backend in a temp dir with a State of the given resources
+func localOptions(t *testing.T, resources ...models.Resource) (Options, states.StateStorage) {
+	path := filepath.Join(t.TempDir(), local.KusionState)
+	storage := &local.FileSystemState{Path: path}
+	if len(resources) > 0 {
+		state := &states.State{Project: "testdata", Stack: "dev", Serial: 1, Resources: resources}
+		assert.NoError(t, storage.Apply(state))
+	}
+
+	return Options{BackendOps: backend.BackendOps{Type: "local", Config: []string{"path=" + path}}}, storage
+}
+
+func TestOptions_RunList(t *testing.T) {
+	mockey.PatchConvey("no state", t, func() {
+		mockDetectProjectAndStack()
+
+		o, _ := localOptions(t)
+		assert.Error(t, o.RunList())
+		assert.Error(t, o.RunPull())
+	})
+
+	mockey.PatchConvey("list and pull state", t, func() {
+		mockDetectProjectAndStack()
+
+		o, _ := localOptions(t, sa)
+		assert.NoError(t, o.RunList())
+		assert.NoError(t, o.RunPull())
+	})
+}
+
+func TestShowOptions_Run(t *testing.T) {
+	mockey.PatchConvey("show resource", t, func() {
+		mockDetectProjectAndStack()
+
+		o := NewShowOptions()
+		o.Options, _ = localOptions(t, sa)
+		o.Complete([]string{})
+		assert.Error(t, o.Validate())
+
+		o.Complete([]string{"not-exist"})
+		assert.Error(t, o.Run())
+
+		o.Complete([]string{sa.ID})
+		assert.NoError(t, o.Run())
+	})
+}
+
+func TestRmOptions_Run(t *testing.T) {
+	mockey.PatchConvey("remove resources", t, func() {
+		mockDetectProjectAndStack()
+
+		sb := models.Resource{ID: "fake-id-2", Type: "Kubernetes", DependsOn: []string{sa.ID}}
+		o := NewRmOptions()
+		var storage states.StateStorage
+		o.Options, storage = localOptions(t, sa, sb)
+
+		o.Complete([]string{sa.ID, "not-exist"})
+		assert.Error(t, o.Run())
+
+		o.Complete([]string{sa.ID})
+		assert.NoError(t, o.Run())
+
+		state, err := storage.GetLatestState(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), state.Serial)
+		assert.Len(t, state.Resources, 1)
+		assert.Equal(t, sb.ID, state.Resources[0].ID)
+	})
+}
+
+func TestMvOptions_Run(t *testing.T) {
+	mockey.PatchConvey("rename resource", t, func() {
+		mockDetectProjectAndStack()
+
+		sb := models.Resource{ID: "fake-id-2", Type: "Kubernetes", DependsOn: []string{sa.ID}}
+		o := NewMvOptions()
+		var storage states.StateStorage
+		o.Options, storage = localOptions(t, sa, sb)
+
+		o.Complete([]string{sa.ID, sa.ID})
+		assert.Error(t, o.Validate())
+
+		o.Complete([]string{sa.ID, sb.ID})
+		assert.Error(t, o.Run())
+
+		o.Complete([]string{sa.ID, "fake-id-3"})
+		assert.NoError(t, o.Validate())
+		assert.NoError(t, o.Run())
+
+		state, err := storage.GetLatestState(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), state.Serial)
+		index := state.Resources.Index()
+		assert.Contains(t, index, "fake-id-3")
+		assert.NotContains(t, index, sa.ID)
+		assert.Equal(t, []string{"fake-id-3"}, index[sb.ID].DependsOn)
+	})
+}
+
+func TestPushOptions_Run(t *testing.T) {
+	mockey.PatchConvey("push state", t, func() {
+		mockDetectProjectAndStack()
+
+		// prepare a state file with serial 1 to push
+		_, source := localOptions(t, sa)
+		o := NewPushOptions()
+		var storage states.StateStorage
+		o.Options, storage = localOptions(t, sa)
+		o.File = source.(*local.FileSystemState).Path
+
+		// serial is not greater than the latest state
+		assert.Error(t, o.Run())
+
+		o.Force = true
+		assert.NoError(t, o.Run())
+
+		state, err := storage.GetLatestState(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), state.Serial)
+		assert.Equal(t, "testdata", state.Project)
+	})
+}
@@ -0,0 +1,65 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+func NewCmdPull() *cobra.Command {
+	var (
+		pullShort = i18n.T(`Pull the state from the backend`)
+
+		pullLong = i18n.T(`
+		Pull the latest state of the current stack from the backend and print it to stdout.
+
+		Together with 'kusion state push', it can be used to move the state between backends.`)
+
+		pullExample = i18n.T(`
+		# Pull the state into a local file
+		kusion state pull > state.json
+
+		# Pull the state from a specified backend
+		kusion state pull --backend-type oss --backend-config bucket=kusion > state.json`)
+	)
+
+	o := &Options{}
+	cmd := &cobra.Command{
+		Use:     "pull",
+		Short:   pullShort,
+		Long:    templates.LongDesc(pullLong),
+		Example: templates.Examples(pullExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			util.CheckErr(o.RunPull())
+			return
+		},
+	}
+
+	o.AddStateFlags(cmd)
+
+	return cmd
+}
+
+func (o *Options) RunPull() error {
+	_, _, storage, query, err := o.stateStorage()
+	if err != nil {
+		return err
+	}
+
+	state, err := latestState(storage, query)
+	if err != nil {
+		return err
+	}
+	output, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json marshal State failed as %w", err)
+	}
+	fmt.Println(string(output))
+	return nil
+}
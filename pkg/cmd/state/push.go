@@ -0,0 +1,128 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/engine/operation"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+type PushOptions struct {
+	Options
+	File  string
+	Force bool
+}
+
+func NewPushOptions() *PushOptions {
+	return &PushOptions{}
+}
+
+func NewCmdPush() *cobra.Command {
+	var (
+		pushShort = i18n.T(`Push a local state file to the backend`)
+
+		pushLong = i18n.T(`
+		Push a local state file to the backend as the latest state of the current stack.
+
+		The serial of the pushed state must be greater than the serial of the latest state in the backend,
+		otherwise the push is rejected unless --force is specified.
+		Together with 'kusion state pull', it can be used to move the state between backends.`)
+
+		pushExample = i18n.T(`
+		# Push a local state file to the backend
+		kusion state push state.json
+
+		# Move the state from the local backend to an oss backend
+		kusion state pull --backend-type local > state.json
+		kusion state push state.json --backend-type oss --backend-config bucket=kusion`)
+	)
+
+	o := NewPushOptions()
+	cmd := &cobra.Command{
+		Use:     "push FILE",
+		Short:   pushShort,
+		Long:    templates.LongDesc(pushLong),
+		Example: templates.Examples(pushExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			o.Complete(args)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	o.AddStateFlags(cmd)
+	cmd.Flags().BoolVarP(&o.Force, "force", "", false,
+		i18n.T("Push the state even if its serial is not greater than the latest state"))
+
+	return cmd
+}
+
+func (o *PushOptions) Complete(args []string) {
+	if len(args) > 0 {
+		o.File = args[0]
+	}
+}
+
+func (o *PushOptions) Validate() error {
+	if o.File == "" {
+		return errors.New("state file is required")
+	}
+	return nil
+}
+
+func (o *PushOptions) Run() error {
+	data, err := os.ReadFile(o.File)
+	if err != nil {
+		return err
+	}
+	state := &states.State{}
+	// JSON is a subset of YAML, and yaml.Unmarshal keeps the right number type of attributes
+	if err = yaml.Unmarshal(data, state); err != nil {
+		return fmt.Errorf("unmarshal state file %s failed as %w", o.File, err)
+	}
+
+	_, _, storage, query, err := o.stateStorage()
+	if err != nil {
+		return err
+	}
+
+	unlock, info, err := operation.LockState(storage, query, "StatePush", "")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	latest, err := storage.GetLatestState(query)
+	if err != nil {
+		return err
+	}
+	if latest != nil && state.Serial <= latest.Serial {
+		if !o.Force {
+			return fmt.Errorf("serial %d of the pushed state is not greater than serial %d of the latest state, "+
+				"use --force to overwrite it", state.Serial, latest.Serial)
+		}
+		state.Serial = latest.Serial + 1
+	}
+
+	state.Tenant = query.Tenant
+	state.Project = query.Project
+	state.Stack = query.Stack
+	state.Cluster = query.Cluster
+	state.Operator = info.Holder
+	if err = storage.Apply(state); err != nil {
+		return err
+	}
+
+	fmt.Printf("Pushed state of serial %d to stack %s/%s\n", state.Serial, query.Project, query.Stack)
+	return nil
+}
@@ -0,0 +1,107 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+type RmOptions struct {
+	Options
+	IDs []string
+}
+
+func NewRmOptions() *RmOptions {
+	return &RmOptions{}
+}
+
+func NewCmdRm() *cobra.Command {
+	var (
+		rmShort = i18n.T(`Remove resources from the state`)
+
+		rmLong = i18n.T(`
+		Remove resources from the latest state of the current stack.
+
+		Removed resources are no longer managed by Kusion, but the actual resources are NOT destroyed.
+		It is usually used when a resource has been deleted out-of-band or should be managed by others.`)
+
+		rmExample = i18n.T(`
+		# Remove a Kubernetes resource from the state
+		kusion state rm v1:Namespace:default
+
+		# Remove multiple resources from the state
+		kusion state rm v1:Namespace:default apps/v1:Deployment:default:nginx`)
+	)
+
+	o := NewRmOptions()
+	cmd := &cobra.Command{
+		Use:     "rm RESOURCE_ID...",
+		Short:   rmShort,
+		Long:    templates.LongDesc(rmLong),
+		Example: templates.Examples(rmExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			o.Complete(args)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	o.AddStateFlags(cmd)
+
+	return cmd
+}
+
+func (o *RmOptions) Complete(args []string) {
+	o.IDs = args
+}
+
+func (o *RmOptions) Validate() error {
+	if len(o.IDs) == 0 {
+		return errors.New("at least one resource ID is required")
+	}
+	return nil
+}
+
+func (o *RmOptions) Run() error {
+	_, _, storage, query, err := o.stateStorage()
+	if err != nil {
+		return err
+	}
+
+	err = updateState(storage, query, "StateRm", func(state *states.State) error {
+		index := state.Resources.Index()
+		removed := make(map[string]bool, len(o.IDs))
+		for _, id := range o.IDs {
+			if _, ok := index[id]; !ok {
+				return fmt.Errorf("can not find resource %s in the State", id)
+			}
+			removed[id] = true
+		}
+
+		resources := make(models.Resources, 0, len(state.Resources))
+		for _, res := range state.Resources {
+			if !removed[res.ResourceKey()] {
+				resources = append(resources, res)
+			}
+		}
+		state.Resources = resources
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range o.IDs {
+		fmt.Printf("Removed %s\n", id)
+	}
+	return nil
+}
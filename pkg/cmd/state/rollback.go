@@ -0,0 +1,179 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	applycmd "kusionstack.io/kusion/pkg/cmd/apply"
+	previewcmd "kusionstack.io/kusion/pkg/cmd/preview"
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+type RollbackOptions struct {
+	Options
+	Serial   uint64
+	Yes      bool
+	Detail   bool
+	Operator string
+	NoStyle  bool
+}
+
+func NewRollbackOptions() *RollbackOptions {
+	return &RollbackOptions{}
+}
+
+func NewCmdRollback() *cobra.Command {
+	var (
+		rollbackShort = i18n.T(`Roll back resources to a previous snapshot of the state`)
+
+		rollbackLong = i18n.T(`
+		Roll back resources in the current stack to a previous snapshot of the state.
+
+		Resources recorded in the snapshot with the specified serial are previewed against the latest state,
+		and applied after confirmation. A new state with the rolled back resources is saved once the rollback succeeds.`)
+
+		rollbackExample = i18n.T(`
+		# Roll back resources to the snapshot with serial 3
+		kusion state rollback --serial 3
+
+		# Roll back resources without confirmation
+		kusion state rollback --serial 3 --yes`)
+	)
+
+	o := NewRollbackOptions()
+	cmd := &cobra.Command{
+		Use:     "rollback",
+		Short:   rollbackShort,
+		Long:    templates.LongDesc(rollbackLong),
+		Example: templates.Examples(rollbackExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	o.AddStateFlags(cmd)
+	cmd.Flags().Uint64VarP(&o.Serial, "serial", "", 0,
+		i18n.T("Specify the serial of the state snapshot to roll back to"))
+	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", false,
+		i18n.T("Automatically approve and perform the rollback after previewing it"))
+	cmd.Flags().BoolVarP(&o.Detail, "detail", "d", false,
+		i18n.T("Automatically show preview details after previewing it"))
+	cmd.Flags().StringVarP(&o.Operator, "operator", "", "",
+		i18n.T("Specify the operator"))
+	cmd.Flags().BoolVarP(&o.NoStyle, "no-style", "", false,
+		i18n.T("no-style sets to RawOutput mode and disables all of styling"))
+
+	return cmd
+}
+
+func (o *RollbackOptions) Validate() error {
+	if o.Serial == 0 {
+		return errors.New("--serial is required and must be greater than 0")
+	}
+	return nil
+}
+
+func (o *RollbackOptions) Run() error {
+	if o.NoStyle {
+		pterm.DisableStyling()
+		pterm.DisableColor()
+	}
+
+	project, stack, storage, query, err := o.stateStorage()
+	if err != nil {
+		return err
+	}
+
+	target, err := storage.GetStateBySerial(query, o.Serial)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return fmt.Errorf("can not find State with serial %d in stack %s/%s", o.Serial, query.Project, query.Stack)
+	}
+
+	ao := o.applyOptions()
+	sp := &models.Spec{Resources: target.Resources}
+
+	// Compute changes between the snapshot and the latest state
+	changes, err := previewcmd.Preview(&ao.Options, storage, sp, project, stack)
+	if err != nil {
+		return err
+	}
+
+	if changes.AllUnChange() {
+		fmt.Printf("All resources are consistent with the State of serial %d. No diff found\n", o.Serial)
+		return nil
+	}
+
+	changes.Summary(os.Stdout)
+	if o.Detail {
+		changes.OutputDiff("all")
+	}
+
+	if !o.Yes {
+		for {
+			input, err := prompt()
+			if err != nil {
+				return err
+			}
+			if input == "yes" {
+				break
+			} else if input == "details" {
+				target, err := changes.PromptDetails()
+				if err != nil {
+					return err
+				}
+				changes.OutputDiff(target)
+			} else {
+				fmt.Println("Operation rollback canceled")
+				return nil
+			}
+		}
+	}
+
+	fmt.Printf("Start rolling back to the State of serial %d ...\n", o.Serial)
+	return applycmd.Apply(ao, storage, sp, changes, os.Stdout)
+}
+
+// applyOptions converts the rollback options to apply options, so that the rollback is
+// performed the same way as the command 'kusion apply'
+func (o *RollbackOptions) applyOptions() *applycmd.Options {
+	ao := applycmd.NewApplyOptions()
+	ao.WorkDir = o.WorkDir
+	ao.BackendOps = o.BackendOps
+	ao.Operator = o.Operator
+	ao.NoStyle = o.NoStyle
+	ao.Yes = o.Yes
+	if o.Cluster != "" {
+		ao.Arguments["cluster"] = o.Cluster
+	}
+	return ao
+}
+
+func prompt() (string, error) {
+	prompt := &survey.Select{
+		Message: `Do you want to roll back to this State?`,
+		Options: []string{"yes", "details", "no"},
+		Default: "details",
+	}
+
+	var input string
+	err := survey.AskOne(prompt, &input)
+	if err != nil {
+		fmt.Printf("Prompt failed %v\n", err)
+		return "", err
+	}
+	return input, nil
+}
@@ -0,0 +1,87 @@
+package state
+
+import (
+	"io"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	applycmd "kusionstack.io/kusion/pkg/cmd/apply"
+	previewcmd "kusionstack.io/kusion/pkg/cmd/preview"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+var sa = models.Resource{
+	ID:   "fake-id",
+	Type: "Kubernetes",
+	Attributes: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+	},
+}
+
+func TestRollbackOptions_Validate(t *testing.T) {
+	o := NewRollbackOptions()
+	assert.Error(t, o.Validate())
+
+	o.Serial = 1
+	assert.NoError(t, o.Validate())
+}
+
+func TestRollbackOptions_Run(t *testing.T) {
+	mockey.PatchConvey("serial not found", t, func() {
+		mockDetectProjectAndStack()
+		mockey.Mock((*local.FileSystemState).GetStateBySerial).To(func(f *local.FileSystemState, query *states.StateQuery, serial uint64) (*states.State, error) {
+			return nil, nil
+		}).Build()
+
+		o := NewRollbackOptions()
+		o.Serial = 1
+		assert.Error(t, o.Run())
+	})
+
+	mockey.PatchConvey("rollback to serial", t, func() {
+		mockDetectProjectAndStack()
+		mockey.Mock((*local.FileSystemState).GetStateBySerial).To(func(f *local.FileSystemState, query *states.StateQuery, serial uint64) (*states.State, error) {
+			return &states.State{Serial: serial, Resources: models.Resources{sa}}, nil
+		}).Build()
+		mockey.Mock(previewcmd.Preview).To(func(
+			o *previewcmd.Options,
+			storage states.StateStorage,
+			planResources *models.Spec,
+			project *projectstack.Project,
+			stack *projectstack.Stack,
+		) (*opsmodels.Changes, error) {
+			assert.Equal(t, "dev-cluster", o.Arguments["cluster"])
+			return opsmodels.NewChanges(project, stack, &opsmodels.ChangeOrder{
+				StepKeys: []string{sa.ID},
+				ChangeSteps: map[string]*opsmodels.ChangeStep{
+					sa.ID: {ID: sa.ID, Action: opsmodels.Create, From: &sa},
+				},
+			}), nil
+		}).Build()
+		var applied *models.Spec
+		mockey.Mock(applycmd.Apply).To(func(
+			o *applycmd.Options,
+			storage states.StateStorage,
+			planResources *models.Spec,
+			changes *opsmodels.Changes,
+			out io.Writer,
+		) error {
+			applied = planResources
+			return nil
+		}).Build()
+
+		o := NewRollbackOptions()
+		o.Serial = 1
+		o.Cluster = "dev-cluster"
+		o.Yes = true
+		assert.NoError(t, o.Run())
+		assert.Equal(t, models.Resources{sa}, applied.Resources)
+	})
+}
@@ -0,0 +1,85 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/util/i18n"
+	jsonutil "kusionstack.io/kusion/pkg/util/json"
+)
+
+type ShowOptions struct {
+	Options
+	ID string
+}
+
+func NewShowOptions() *ShowOptions {
+	return &ShowOptions{}
+}
+
+func NewCmdShow() *cobra.Command {
+	var (
+		showShort = i18n.T(`Show a resource in the state`)
+
+		showLong = i18n.T(`
+		Show the attributes of a resource recorded in the latest state of the current stack.`)
+
+		showExample = i18n.T(`
+		# Show a Kubernetes resource in the state
+		kusion state show v1:Namespace:default`)
+	)
+
+	o := NewShowOptions()
+	cmd := &cobra.Command{
+		Use:     "show RESOURCE_ID",
+		Short:   showShort,
+		Long:    templates.LongDesc(showLong),
+		Example: templates.Examples(showExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			o.Complete(args)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	o.AddStateFlags(cmd)
+
+	return cmd
+}
+
+func (o *ShowOptions) Complete(args []string) {
+	if len(args) > 0 {
+		o.ID = args[0]
+	}
+}
+
+func (o *ShowOptions) Validate() error {
+	if o.ID == "" {
+		return errors.New("resource ID is required")
+	}
+	return nil
+}
+
+func (o *ShowOptions) Run() error {
+	_, _, storage, query, err := o.stateStorage()
+	if err != nil {
+		return err
+	}
+
+	state, err := latestState(storage, query)
+	if err != nil {
+		return err
+	}
+	res, ok := state.Resources.Index()[o.ID]
+	if !ok {
+		return fmt.Errorf("can not find resource %s in the State", o.ID)
+	}
+	fmt.Println(jsonutil.Marshal2PrettyString(res))
+	return nil
+}
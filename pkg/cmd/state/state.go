@@ -0,0 +1,40 @@
+package state
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+func NewCmdState() *cobra.Command {
+	var (
+		stateShort = i18n.T(`Inspect and manage the state of the stack`)
+
+		stateLong = i18n.T(`
+		Inspect and manage the state of the current stack.
+
+		The state records all resources applied by Kusion, and it is stored in the backend configured in project.yaml.
+		Commands in this group operate on the state directly, so be careful when modifying it.`)
+	)
+
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: stateShort,
+		Long:  templates.LongDesc(stateLong),
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdList())
+	cmd.AddCommand(NewCmdShow())
+	cmd.AddCommand(NewCmdRm())
+	cmd.AddCommand(NewCmdMv())
+	cmd.AddCommand(NewCmdPull())
+	cmd.AddCommand(NewCmdPush())
+	cmd.AddCommand(NewCmdHistory())
+	cmd.AddCommand(NewCmdRollback())
+
+	return cmd
+}
@@ -0,0 +1,62 @@
+package unlock
+
+import (
+	"errors"
+	"fmt"
+
+	"kusionstack.io/kusion/pkg/engine/backend"
+	_ "kusionstack.io/kusion/pkg/engine/backend/init"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+type Options struct {
+	WorkDir string
+	Cluster string
+	LockID  string
+	backend.BackendOps
+}
+
+func NewUnlockOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) Complete(args []string) {
+	if len(args) > 0 {
+		o.LockID = args[0]
+	}
+}
+
+func (o *Options) Validate() error {
+	if o.LockID == "" {
+		return errors.New("lock ID is required")
+	}
+	return nil
+}
+
+func (o *Options) Run() error {
+	// Parse project and stack of work directory
+	project, stack, err := projectstack.DetectProjectAndStack(o.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	// Get state storage from backend config to manage state
+	stateStorage, err := backend.BackendFromConfig(project.Backend, o.BackendOps, o.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	query := &states.StateQuery{
+		Tenant:  project.Tenant,
+		Project: project.Name,
+		Stack:   stack.Name,
+		Cluster: o.Cluster,
+	}
+	if err = stateStorage.Unlock(query, o.LockID); err != nil {
+		return err
+	}
+
+	fmt.Printf("The state of stack %s/%s has been unlocked\n", project.Name, stack.Name)
+	return nil
+}
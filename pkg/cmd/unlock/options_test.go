@@ -0,0 +1,42 @@
+package unlock
+
+import (
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	o := NewUnlockOptions()
+	o.Complete([]string{})
+	assert.Error(t, o.Validate())
+
+	o.Complete([]string{"lock-id"})
+	assert.NoError(t, o.Validate())
+	assert.Equal(t, "lock-id", o.LockID)
+}
+
+func TestOptions_Run(t *testing.T) {
+	mockey.PatchConvey("unlock state", t, func() {
+		mockey.Mock(projectstack.DetectProjectAndStack).To(func(stackDir string) (*projectstack.Project, *projectstack.Stack, error) {
+			project := &projectstack.Project{ProjectConfiguration: projectstack.ProjectConfiguration{Name: "testdata"}}
+			stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "dev"}}
+			return project, stack, nil
+		}).Build()
+		var unlocked string
+		mockey.Mock((*local.FileSystemState).Unlock).To(func(f *local.FileSystemState, query *states.StateQuery, lockID string) error {
+			unlocked = lockID
+			return nil
+		}).Build()
+
+		o := NewUnlockOptions()
+		o.Complete([]string{"lock-id"})
+		assert.NoError(t, o.Run())
+		assert.Equal(t, "lock-id", unlocked)
+	})
+}
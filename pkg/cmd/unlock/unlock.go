@@ -0,0 +1,55 @@
+package unlock
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"kusionstack.io/kusion/pkg/cmd/util"
+	"kusionstack.io/kusion/pkg/util/i18n"
+)
+
+func NewCmdForceUnlock() *cobra.Command {
+	var (
+		unlockShort = i18n.T(`Release a stuck lock on the state of the stack`)
+
+		unlockLong = i18n.T(`
+		Manually release a lock on the state of the current stack.
+	
+		The lock is acquired by operations like apply and destroy to prevent concurrent modifications of the state,
+		and it will be left behind if the operation is killed unexpectedly.
+		The lock ID is printed in the error message when an operation fails to acquire the lock.
+
+		Be very careful with this command. If you unlock the state while someone else is holding the lock,
+		multiple writers may overwrite each other's state.`)
+
+		unlockExample = i18n.T(`
+		# Release the lock of the current stack
+		kusion force-unlock 2f0c5a3e7d3c4b0e9d6a8b1c5e7f9a2d
+
+		# Release the lock with specifying work directory
+		kusion force-unlock 2f0c5a3e7d3c4b0e9d6a8b1c5e7f9a2d -w /path/to/workdir`)
+	)
+
+	o := NewUnlockOptions()
+	cmd := &cobra.Command{
+		Use:     "force-unlock LOCK_ID",
+		Short:   unlockShort,
+		Long:    templates.LongDesc(unlockLong),
+		Example: templates.Examples(unlockExample),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			defer util.RecoverErr(&err)
+			o.Complete(args)
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+			return
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.WorkDir, "workdir", "w", "",
+		i18n.T("Specify the work directory"))
+	cmd.Flags().StringVarP(&o.Cluster, "cluster", "", "",
+		i18n.T("Specify the cluster of the locked state"))
+	o.AddBackendFlags(cmd)
+
+	return cmd
+}
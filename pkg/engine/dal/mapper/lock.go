@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/didi/gendry/builder"
+	"github.com/didi/gendry/scanner"
+	"github.com/pkg/errors"
+)
+
+// StateLockDO is a record of table state_lock. Table state_lock MUST have a unique key on
+// (tenant, project, stack, cluster) so that only one lock can be inserted for a State, which is
+// created by pkg/engine/dal/schema/state_lock.sql
+type StateLockDO struct {
+	LockID     string    `json:"lock_id"`
+	Tenant     string    `json:"tenant"`
+	Project    string    `json:"project"`
+	Stack      string    `json:"stack"`
+	Cluster    string    `json:"cluster"`
+	Holder     string    `json:"holder"`
+	Operation  string    `json:"operation"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+// GetLock gets one record from table state_lock by condition "where"
+func GetLock(db *sql.DB, where map[string]interface{}) (*StateLockDO, error) {
+	if nil == db {
+		return nil, errors.New("sql.DB is nil")
+	}
+	cond, values, err := builder.BuildSelect("state_lock", where, nil)
+	if nil != err {
+		return nil, err
+	}
+	row, err := db.Query(cond, values...)
+	if nil != err || nil == row {
+		return nil, err
+	}
+	defer row.Close()
+	var dbRes *StateLockDO
+	scanner.SetTagName("json")
+	err = scanner.Scan(row, &dbRes)
+	return dbRes, err
+}
+
+// InsertLock inserts a record into table state_lock
+func InsertLock(db *sql.DB, data map[string]interface{}) error {
+	if nil == db {
+		return errors.New("sql.DB is nil")
+	}
+	cond, values, err := builder.BuildInsert("state_lock", []map[string]interface{}{data})
+	if nil != err {
+		return err
+	}
+	_, err = db.Exec(cond, values...)
+	return err
+}
+
+// DeleteLock deletes records from table state_lock by condition "where"
+func DeleteLock(db *sql.DB, where map[string]interface{}) error {
+	if nil == db {
+		return errors.New("sql.DB is nil")
+	}
+	cond, values, err := builder.BuildDelete("state_lock", where)
+	if nil != err {
+		return err
+	}
+	_, err = db.Exec(cond, values...)
+	return err
+}
@@ -44,6 +44,26 @@ func GetOne(db *sql.DB, where map[string]interface{}) (*StateDO, error) {
 	return dbRes, err
 }
 
+// Get gets all records from table state by condition "where"
+func Get(db *sql.DB, where map[string]interface{}) ([]*StateDO, error) {
+	if nil == db {
+		return nil, errors.New("sql.DB is nil")
+	}
+	cond, values, err := builder.BuildSelect("state", where, nil)
+	if nil != err {
+		return nil, err
+	}
+	row, err := db.Query(cond, values...)
+	if nil != err || nil == row {
+		return nil, err
+	}
+	defer row.Close()
+	var dbRes []*StateDO
+	scanner.SetTagName("json")
+	err = scanner.Scan(row, &dbRes)
+	return dbRes, err
+}
+
 // Insert inserts an array of data into table StateDO
 func Insert(db *sql.DB, data []map[string]interface{}) (int64, error) {
 	if nil == db {
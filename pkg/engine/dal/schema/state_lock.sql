@@ -0,0 +1,13 @@
+-- Table state_lock keeps locks of States in the db backend, and it must be created before locking States.
+-- The primary key guarantees that only one operation can hold the lock of a State
+CREATE TABLE IF NOT EXISTS `state_lock` (
+  `lock_id`     varchar(64)  NOT NULL COMMENT 'ID of the lock, which is required to release it',
+  `tenant`      varchar(128) NOT NULL DEFAULT '',
+  `project`     varchar(128) NOT NULL,
+  `stack`       varchar(128) NOT NULL,
+  `cluster`     varchar(128) NOT NULL DEFAULT '',
+  `holder`      varchar(256) NOT NULL DEFAULT '' COMMENT 'the person who holds the lock',
+  `operation`   varchar(64)  NOT NULL DEFAULT '' COMMENT 'the operation that acquired the lock, such as Apply',
+  `create_time` datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'the time the lock is acquired',
+  PRIMARY KEY (`tenant`, `project`, `stack`, `cluster`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -3,6 +3,7 @@ package engine
 import (
 	"encoding/json"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	kcl "kcl-lang.io/kcl-go"
 
 	"kusionstack.io/kusion/pkg/log"
@@ -36,5 +37,48 @@ func KCLResult2Spec(kclResults []kcl.KCLResult) (*models.Spec, error) {
 		resources[i] = item
 	}
 
+	if err := withClusterIDs(resources); err != nil {
+		return nil, err
+	}
 	return &models.Spec{Resources: resources}, nil
 }
+
+// withClusterIDs appends clusters to IDs of Kubernetes resources which select a cluster in Extensions and whose IDs
+// are built from their attributes, so that the same app can be deployed to several clusters in one stack. DependsOn
+// of resources in the same cluster are updated as well, while implicit references have to use the new IDs
+func withClusterIDs(resources []models.Resource) error {
+	// IDs replaced in each cluster
+	replaced := map[string]map[string]string{}
+	clusters := make([]string, len(resources))
+	for i := range resources {
+		r := &resources[i]
+		cluster, err := r.Cluster()
+		if err != nil {
+			return err
+		}
+		clusters[i] = cluster.String()
+		if r.Type != models.Kubernetes || cluster.IsZero() {
+			continue
+		}
+		o := &unstructured.Unstructured{Object: r.Attributes}
+		if r.ID != BuildIDForKubernetes(o) {
+			continue
+		}
+		id := BuildIDForKubernetesInCluster(o, cluster)
+		if replaced[clusters[i]] == nil {
+			replaced[clusters[i]] = map[string]string{}
+		}
+		replaced[clusters[i]][r.ID] = id
+		r.ID = id
+	}
+
+	for i := range resources {
+		ids := replaced[clusters[i]]
+		for j, dep := range resources[i].DependsOn {
+			if id, ok := ids[dep]; ok {
+				resources[i].DependsOn[j] = id
+			}
+		}
+	}
+	return nil
+}
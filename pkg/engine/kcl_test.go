@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kcl "kcl-lang.io/kcl-go"
+)
+
+func newKCLResult(id, cluster string, dependsOn ...string) kcl.KCLResult {
+	result := kcl.KCLResult{
+		"id":   id,
+		"type": "Kubernetes",
+		"attributes": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "nginx", "namespace": "default"},
+		},
+	}
+	if cluster != "" {
+		result["extensions"] = map[string]interface{}{"cluster": map[string]interface{}{"context": cluster}}
+	}
+	if len(dependsOn) > 0 {
+		result["dependsOn"] = dependsOn
+	}
+	return result
+}
+
+func TestKCLResult2Spec(t *testing.T) {
+	id := "apps/v1:Deployment:default:nginx"
+	ns := kcl.KCLResult{
+		"id":   "v1:Namespace:default",
+		"type": "Kubernetes",
+		"attributes": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "default"},
+		},
+		"extensions": map[string]interface{}{"cluster": map[string]interface{}{"context": "prod"}},
+	}
+
+	spec, err := KCLResult2Spec([]kcl.KCLResult{
+		newKCLResult(id, ""),
+		newKCLResult(id, "prod", "v1:Namespace:default"),
+		newKCLResult(id, "test", "v1:Namespace:default"),
+		newKCLResult("custom", "test"),
+		ns,
+	})
+	require.NoError(t, err)
+	var ids []string
+	for _, r := range spec.Resources {
+		ids = append(ids, r.ID)
+	}
+	assert.Equal(t, []string{
+		id,
+		id + "@prod",
+		id + "@test",
+		"custom",
+		"v1:Namespace:default@prod",
+	}, ids)
+	// dependencies are replaced in the same cluster only
+	assert.Equal(t, []string{"v1:Namespace:default@prod"}, spec.Resources[1].DependsOn)
+	assert.Equal(t, []string{"v1:Namespace:default"}, spec.Resources[2].DependsOn)
+
+	_, err = KCLResult2Spec([]kcl.KCLResult{{"id": "invalid", "extensions": map[string]interface{}{"cluster": "prod"}}})
+	assert.Error(t, err)
+}
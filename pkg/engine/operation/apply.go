@@ -3,6 +3,7 @@ package operation
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 
 	"kusionstack.io/kusion/pkg/engine/operation/graph"
@@ -12,6 +13,7 @@ import (
 	"kusionstack.io/kusion/pkg/engine/states"
 	"kusionstack.io/kusion/pkg/log"
 	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
 	"kusionstack.io/kusion/pkg/status"
 	"kusionstack.io/kusion/third_party/terraform/dag"
 	"kusionstack.io/kusion/third_party/terraform/tfdiags"
@@ -19,6 +21,9 @@ import (
 
 type ApplyOperation struct {
 	opsmodels.Operation
+
+	// applied contains resource nodes applied successfully, which will be reverted if RollbackOnFailure is set
+	applied []*graph.ResourceNode
 }
 
 type ApplyRequest struct {
@@ -27,6 +32,9 @@ type ApplyRequest struct {
 
 type ApplyResponse struct {
 	State *states.State
+
+	// Rollback is the result of reverting applied resources when the apply fails with RollbackOnFailure set
+	Rollback *RollbackReport
 }
 
 func NewApplyGraph(m *models.Spec, priorState *states.State) (*dag.AcyclicGraph, status.Status) {
@@ -77,8 +85,20 @@ func (ao *ApplyOperation) Apply(request *ApplyRequest) (rsp *ApplyResponse, st s
 		return nil, st
 	}
 
+	// 0. lock the State to prevent concurrent operations on the same stack
+	unlock, st := lockState(o.StateStorage, &request.Request, opsmodels.Apply)
+	if status.IsErr(st) {
+		return nil, st
+	}
+	defer unlock()
+
 	// 1. init & build Indexes
 	priorState, resultState := o.InitStates(&request.Request)
+	if request.ExpectedSerial != nil && priorState.Serial != *request.ExpectedSerial {
+		return nil, status.NewErrorStatusWithMsg(status.InvalidArgument, fmt.Sprintf(
+			"the State has been modified since the plan was saved, expected serial %d but got %d. Please preview again",
+			*request.ExpectedSerial, priorState.Serial))
+	}
 	priorStateResourceIndex := priorState.Resources.Index()
 	// copy priorStateResourceIndex into a new map
 	stateResourceIndex := map[string]*models.Resource{}
@@ -88,7 +108,7 @@ func (ao *ApplyOperation) Apply(request *ApplyRequest) (rsp *ApplyResponse, st s
 
 	resources := request.Spec.Resources
 	resources = append(resources, priorState.Resources...)
-	runtimesMap, s := runtimeinit.Runtimes(resources)
+	runtimesMap, s := runtimeinit.Runtimes(resources, request.Project)
 	if status.IsErr(s) {
 		return nil, s
 	}
@@ -99,7 +119,17 @@ func (ao *ApplyOperation) Apply(request *ApplyRequest) (rsp *ApplyResponse, st s
 	if status.IsErr(s) {
 		return nil, s
 	}
+	if s = pruneGraph(applyGraph, request.Targets); status.IsErr(s) {
+		return nil, s
+	}
+	if s = parser.CheckPreventDestroy(applyGraph); status.IsErr(s) {
+		return nil, s
+	}
 	log.Infof("Apply Graph:\n%s", applyGraph.String())
+	retry, s := retryPolicy(&o)
+	if status.IsErr(s) {
+		return nil, s
+	}
 
 	applyOperation := &ApplyOperation{
 		Operation: opsmodels.Operation{
@@ -111,24 +141,69 @@ func (ao *ApplyOperation) Apply(request *ApplyRequest) (rsp *ApplyResponse, st s
 			RuntimeMap:              o.RuntimeMap,
 			Stack:                   o.Stack,
 			IgnoreFields:            o.IgnoreFields,
+			ChangeOrder:             o.ChangeOrder,
 			MsgCh:                   o.MsgCh,
 			ResultState:             resultState,
 			Lock:                    &sync.Mutex{},
 			SecretStores:            o.SecretStores,
+			Parallelism:             o.Parallelism,
+			FailurePolicy:           o.FailurePolicy,
+			ReadinessTimeout:        o.ReadinessTimeout,
+			RollbackOnFailure:       o.RollbackOnFailure,
+			ForceConflicts:          o.ForceConflicts,
+			Ctx:                     o.Ctx,
+			RetryPolicy:             retry,
 		},
 	}
 
-	w := &dag.Walker{Callback: applyOperation.applyWalkFun}
-	w.Update(applyGraph)
-	// Wait
-	if diags := w.Wait(); diags.HasErrors() {
-		st = status.NewErrorStatus(diags.Err())
+	diags := walkResources(&applyOperation.Operation, applyGraph, applyOperation.applyWalkFun)
+	// resources completed have been saved in the State one by one, so only report resources never attempted
+	if st = interruptedStatus(&applyOperation.Operation, diags); status.IsErr(st) {
+		return &ApplyResponse{State: resultState}, st
+	}
+	if diags.HasErrors() {
+		if !o.RollbackOnFailure {
+			st = status.NewErrorStatus(diags.Err())
+			return nil, st
+		}
+		// 3. revert resources applied successfully if required
+		report := applyOperation.rollback(applyGraph)
+		st = status.NewErrorStatus(fmt.Errorf("%w\n%s", diags.Err(), report))
+		return &ApplyResponse{State: resultState, Rollback: report}, st
+	}
+
+	// 4. resolve outputs of the stack with applied resources and save them in the State
+	if st = applyOperation.updateOutputs(request.Stack); status.IsErr(st) {
 		return nil, st
 	}
 
 	return &ApplyResponse{State: resultState}, nil
 }
 
+// updateOutputs resolves outputs declared in the stack, and saves the State if outputs are changed
+func (ao *ApplyOperation) updateOutputs(stack *projectstack.Stack) status.Status {
+	var declared map[string]string
+	if stack != nil {
+		declared = stack.Outputs
+	}
+
+	ao.Lock.Lock()
+	outputs, s := ResolveOutputs(declared, ao.StateResourceIndex)
+	ao.Lock.Unlock()
+	if status.IsErr(s) {
+		return s
+	}
+	if reflect.DeepEqual(outputs, ao.ResultState.Outputs) {
+		return nil
+	}
+
+	ao.ResultState.Outputs = outputs
+	if err := ao.UpdateState(ao.StateResourceIndex); err != nil {
+		return status.NewErrorStatus(err)
+	}
+	return nil
+}
+
 func (ao *ApplyOperation) applyWalkFun(v dag.Vertex) (diags tfdiags.Diagnostics) {
 	var s status.Status
 	if v == nil {
@@ -148,6 +223,9 @@ func (ao *ApplyOperation) applyWalkFun(v dag.Vertex) (diags tfdiags.Diagnostics)
 				}
 			} else {
 				o.MsgCh <- opsmodels.Message{ResourceID: rn.Hashcode().(string), OpResult: opsmodels.Success}
+				if o.RollbackOnFailure {
+					ao.recordApplied(rn)
+				}
 			}
 		} else {
 			s = node.Execute(o)
@@ -12,7 +12,9 @@ import (
 	"github.com/bytedance/mockey"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
+	kcl "kcl-lang.io/kcl-go"
 
+	"kusionstack.io/kusion/pkg/engine"
 	"kusionstack.io/kusion/pkg/engine/operation/graph"
 	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
 	"kusionstack.io/kusion/pkg/engine/runtime"
@@ -25,6 +27,28 @@ import (
 	"kusionstack.io/kusion/pkg/status"
 )
 
+// clusterSpec returns the Spec compiled from the same deployment in each cluster
+func clusterSpec(t *testing.T, clusters ...string) *models.Spec {
+	var results []kcl.KCLResult
+	for _, cluster := range clusters {
+		results = append(results, kcl.KCLResult{
+			"id":   "apps/v1:Deployment:default:nginx",
+			"type": "Kubernetes",
+			"attributes": map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "nginx", "namespace": "default"},
+			},
+			"extensions": map[string]interface{}{"cluster": map[string]interface{}{"context": cluster}},
+		})
+	}
+	spec, err := engine.KCLResult2Spec(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
 func Test_validateRequest(t *testing.T) {
 	type args struct {
 		request *opsmodels.Request
@@ -51,6 +75,25 @@ func Test_validateRequest(t *testing.T) {
 			},
 			want: nil,
 		},
+		{
+			name: "same resource in several clusters",
+			args: args{
+				request: &opsmodels.Request{
+					Spec: clusterSpec(t, "prod", "test"),
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "duplicate resource",
+			args: args{
+				request: &opsmodels.Request{
+					Spec: clusterSpec(t, "prod", "prod"),
+				},
+			},
+			want: status.NewErrorStatusWithMsg(status.InvalidArgument,
+				"Duplicate resource:apps/v1:Deployment:default:nginx@prod in request."),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -148,7 +191,7 @@ func TestOperation_Apply(t *testing.T) {
 				Operator: "faker",
 				Spec:     mf,
 			}}},
-			wantRsp: &ApplyResponse{rs},
+			wantRsp: &ApplyResponse{State: rs},
 			wantSt:  nil,
 		},
 	}
@@ -176,7 +219,7 @@ func TestOperation_Apply(t *testing.T) {
 				o.ResultState = rs
 				return nil
 			}).Build()
-			mockey.Mock(runtimeinit.Runtimes).To(func(resources models.Resources) (map[models.Type]runtime.Runtime, status.Status) {
+			mockey.Mock(runtimeinit.Runtimes).To(func(resources models.Resources, project *projectstack.Project) (map[models.Type]runtime.Runtime, status.Status) {
 				return map[models.Type]runtime.Runtime{runtime.Kubernetes: &kubernetes.KubernetesRuntime{}}, nil
 			}).Build()
 
@@ -186,3 +229,28 @@ func TestOperation_Apply(t *testing.T) {
 		})
 	}
 }
+
+func TestOperation_ApplyWithExpectedSerial(t *testing.T) {
+	mockey.PatchConvey("state serial has moved", t, func() {
+		stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "fakeStack"}}
+		project := &projectstack.Project{ProjectConfiguration: projectstack.ProjectConfiguration{Name: "fakeProject"}}
+		mockey.Mock((*opsmodels.Operation).InitStates).To(func(o *opsmodels.Operation, request *opsmodels.Request) (*states.State, *states.State) {
+			return &states.State{Serial: 2}, states.NewState()
+		}).Build()
+
+		serial := uint64(1)
+		ao := &ApplyOperation{Operation: opsmodels.Operation{
+			OperationType: opsmodels.Apply,
+			StateStorage:  &local.FileSystemState{Path: filepath.Join("test_data", local.KusionState)},
+			MsgCh:         make(chan opsmodels.Message, 5),
+		}}
+		_, st := ao.Apply(&ApplyRequest{opsmodels.Request{
+			Stack:          stack,
+			Project:        project,
+			Spec:           &models.Spec{},
+			ExpectedSerial: &serial,
+		}})
+		assert.True(t, status.IsErr(st))
+		assert.Contains(t, st.Message(), "expected serial 1 but got 2")
+	})
+}
@@ -34,7 +34,8 @@ func NewDestroyGraph(resource models.Resources) (*dag.AcyclicGraph, status.Statu
 }
 
 // Destroy will delete all resources in this request. The whole process is similar to the operation Apply,
-// but every node's execution is deleting the resource.
+// but every node's execution is deleting the resource. If Targets is set, only matched resources and their
+// dependents are deleted, and other resources are kept in the State.
 func (do *DestroyOperation) Destroy(request *DestroyRequest) (st status.Status) {
 	o := do.Operation
 	defer close(o.MsgCh)
@@ -43,8 +44,19 @@ func (do *DestroyOperation) Destroy(request *DestroyRequest) (st status.Status)
 		return st
 	}
 
+	// 0. lock the State to prevent concurrent operations on the same stack
+	unlock, st := lockState(o.StateStorage, &request.Request, opsmodels.Destroy)
+	if status.IsErr(st) {
+		return st
+	}
+	defer unlock()
+
 	// 1. init & build Indexes
 	priorState, resultState := o.InitStates(&request.Request)
+	// outputs are resolved from resources, so they are dropped once all resources are destroyed
+	if len(request.Targets) == 0 {
+		resultState.Outputs = nil
+	}
 	priorStateResourceIndex := priorState.Resources.Index()
 	// copy priorStateResourceIndex into a new map
 	stateResourceIndex := map[string]*models.Resource{}
@@ -54,7 +66,7 @@ func (do *DestroyOperation) Destroy(request *DestroyRequest) (st status.Status)
 
 	// only destroy resources we have recorded
 	resources := priorState.Resources
-	runtimesMap, s := runtimeinit.Runtimes(resources)
+	runtimesMap, s := runtimeinit.Runtimes(resources, request.Project)
 	if status.IsErr(s) {
 		return s
 	}
@@ -65,6 +77,16 @@ func (do *DestroyOperation) Destroy(request *DestroyRequest) (st status.Status)
 	if status.IsErr(s) {
 		return s
 	}
+	if s = pruneGraph(destroyGraph, request.Targets); status.IsErr(s) {
+		return s
+	}
+	if s = parser.CheckPreventDestroy(destroyGraph); status.IsErr(s) {
+		return s
+	}
+	retry, s := retryPolicy(&o)
+	if status.IsErr(s) {
+		return s
+	}
 
 	newDo := &DestroyOperation{
 		Operation: opsmodels.Operation{
@@ -78,13 +100,18 @@ func (do *DestroyOperation) Destroy(request *DestroyRequest) (st status.Status)
 			MsgCh:                   o.MsgCh,
 			ResultState:             resultState,
 			Lock:                    &sync.Mutex{},
+			Parallelism:             o.Parallelism,
+			FailurePolicy:           o.FailurePolicy,
+			Ctx:                     o.Ctx,
+			RetryPolicy:             retry,
 		},
 	}
 
-	w := &dag.Walker{Callback: newDo.destroyWalkFun}
-	w.Update(destroyGraph)
-	// Wait
-	if diags := w.Wait(); diags.HasErrors() {
+	diags := walkResources(&newDo.Operation, destroyGraph, newDo.destroyWalkFun)
+	if st = interruptedStatus(&newDo.Operation, diags); status.IsErr(st) {
+		return st
+	}
+	if diags.HasErrors() {
 		st = status.NewErrorStatus(diags.Err())
 		return st
 	}
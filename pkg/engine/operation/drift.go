@@ -0,0 +1,194 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	runtimeinit "kusionstack.io/kusion/pkg/engine/runtime/init"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+	"kusionstack.io/kusion/pkg/util/diff"
+	"kusionstack.io/kusion/third_party/dyff"
+)
+
+// kubernetesServerSideFields are fields maintained by the Kubernetes API server. They change without any manual
+// modification, so they are ignored when detecting drift of Kubernetes resources
+var kubernetesServerSideFields = []string{
+	"status",
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.managedFields",
+	"metadata.creationTimestamp",
+	"metadata.uid",
+	"metadata.selfLink",
+}
+
+type DriftOperation struct {
+	opsmodels.Operation
+}
+
+type DriftRequest struct {
+	opsmodels.Request `json:",inline" yaml:",inline"`
+}
+
+type DriftResponse struct {
+	// Resources contains the drift of all resources in the State, including resources without drift
+	Resources []*ResourceDrift `json:"resources"`
+}
+
+// ResourceDrift is the difference between a resource recorded in the State and the live resource in the actual infrastructure
+type ResourceDrift struct {
+	ID   string      `json:"id"`
+	Type models.Type `json:"type"`
+
+	// Deleted means this resource doesn't exist in the actual infrastructure anymore
+	Deleted bool `json:"deleted,omitempty"`
+
+	// Added are fields that only exist in the live resource
+	Added []*FieldDrift `json:"added,omitempty"`
+
+	// Removed are fields that only exist in the State
+	Removed []*FieldDrift `json:"removed,omitempty"`
+
+	// Changed are fields whose values in the live resource are different from the State
+	Changed []*FieldDrift `json:"changed,omitempty"`
+}
+
+// FieldDrift is the drift of a field in the resource attributes
+type FieldDrift struct {
+	// Path of the field, e.g. /spec/replicas
+	Path string `json:"path"`
+
+	// From is the value recorded in the State
+	From interface{} `json:"from,omitempty"`
+
+	// To is the value of the live resource
+	To interface{} `json:"to,omitempty"`
+}
+
+// Drifted returns true if the live resource is different from the State
+func (d *ResourceDrift) Drifted() bool {
+	return d.Deleted || len(d.Added) != 0 || len(d.Removed) != 0 || len(d.Changed) != 0
+}
+
+// Drifted returns all drifted resources in this response
+func (r *DriftResponse) Drifted() []*ResourceDrift {
+	var drifted []*ResourceDrift
+	for _, d := range r.Resources {
+		if d.Drifted() {
+			drifted = append(drifted, d)
+		}
+	}
+	return drifted
+}
+
+// Drift compares every resource in the latest State with the live resource read from its Runtime, and reports
+// the differences made outside Kusion. It is a read-only operation, so neither the State nor the actual
+// infrastructure will be changed.
+func (dro *DriftOperation) Drift(request *DriftRequest) (rsp *DriftResponse, st status.Status) {
+	log.Infof("engine: Drift start!")
+	o := dro.Operation
+
+	defer func() {
+		if e := recover(); e != nil {
+			log.Error("drift panic:%v", e)
+
+			switch x := e.(type) {
+			case string:
+				st = status.NewErrorStatus(fmt.Errorf("drift panic:%s", e))
+			case error:
+				st = status.NewErrorStatus(x)
+			default:
+				st = status.NewErrorStatusWithCode(status.Unknown, errors.New("unknown panic"))
+			}
+		}
+	}()
+
+	if request == nil {
+		return nil, status.NewErrorStatusWithMsg(status.InvalidArgument, "request is nil")
+	}
+
+	priorState, _ := o.InitStates(&request.Request)
+	runtimesMap, s := runtimeinit.Runtimes(priorState.Resources, request.Project)
+	if status.IsErr(s) {
+		return nil, s
+	}
+	o.RuntimeMap = runtimesMap
+
+	rsp = &DriftResponse{Resources: make([]*ResourceDrift, 0, len(priorState.Resources))}
+	for i := range priorState.Resources {
+		d, s := dro.resourceDrift(&priorState.Resources[i], runtimesMap[priorState.Resources[i].Type])
+		if status.IsErr(s) {
+			return nil, s
+		}
+		rsp.Resources = append(rsp.Resources, d)
+	}
+	return rsp, nil
+}
+
+func (dro *DriftOperation) resourceDrift(prior *models.Resource, rt runtime.Runtime) (*ResourceDrift, status.Status) {
+	d := &ResourceDrift{ID: prior.ResourceKey(), Type: prior.Type}
+
+	response := rt.Read(dro.Context(), &runtime.ReadRequest{
+		PriorResource: prior,
+		Stack:         dro.Stack,
+	})
+	if status.IsErr(response.Status) {
+		return nil, response.Status
+	}
+	if response.Resource == nil {
+		d.Deleted = true
+		return d, nil
+	}
+
+	// copy attributes to avoid modifying the State
+	from := prior.DeepCopy().Attributes
+	to := response.Resource.DeepCopy().Attributes
+	ignoreFields := append([]string{}, dro.IgnoreFields...)
+	if prior.Type == runtime.Kubernetes {
+		ignoreFields = append(ignoreFields, kubernetesServerSideFields...)
+	}
+	for _, field := range ignoreFields {
+		splits := strings.Split(field, ".")
+		graph.RemoveNestedField(from, splits...)
+		graph.RemoveNestedField(to, splits...)
+	}
+
+	report, err := diff.ToReport(from, to)
+	if err != nil {
+		return nil, status.NewErrorStatus(err)
+	}
+	for _, dd := range report.Diffs {
+		path := dd.Path.String()
+		for _, detail := range dd.Details {
+			fd := &FieldDrift{Path: path, From: decodeNode(detail.From), To: decodeNode(detail.To)}
+			switch detail.Kind {
+			case dyff.ADDITION:
+				d.Added = append(d.Added, fd)
+			case dyff.REMOVAL:
+				d.Removed = append(d.Removed, fd)
+			case dyff.MODIFICATION:
+				d.Changed = append(d.Changed, fd)
+			}
+		}
+	}
+	return d, nil
+}
+
+func decodeNode(node *yamlv3.Node) interface{} {
+	if node == nil {
+		return nil
+	}
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return node.Value
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+//go:build !arm64
+// +build !arm64
+
+package operation
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	runtimeinit "kusionstack.io/kusion/pkg/engine/runtime/init"
+	"kusionstack.io/kusion/pkg/engine/runtime/kubernetes"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+func TestDriftOperation_Drift(t *testing.T) {
+	stack := &projectstack.Stack{
+		StackConfiguration: projectstack.StackConfiguration{Name: "fakeStack"},
+		Path:               "fakePath",
+	}
+	project := &projectstack.Project{
+		ProjectConfiguration: projectstack.ProjectConfiguration{
+			Name:   "fakeProject",
+			Tenant: "fakeTenant",
+		},
+		Path:   "fakePath",
+		Stacks: []*projectstack.Stack{stack},
+	}
+	deployment := func(name string, replicas int, resourceVersion string) models.Resource {
+		return models.Resource{
+			ID:   "apps/v1:Deployment:default:" + name,
+			Type: runtime.Kubernetes,
+			Attributes: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":            name,
+					"namespace":       "default",
+					"resourceVersion": resourceVersion,
+				},
+				"spec": map[string]interface{}{
+					"replicas": replicas,
+				},
+			},
+		}
+	}
+	live := map[string]models.Resource{
+		"apps/v1:Deployment:default:unchanged": deployment("unchanged", 1, "2"),
+		"apps/v1:Deployment:default:changed":   deployment("changed", 3, "1"),
+	}
+
+	mockey.PatchConvey("drift test", t, func() {
+		storage := &local.FileSystemState{Path: filepath.Join(t.TempDir(), local.KusionState)}
+		assert.NoError(t, storage.Apply(&states.State{Resources: models.Resources{
+			deployment("unchanged", 1, "1"),
+			deployment("changed", 1, "1"),
+			deployment("deleted", 1, "1"),
+		}}))
+
+		mockey.Mock(runtimeinit.Runtimes).To(func(resources models.Resources, project *projectstack.Project) (map[models.Type]runtime.Runtime, status.Status) {
+			return map[models.Type]runtime.Runtime{runtime.Kubernetes: &kubernetes.KubernetesRuntime{}}, nil
+		}).Build()
+		mockey.Mock((*kubernetes.KubernetesRuntime).Read).To(func(
+			k *kubernetes.KubernetesRuntime,
+			ctx context.Context,
+			request *runtime.ReadRequest,
+		) *runtime.ReadResponse {
+			if res, ok := live[request.PriorResource.ID]; ok {
+				return &runtime.ReadResponse{Resource: &res}
+			}
+			return &runtime.ReadResponse{}
+		}).Build()
+
+		dro := &DriftOperation{Operation: opsmodels.Operation{StateStorage: storage, Stack: stack}}
+		rsp, st := dro.Drift(&DriftRequest{Request: opsmodels.Request{
+			Tenant:  "fakeTenant",
+			Stack:   stack,
+			Project: project,
+		}})
+		assert.Nil(t, st)
+		assert.Len(t, rsp.Resources, 3)
+		assert.False(t, rsp.Resources[0].Drifted())
+
+		drifted := rsp.Drifted()
+		assert.Len(t, drifted, 2)
+		assert.Equal(t, "apps/v1:Deployment:default:changed", drifted[0].ID)
+		assert.Equal(t, []*FieldDrift{{Path: "/spec/replicas", From: 1, To: 3}}, drifted[0].Changed)
+		assert.True(t, drifted[1].Deleted)
+	})
+}
@@ -22,6 +22,9 @@ type ResourceNode struct {
 	*baseNode
 	Action   opsmodels.ActionType
 	resource *models.Resource
+
+	// conflicts are fields owned by other managers that applying the resource would overwrite
+	conflicts []string
 }
 
 var _ ExecutableNode = (*ResourceNode)(nil)
@@ -31,9 +34,16 @@ const (
 )
 
 func (rn *ResourceNode) PreExecute(o *opsmodels.Operation) status.Status {
-	value := reflect.ValueOf(rn.resource.Attributes)
+	if o.OperationType != opsmodels.ApplyPreview && o.OperationType != opsmodels.Apply {
+		return nil
+	}
 	var replaced reflect.Value
-	var s status.Status
+
+	// replace refs to other stacks first, which must be resolved even in the preview stage
+	value, s := ReplaceStackRef(reflect.ValueOf(rn.resource.Attributes), o)
+	if status.IsErr(s) {
+		return s
+	}
 
 	switch o.OperationType {
 	case opsmodels.ApplyPreview:
@@ -46,8 +56,6 @@ func (rn *ResourceNode) PreExecute(o *opsmodels.Operation) status.Status {
 	case opsmodels.Apply:
 		// replace secret ref and implicit ref
 		_, replaced, s = ReplaceRef(value, o.CtxResourceIndex, MustImplicitReplaceFun, o.SecretStores, vals.ParseSecretRef)
-	default:
-		return nil
 	}
 	if status.IsErr(s) {
 		return s
@@ -89,12 +97,19 @@ func (rn *ResourceNode) Execute(operation *opsmodels.Operation) (s status.Status
 	if status.IsErr(s) {
 		return s
 	}
+	planedResource, err := ignoreChanges(planedResource, liveResource)
+	if err != nil {
+		return status.NewErrorStatusWithMsg(status.IllegalManifest, err.Error())
+	}
 
 	// compute action type
 	dryRunResource, s := rn.computeActionType(operation, planedResource, priorResource, liveResource)
 	if status.IsErr(s) {
 		return s
 	}
+	if s = rn.checkPreventDestroy(); status.IsErr(s) {
+		return s
+	}
 
 	// execute the operation
 	switch operation.OperationType {
@@ -106,6 +121,9 @@ func (rn *ResourceNode) Execute(operation *opsmodels.Operation) (s status.Status
 		}
 		updateChangeOrder(operation, rn, liveResource, dryRunResource)
 	case opsmodels.Apply, opsmodels.Destroy:
+		if s = rn.checkPlannedAction(operation); status.IsErr(s) {
+			return s
+		}
 		if s = rn.applyResource(operation, priorResource, planedResource, liveResource); status.IsErr(s) {
 			return s
 		}
@@ -116,6 +134,40 @@ func (rn *ResourceNode) Execute(operation *opsmodels.Operation) (s status.Status
 	return nil
 }
 
+// checkPreventDestroy refuses to delete or replace the resource if preventDestroy is set in its lifecycle. Deleting
+// an abandoned resource is allowed, which only drops it from the State
+func (rn *ResourceNode) checkPreventDestroy() status.Status {
+	if rn.Action != opsmodels.Delete && rn.Action != opsmodels.Replace {
+		return nil
+	}
+	lifecycle, err := rn.resource.Lifecycle()
+	if err != nil {
+		return status.NewErrorStatusWithMsg(status.IllegalManifest, err.Error())
+	}
+	if lifecycle.PreventDestroy && !(rn.Action == opsmodels.Delete && lifecycle.Abandon()) {
+		return status.NewErrorStatusWithMsg(status.InvalidArgument, fmt.Sprintf(
+			"can't %s resource %s since preventDestroy is set in its lifecycle", strings.ToLower(rn.Action.String()), rn.ID))
+	}
+	return nil
+}
+
+// checkPlannedAction makes sure the action of this node is the same as the one in the saved Plan, if the operation
+// is applying a Plan
+func (rn *ResourceNode) checkPlannedAction(operation *opsmodels.Operation) status.Status {
+	if operation.OperationType != opsmodels.Apply || operation.ChangeOrder == nil {
+		return nil
+	}
+	step := operation.ChangeOrder.Get(rn.ID)
+	if step == nil {
+		return status.NewErrorStatus(fmt.Errorf("resource %s is not in the plan", rn.ID))
+	}
+	if step.Action != rn.Action {
+		return status.NewErrorStatus(fmt.Errorf("resource %s has changed since the plan was saved, planned to %s but it needs to %s now",
+			rn.ID, step.Action, rn.Action))
+	}
+	return nil
+}
+
 // computeActionType compute ActionType of current resource node according to  planResource, priorResource and liveResource.
 // dryRunResource is a middle result during the process of computing ActionType. We will use it to perform live diff latter
 func (rn *ResourceNode) computeActionType(
@@ -135,27 +187,41 @@ func (rn *ResourceNode) computeActionType(
 			rn.Action = opsmodels.Create
 		} else {
 			// Dry run to fetch predictable resource
-			dryRunResp := operation.RuntimeMap[rn.resource.Type].Apply(context.Background(), &runtime.ApplyRequest{
-				PriorResource: priorResource,
-				PlanResource:  planedResource,
-				Stack:         operation.Stack,
-				DryRun:        true,
+			var dryRunResp *runtime.ApplyResponse
+			s := rn.callWithRetry(operation, func() status.Status {
+				dryRunResp = operation.RuntimeMap[rn.resource.Type].Apply(operation.Context(), &runtime.ApplyRequest{
+					PriorResource: priorResource,
+					PlanResource:  planedResource,
+					Stack:         operation.Stack,
+					DryRun:        true,
+				})
+				return dryRunResp.Status
 			})
-			if status.IsErr(dryRunResp.Status) {
-				return nil, dryRunResp.Status
+			if status.IsErr(s) {
+				return nil, s
 			}
 			dryRunResource = dryRunResp.Resource
-			// Ignore differences of target fields
-			for _, field := range operation.IgnoreFields {
+			rn.conflicts = dryRunResp.Conflicts
+			lifecycle, err := planedResource.Lifecycle()
+			if err != nil {
+				return nil, status.NewErrorStatusWithMsg(status.IllegalManifest, err.Error())
+			}
+			// Ignore differences of target fields, both from the operation and the lifecycle of this resource
+			ignoreFields := append(append([]string{}, operation.IgnoreFields...), lifecycle.IgnoreChanges...)
+			for _, field := range ignoreFields {
 				splits := strings.Split(field, ".")
-				removeNestedField(liveResource.Attributes, splits...)
-				removeNestedField(dryRunResource.Attributes, splits...)
+				if liveResource != nil {
+					RemoveNestedField(liveResource.Attributes, splits...)
+				}
+				RemoveNestedField(dryRunResource.Attributes, splits...)
 			}
 			report, err := diff.ToReport(liveResource, dryRunResource)
 			if err != nil {
 				return nil, status.NewErrorStatus(err)
 			}
-			if len(report.Diffs) == 0 {
+			if dryRunResp.RequiresReplace {
+				rn.Action = opsmodels.Replace
+			} else if len(report.Diffs) == 0 {
 				rn.Action = opsmodels.UnChanged
 			} else {
 				rn.Action = opsmodels.Update
@@ -187,16 +253,20 @@ func (rn *ResourceNode) initThreeWayDiffData(operation *opsmodels.Operation) (*m
 		Stack:         operation.Stack,
 	}
 	resourceType := rn.resource.Type
-	response := operation.RuntimeMap[resourceType].Read(context.Background(), readRequest)
+	var response *runtime.ReadResponse
+	s := rn.callWithRetry(operation, func() status.Status {
+		response = operation.RuntimeMap[resourceType].Read(operation.Context(), readRequest)
+		return response.Status
+	})
 	liveResource := response.Resource
-	s := response.Status
 	if status.IsErr(s) {
 		return nil, nil, nil, s
 	}
 	return planedResource, priorResource, liveResource, nil
 }
 
-func removeNestedField(obj interface{}, fields ...string) {
+// RemoveNestedField removes the field located by fields in obj. Fields in all elements are removed if a slice is met
+func RemoveNestedField(obj interface{}, fields ...string) {
 	m := obj
 	switch next := m.(type) {
 	case map[string]interface{}:
@@ -204,35 +274,145 @@ func removeNestedField(obj interface{}, fields ...string) {
 			delete(next, fields[0])
 			return
 		} else {
-			removeNestedField(next[fields[0]], fields[1:]...)
+			RemoveNestedField(next[fields[0]], fields[1:]...)
 		}
 	case []interface{}:
 		for _, n := range next {
-			removeNestedField(n, fields...)
+			RemoveNestedField(n, fields...)
 		}
 	default:
 		return
 	}
 }
 
+// ignoreChanges returns a copy of the planed resource whose fields in ignoreChanges of its lifecycle are set to the
+// values of the live resource, or removed if the live resource doesn't have them, so that changes of these fields are
+// never applied. The planed resource is returned as it is if it is nil or there is no live resource
+func ignoreChanges(planed, live *models.Resource) (*models.Resource, error) {
+	if planed == nil || live == nil {
+		return planed, nil
+	}
+	lifecycle, err := planed.Lifecycle()
+	if err != nil {
+		return nil, err
+	}
+	if len(lifecycle.IgnoreChanges) == 0 {
+		return planed, nil
+	}
+	out := *planed
+	var attributes interface{} = planed.Attributes
+	for _, field := range lifecycle.IgnoreChanges {
+		attributes = withNestedField(attributes, live.Attributes, strings.Split(field, ".")...)
+	}
+	out.Attributes = attributes.(map[string]interface{})
+	return &out, nil
+}
+
+// withNestedField returns a copy of dst whose field located by fields is set to the one in src, or removed if src
+// doesn't have it. Fields in elements of the same index are set if slices are met, and dst itself is not changed
+func withNestedField(dst, src interface{}, fields ...string) interface{} {
+	switch next := dst.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(next))
+		for k, v := range next {
+			out[k] = v
+		}
+		srcMap, _ := src.(map[string]interface{})
+		if len(fields) == 1 {
+			if v, ok := srcMap[fields[0]]; ok {
+				out[fields[0]] = copyValue(v)
+			} else {
+				delete(out, fields[0])
+			}
+			return out
+		}
+		if v, ok := next[fields[0]]; ok {
+			out[fields[0]] = withNestedField(v, srcMap[fields[0]], fields[1:]...)
+		}
+		return out
+	case []interface{}:
+		srcSlice, _ := src.([]interface{})
+		out := make([]interface{}, len(next))
+		for i, n := range next {
+			var v interface{}
+			if i < len(srcSlice) {
+				v = srcSlice[i]
+			}
+			out[i] = withNestedField(n, v, fields...)
+		}
+		return out
+	default:
+		return dst
+	}
+}
+
+// copyValue returns a deep copy of maps and slices in v
+func copyValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			out[k] = copyValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, item := range value {
+			out[i] = copyValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
 func (rn *ResourceNode) applyResource(operation *opsmodels.Operation, prior, planed, live *models.Resource) status.Status {
 	log.Infof("operation:%v, prior:%v, plan:%v, live:%v", rn.Action, jsonutil.Marshal2String(prior),
 		jsonutil.Marshal2String(planed), jsonutil.Marshal2String(live))
 
 	var res *models.Resource
 	var s status.Status
+	// aborted means the resource is aborted by an interruption, and res is its live state read afterwards
+	var aborted bool
+	// deleted means the resource has been deleted by a failed replacement
+	var deleted bool
 	resourceType := rn.resource.Type
 
 	rt := operation.RuntimeMap[resourceType]
 	switch rn.Action {
-	case opsmodels.Create, opsmodels.Update:
-		response := rt.Apply(context.Background(), &runtime.ApplyRequest{PriorResource: prior, PlanResource: planed, Stack: operation.Stack})
+	case opsmodels.Create, opsmodels.Update, opsmodels.Replace:
+		var response *runtime.ApplyResponse
+		s = rn.callWithRetry(operation, func() status.Status {
+			response = rt.Apply(operation.Context(), &runtime.ApplyRequest{
+				PriorResource:    prior,
+				PlanResource:     planed,
+				Stack:            operation.Stack,
+				ReadinessTimeout: operation.ReadinessTimeout,
+				Replace:          rn.Action == opsmodels.Replace,
+				ForceConflicts:   operation.ForceConflicts,
+				Progress:         rn.progress(operation),
+			})
+			deleted = deleted || response.Deleted
+			return response.Status
+		})
 		res = response.Resource
-		s = response.Status
 		log.Debugf("apply resource:%s, response: %v", planed.ID, jsonutil.Marshal2String(response))
+		if status.IsErr(s) && res == nil && operation.Interrupted() {
+			res, aborted = rn.readAborted(operation, prior, planed)
+		}
 	case opsmodels.Delete:
-		response := rt.Delete(context.Background(), &runtime.DeleteRequest{Resource: prior, Stack: operation.Stack})
-		s = response.Status
+		lifecycle, err := rn.resource.Lifecycle()
+		if err != nil {
+			return status.NewErrorStatusWithMsg(status.IllegalManifest, err.Error())
+		}
+		// only drop the resource from the State if it is abandoned
+		if lifecycle.Abandon() {
+			log.Infof("abandon resource:%s, the actual resource is kept", rn.ID)
+			break
+		}
+		s = rn.callWithRetry(operation, func() status.Status {
+			return rt.Delete(operation.Context(), &runtime.DeleteRequest{Resource: prior, Stack: operation.Stack}).Status
+		})
 		if s != nil {
 			log.Debugf("delete resource:%s, resource: %v", prior.ID, s.String())
 		}
@@ -240,8 +420,11 @@ func (rn *ResourceNode) applyResource(operation *opsmodels.Operation, prior, pla
 		log.Infof("planed resource and live resource are equal")
 		// auto import resources exist in spec and live cluster but no recorded in kusion_state.json
 		if prior == nil {
-			response := rt.Import(context.Background(), &runtime.ImportRequest{PlanResource: planed})
-			s = response.Status
+			var response *runtime.ImportResponse
+			s = rn.callWithRetry(operation, func() status.Status {
+				response = rt.Import(operation.Context(), &runtime.ImportRequest{PlanResource: planed, Stack: operation.Stack})
+				return response.Status
+			})
 			log.Debugf("import resource:%s, resource:%v", planed.ID, jsonutil.Marshal2String(s))
 			res = response.Resource
 		} else {
@@ -249,14 +432,17 @@ func (rn *ResourceNode) applyResource(operation *opsmodels.Operation, prior, pla
 		}
 	}
 	if status.IsErr(s) {
+		// the resource has been changed if it is returned with an error, such as a failed rollout, so still record it.
+		// It is recorded as deleted if it is deleted but failed to be created again when replacing it
+		if res != nil || aborted || deleted {
+			if e := rn.updateState(operation, res); e != nil {
+				log.Errorf("update state of failed resource %s failed: %v", rn.resource.ResourceKey(), e)
+			}
+		}
 		return s
 	}
 
-	key := rn.resource.ResourceKey()
-	if e := operation.RefreshResourceIndex(key, res, rn.Action); e != nil {
-		return status.NewErrorStatus(e)
-	}
-	if e := operation.UpdateState(operation.StateResourceIndex); e != nil {
+	if e := rn.updateState(operation, res); e != nil {
 		return status.NewErrorStatus(e)
 	}
 
@@ -265,6 +451,30 @@ func (rn *ResourceNode) applyResource(operation *opsmodels.Operation, prior, pla
 	return nil
 }
 
+// readAborted reads the live resource after applying it is aborted by an interruption, since the resource may have
+// been changed before the abort. It returns false if the live resource can't be read
+func (rn *ResourceNode) readAborted(operation *opsmodels.Operation, prior, planed *models.Resource) (*models.Resource, bool) {
+	// the context of the operation has been canceled, so read with a new one
+	response := operation.RuntimeMap[rn.resource.Type].Read(context.Background(), &runtime.ReadRequest{
+		PlanResource:  planed,
+		PriorResource: prior,
+		Stack:         operation.Stack,
+	})
+	if status.IsErr(response.Status) {
+		log.Errorf("read resource %s aborted by interruption failed: %v", rn.ID, response.Status)
+		return nil, false
+	}
+	return response.Resource, true
+}
+
+// updateState records the result of this node in the operation and saves the State
+func (rn *ResourceNode) updateState(operation *opsmodels.Operation, res *models.Resource) error {
+	if e := operation.RefreshResourceIndex(rn.resource.ResourceKey(), res, rn.Action); e != nil {
+		return e
+	}
+	return operation.UpdateState(operation.StateResourceIndex)
+}
+
 func (rn *ResourceNode) State() *models.Resource {
 	return rn.resource
 }
@@ -293,7 +503,12 @@ func updateChangeOrder(ops *opsmodels.Operation, rn *ResourceNode, plan, live in
 		order.ChangeSteps = make(map[string]*opsmodels.ChangeStep)
 	}
 	order.StepKeys = append(order.StepKeys, rn.ID)
-	order.ChangeSteps[rn.ID] = opsmodels.NewChangeStep(rn.ID, rn.Action, plan, live)
+	step := opsmodels.NewChangeStep(rn.ID, rn.Action, plan, live)
+	step.Conflicts = rn.conflicts
+	if lifecycle, err := rn.resource.Lifecycle(); err == nil && rn.Action == opsmodels.Delete && lifecycle.Abandon() {
+		step.Abandoned = true
+	}
+	order.ChangeSteps[rn.ID] = step
 }
 
 func ReplaceSecretRef(v reflect.Value, ss *vals.SecretStores) ([]string, reflect.Value, status.Status) {
@@ -442,3 +657,13 @@ func ReplaceRef(
 	}
 	return result, v, nil
 }
+
+// progress returns the callback sending the progress reported by the Runtime to MsgCh, or nil if there is no MsgCh
+func (rn *ResourceNode) progress(operation *opsmodels.Operation) func(string) {
+	if operation.MsgCh == nil {
+		return nil
+	}
+	return func(message string) {
+		operation.MsgCh <- opsmodels.Message{ResourceID: rn.ID, OpResult: opsmodels.Progress, Detail: message}
+	}
+}
@@ -2,8 +2,10 @@ package graph
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/bytedance/mockey"
 	"github.com/stretchr/testify/assert"
@@ -187,7 +189,7 @@ func TestResourceNode_Execute(t *testing.T) {
 	}
 }
 
-func Test_removeNestedField(t *testing.T) {
+func TestRemoveNestedField(t *testing.T) {
 	t.Run("remove nested field", func(t *testing.T) {
 		e1 := []interface{}{
 			map[string]interface{}{"f": "f1", "g": "g1"},
@@ -210,29 +212,29 @@ func Test_removeNestedField(t *testing.T) {
 			"a": a,
 		}
 
-		removeNestedField(obj, "a", "c", "e", "f")
+		RemoveNestedField(obj, "a", "c", "e", "f")
 		assert.Len(t, e1[0], 1)
 		assert.Len(t, e2[0], 1)
 
-		removeNestedField(obj, "a", "c", "e", "g")
+		RemoveNestedField(obj, "a", "c", "e", "g")
 		assert.Empty(t, e1[0])
 		assert.Empty(t, e2[0])
 
-		removeNestedField(obj, "a", "c", "e")
+		RemoveNestedField(obj, "a", "c", "e")
 		assert.Len(t, c[0], 1)
 		assert.Len(t, c[1], 1)
 
-		removeNestedField(obj, "a", "c", "d")
+		RemoveNestedField(obj, "a", "c", "d")
 		assert.Len(t, c[0], 0)
 		assert.Len(t, c[1], 0)
 
-		removeNestedField(obj, "a", "c")
+		RemoveNestedField(obj, "a", "c")
 		assert.Len(t, a, 1)
 
-		removeNestedField(obj, "a", "b")
+		RemoveNestedField(obj, "a", "b")
 		assert.Len(t, a, 0)
 
-		removeNestedField(obj, "a")
+		RemoveNestedField(obj, "a")
 		assert.Empty(t, obj)
 	})
 
@@ -254,7 +256,344 @@ func Test_removeNestedField(t *testing.T) {
 			"spec": spec,
 		}
 
-		removeNestedField(obj, "spec", "ports", "targetPort")
+		RemoveNestedField(obj, "spec", "ports", "targetPort")
 		assert.Len(t, ports[0], 2)
 	})
 }
+
+func TestWithNestedField(t *testing.T) {
+	dst := map[string]interface{}{
+		"spec":       map[string]interface{}{"replicas": 1, "paused": true},
+		"containers": []interface{}{map[string]interface{}{"image": "a"}, map[string]interface{}{"image": "b"}},
+	}
+	src := map[string]interface{}{
+		"spec":       map[string]interface{}{"replicas": 3},
+		"containers": []interface{}{map[string]interface{}{"image": "c"}},
+	}
+	var got interface{} = dst
+	for _, field := range []string{"spec.replicas", "spec.paused", "containers.image", "not.exist"} {
+		got = withNestedField(got, src, strings.Split(field, ".")...)
+	}
+	assert.Equal(t, map[string]interface{}{
+		"spec":       map[string]interface{}{"replicas": 3},
+		"containers": []interface{}{map[string]interface{}{"image": "c"}, map[string]interface{}{}},
+	}, got)
+	// dst is not changed
+	assert.Equal(t, map[string]interface{}{"replicas": 1, "paused": true}, dst["spec"])
+}
+
+func TestResourceNode_checkPlannedAction(t *testing.T) {
+	rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, Action: opsmodels.Update}
+	operation := &opsmodels.Operation{OperationType: opsmodels.Apply}
+	assert.Nil(t, rn.checkPlannedAction(operation), "not applying a plan")
+
+	operation.ChangeOrder = &opsmodels.ChangeOrder{
+		StepKeys:    []string{"jack"},
+		ChangeSteps: map[string]*opsmodels.ChangeStep{"jack": opsmodels.NewChangeStep("jack", opsmodels.Update, nil, nil)},
+	}
+	assert.Nil(t, rn.checkPlannedAction(operation))
+
+	rn.Action = opsmodels.Create
+	assert.True(t, status.IsErr(rn.checkPlannedAction(operation)), "action differs from the plan")
+
+	rn.baseNode.ID = "pony"
+	assert.True(t, status.IsErr(rn.checkPlannedAction(operation)), "resource is not in the plan")
+}
+
+func TestResourceNode_computeActionTypeReplace(t *testing.T) {
+	mockey.PatchConvey("requires replace", t, func() {
+		rt := &kubernetes.KubernetesRuntime{}
+		mockey.Mock(mockey.GetMethod(rt, "Apply")).To(
+			func(k *kubernetes.KubernetesRuntime, ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+				return &runtime.ApplyResponse{Resource: request.PlanResource, RequiresReplace: true}
+			}).Build()
+
+		res := &models.Resource{ID: "jack", Type: runtime.Kubernetes, Attributes: map[string]interface{}{"a": "b"}}
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, resource: res}
+		operation := &opsmodels.Operation{
+			OperationType: opsmodels.ApplyPreview,
+			RuntimeMap:    map[models.Type]runtime.Runtime{runtime.Kubernetes: rt},
+		}
+		_, s := rn.computeActionType(operation, res, res, res)
+		assert.Nil(t, s)
+		assert.Equal(t, opsmodels.Replace, rn.Action)
+	})
+}
+
+func TestResourceNode_Lifecycle(t *testing.T) {
+	lifecycle := func(l map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{models.LifecycleKey: l}
+	}
+
+	t.Run("prevent destroy", func(t *testing.T) {
+		res := &models.Resource{ID: "jack", Extensions: lifecycle(map[string]interface{}{"preventDestroy": true})}
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, Action: opsmodels.Update, resource: res}
+		assert.Nil(t, rn.checkPreventDestroy())
+		for _, action := range []opsmodels.ActionType{opsmodels.Delete, opsmodels.Replace} {
+			rn.Action = action
+			assert.True(t, status.IsErr(rn.checkPreventDestroy()))
+		}
+
+		// abandoned resources are never destroyed when deleted
+		res.Extensions = lifecycle(map[string]interface{}{"preventDestroy": true, "deletionPolicy": "Abandon"})
+		rn.Action = opsmodels.Delete
+		assert.Nil(t, rn.checkPreventDestroy())
+		rn.Action = opsmodels.Replace
+		assert.True(t, status.IsErr(rn.checkPreventDestroy()))
+	})
+
+	t.Run("abandon in preview", func(t *testing.T) {
+		res := &models.Resource{ID: "jack", Extensions: lifecycle(map[string]interface{}{"deletionPolicy": "Abandon"})}
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, Action: opsmodels.Delete, resource: res}
+		operation := &opsmodels.Operation{Lock: &sync.Mutex{}, ChangeOrder: &opsmodels.ChangeOrder{}}
+		updateChangeOrder(operation, rn, nil, res)
+		assert.True(t, operation.ChangeOrder.Get("jack").Abandoned)
+		assert.Equal(t, "Abandon", operation.ChangeOrder.Get("jack").ActionString())
+	})
+
+	mockey.PatchConvey("ignore changes", t, func() {
+		rt := &kubernetes.KubernetesRuntime{}
+		mockey.Mock(mockey.GetMethod(rt, "Apply")).To(
+			func(k *kubernetes.KubernetesRuntime, ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+				return &runtime.ApplyResponse{Resource: request.PlanResource.DeepCopy()}
+			}).Build()
+
+		planed := &models.Resource{
+			ID:         "jack",
+			Type:       runtime.Kubernetes,
+			Attributes: map[string]interface{}{"spec": map[string]interface{}{"replicas": 1}},
+			Extensions: lifecycle(map[string]interface{}{"ignoreChanges": []interface{}{"spec.replicas"}}),
+		}
+		live := &models.Resource{
+			ID:         "jack",
+			Type:       runtime.Kubernetes,
+			Attributes: map[string]interface{}{"spec": map[string]interface{}{"replicas": 3}},
+			Extensions: planed.Extensions,
+		}
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, resource: planed}
+		operation := &opsmodels.Operation{
+			OperationType: opsmodels.ApplyPreview,
+			RuntimeMap:    map[models.Type]runtime.Runtime{runtime.Kubernetes: rt},
+		}
+		_, s := rn.computeActionType(operation, planed, planed, live)
+		assert.Nil(t, s)
+		assert.Equal(t, opsmodels.UnChanged, rn.Action)
+	})
+
+	mockey.PatchConvey("ignore changes are not applied", t, func() {
+		planed := &models.Resource{
+			ID:   "jack",
+			Type: runtime.Kubernetes,
+			Attributes: map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": 1, "image": "nginx:1.25"},
+			},
+			Extensions: lifecycle(map[string]interface{}{"ignoreChanges": []interface{}{"spec.replicas"}}),
+		}
+		live := &models.Resource{
+			ID:         "jack",
+			Type:       runtime.Kubernetes,
+			Attributes: map[string]interface{}{"spec": map[string]interface{}{"replicas": 3, "image": "nginx:1.24"}},
+			Extensions: planed.Extensions,
+		}
+
+		rt := &kubernetes.KubernetesRuntime{}
+		var applied *models.Resource
+		mockey.Mock(mockey.GetMethod(rt, "Apply")).To(
+			func(k *kubernetes.KubernetesRuntime, ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+				if !request.DryRun {
+					applied = request.PlanResource
+				}
+				return &runtime.ApplyResponse{Resource: request.PlanResource.DeepCopy()}
+			}).Build()
+		mockey.Mock(mockey.GetMethod(rt, "Read")).Return(&runtime.ReadResponse{Resource: live}).Build()
+		storage := local.NewFileSystemState()
+		mockey.Mock(mockey.GetMethod(storage, "Apply")).Return(nil).Build()
+
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, Action: opsmodels.Update, resource: planed}
+		operation := &opsmodels.Operation{
+			OperationType:           opsmodels.Apply,
+			StateStorage:            storage,
+			CtxResourceIndex:        map[string]*models.Resource{},
+			PriorStateResourceIndex: map[string]*models.Resource{"jack": live},
+			StateResourceIndex:      map[string]*models.Resource{},
+			RuntimeMap:              map[models.Type]runtime.Runtime{runtime.Kubernetes: rt},
+			ResultState:             states.NewState(),
+			Lock:                    &sync.Mutex{},
+		}
+		assert.Nil(t, rn.Execute(operation))
+		assert.Equal(t, opsmodels.Update, rn.Action)
+		assert.Equal(t, map[string]interface{}{"replicas": 3, "image": "nginx:1.25"}, applied.Attributes["spec"])
+		// the planed resource in the Spec is not changed
+		assert.Equal(t, 1, planed.Attributes["spec"].(map[string]interface{})["replicas"])
+	})
+
+	mockey.PatchConvey("ignore changes without the live resource", t, func() {
+		rt := &kubernetes.KubernetesRuntime{}
+		mockey.Mock(mockey.GetMethod(rt, "Apply")).To(
+			func(k *kubernetes.KubernetesRuntime, ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+				return &runtime.ApplyResponse{Resource: request.PlanResource.DeepCopy()}
+			}).Build()
+
+		planed := &models.Resource{
+			ID:         "jack",
+			Type:       runtime.Kubernetes,
+			Attributes: map[string]interface{}{"spec": map[string]interface{}{"replicas": 1}},
+			Extensions: lifecycle(map[string]interface{}{"ignoreChanges": []interface{}{"spec.replicas"}}),
+		}
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, resource: planed}
+		operation := &opsmodels.Operation{
+			OperationType: opsmodels.ApplyPreview,
+			RuntimeMap:    map[models.Type]runtime.Runtime{runtime.Kubernetes: rt},
+		}
+		// the resource recorded in the State has been deleted out of band
+		_, s := rn.computeActionType(operation, planed, planed, nil)
+		assert.Nil(t, s)
+		assert.Equal(t, opsmodels.Update, rn.Action)
+	})
+
+	mockey.PatchConvey("abandon", t, func() {
+		rt := &kubernetes.KubernetesRuntime{}
+		deleted := false
+		mockey.Mock(mockey.GetMethod(rt, "Delete")).To(
+			func(k *kubernetes.KubernetesRuntime, ctx context.Context, request *runtime.DeleteRequest) *runtime.DeleteResponse {
+				deleted = true
+				return &runtime.DeleteResponse{}
+			}).Build()
+		storage := local.NewFileSystemState()
+		mockey.Mock(mockey.GetMethod(storage, "Apply")).Return(nil).Build()
+
+		prior := &models.Resource{
+			ID:         "jack",
+			Type:       runtime.Kubernetes,
+			Extensions: lifecycle(map[string]interface{}{"deletionPolicy": "Abandon"}),
+		}
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, Action: opsmodels.Delete, resource: prior}
+		operation := &opsmodels.Operation{
+			OperationType:      opsmodels.Apply,
+			StateStorage:       storage,
+			CtxResourceIndex:   map[string]*models.Resource{},
+			StateResourceIndex: map[string]*models.Resource{"jack": prior},
+			RuntimeMap:         map[models.Type]runtime.Runtime{runtime.Kubernetes: rt},
+			ResultState:        states.NewState(),
+			Lock:               &sync.Mutex{},
+		}
+		assert.Nil(t, rn.applyResource(operation, prior, nil, prior))
+		assert.False(t, deleted)
+		assert.Nil(t, operation.StateResourceIndex["jack"])
+	})
+}
+
+func TestResourceNode_applyResourceInterrupted(t *testing.T) {
+	mockey.PatchConvey("read the resource aborted by an interruption", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		rt := &kubernetes.KubernetesRuntime{}
+		mockey.Mock(mockey.GetMethod(rt, "Apply")).To(
+			func(k *kubernetes.KubernetesRuntime, ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+				cancel()
+				return &runtime.ApplyResponse{Status: status.NewErrorStatus(ctx.Err())}
+			}).Build()
+		mockey.Mock(mockey.GetMethod(rt, "Read")).To(
+			func(k *kubernetes.KubernetesRuntime, readCtx context.Context, request *runtime.ReadRequest) *runtime.ReadResponse {
+				assert.Nil(t, readCtx.Err())
+				return &runtime.ReadResponse{Resource: request.PlanResource}
+			}).Build()
+		storage := local.NewFileSystemState()
+		mockey.Mock(mockey.GetMethod(storage, "Apply")).Return(nil).Build()
+
+		planed := &models.Resource{ID: "jack", Type: runtime.Kubernetes}
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, Action: opsmodels.Create, resource: planed}
+		operation := &opsmodels.Operation{
+			OperationType:      opsmodels.Apply,
+			StateStorage:       storage,
+			CtxResourceIndex:   map[string]*models.Resource{},
+			StateResourceIndex: map[string]*models.Resource{},
+			RuntimeMap:         map[models.Type]runtime.Runtime{runtime.Kubernetes: rt},
+			ResultState:        states.NewState(),
+			Lock:               &sync.Mutex{},
+			Ctx:                ctx,
+		}
+		assert.True(t, status.IsErr(rn.applyResource(operation, nil, planed, nil)))
+		assert.Equal(t, planed, operation.StateResourceIndex["jack"])
+	})
+}
+
+func TestResourceNode_applyResourceReplaceFailed(t *testing.T) {
+	mockey.PatchConvey("record the resource deleted by a failed replacement", t, func() {
+		rt := &kubernetes.KubernetesRuntime{}
+		mockey.Mock(mockey.GetMethod(rt, "Apply")).To(
+			func(k *kubernetes.KubernetesRuntime, ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+				assert.True(t, request.Replace)
+				return &runtime.ApplyResponse{Deleted: true, Status: status.NewErrorStatusWithMsg(status.Unknown, "create failed")}
+			}).Build()
+		storage := local.NewFileSystemState()
+		mockey.Mock(mockey.GetMethod(storage, "Apply")).Return(nil).Build()
+
+		prior := &models.Resource{ID: "jack", Type: runtime.Kubernetes}
+		rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}, Action: opsmodels.Replace, resource: prior}
+		operation := &opsmodels.Operation{
+			OperationType:      opsmodels.Apply,
+			StateStorage:       storage,
+			CtxResourceIndex:   map[string]*models.Resource{"jack": prior},
+			StateResourceIndex: map[string]*models.Resource{"jack": prior},
+			RuntimeMap:         map[models.Type]runtime.Runtime{runtime.Kubernetes: rt},
+			ResultState:        states.NewState(),
+			Lock:               &sync.Mutex{},
+			Ctx:                context.Background(),
+		}
+		assert.True(t, status.IsErr(rn.applyResource(operation, prior, prior, prior)))
+		res, ok := operation.StateResourceIndex["jack"]
+		assert.True(t, ok)
+		assert.Nil(t, res)
+	})
+}
+
+func TestResourceNode_callWithRetry(t *testing.T) {
+	newOperation := func() *opsmodels.Operation {
+		return &opsmodels.Operation{
+			MsgCh:       make(chan opsmodels.Message, 5),
+			RetryPolicy: &opsmodels.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		}
+	}
+	rn := &ResourceNode{baseNode: &baseNode{ID: "jack"}}
+
+	t.Run("succeed after retries", func(t *testing.T) {
+		operation := newOperation()
+		calls := 0
+		s := rn.callWithRetry(operation, func() status.Status {
+			calls++
+			if calls < 3 {
+				return status.NewErrorStatusWithMsg(status.Throttled, "too many requests")
+			}
+			return nil
+		})
+		assert.Nil(t, s)
+		assert.Equal(t, 3, calls)
+		close(operation.MsgCh)
+		var retries []string
+		for msg := range operation.MsgCh {
+			assert.Equal(t, opsmodels.Retry, msg.OpResult)
+			retries = append(retries, msg.OpErr.Error())
+		}
+		assert.Equal(t, []string{"retry 1/2 in 1ms: too many requests", "retry 2/2 in 1ms: too many requests"}, retries)
+	})
+
+	t.Run("give up", func(t *testing.T) {
+		calls := 0
+		s := rn.callWithRetry(newOperation(), func() status.Status {
+			calls++
+			return status.NewErrorStatusWithMsg(status.Unavailable, "service unavailable")
+		})
+		assert.True(t, status.IsErr(s))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("not transient", func(t *testing.T) {
+		calls := 0
+		s := rn.callWithRetry(newOperation(), func() status.Status {
+			calls++
+			return status.NewErrorStatusWithMsg(status.IllegalManifest, "invalid object")
+		})
+		assert.True(t, status.IsErr(s))
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+// callWithRetry invokes the Runtime call, and retries it with backoff according to the RetryPolicy of the operation
+// if it fails with a transient error. Each retry is sent to MsgCh to show in the progress of the operation
+func (rn *ResourceNode) callWithRetry(operation *opsmodels.Operation, call func() status.Status) status.Status {
+	policy := operation.RetryPolicy
+	for retry := 1; ; retry++ {
+		s := call()
+		if policy == nil || !policy.Retryable(s) || retry > policy.MaxRetries {
+			return s
+		}
+
+		backoff := policy.Backoff(retry)
+		log.Infof("resource %s failed with a transient error, retry %d/%d in %s: %s",
+			rn.ID, retry, policy.MaxRetries, backoff, s.Message())
+		if operation.MsgCh != nil {
+			operation.MsgCh <- opsmodels.Message{
+				ResourceID: rn.ID,
+				OpResult:   opsmodels.Retry,
+				OpErr:      fmt.Errorf("retry %d/%d in %s: %s", retry, policy.MaxRetries, backoff, s.Message()),
+			}
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-operation.Context().Done():
+			timer.Stop()
+			return s
+		}
+	}
+}
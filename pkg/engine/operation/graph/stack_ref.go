@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+const (
+	// StackRefPrefix is the prefix of references to other stacks. A stack ref like
+	// "$kusion_stack.project/stack.resourceKey.attribute" is replaced with the attribute of a resource, and
+	// "$kusion_stack.project/stack.outputs.name" is replaced with an output in the latest State of that stack
+	StackRefPrefix = "$kusion_stack."
+
+	// StackRefOutputs is the keyword to reference outputs of the stack instead of a resource
+	StackRefOutputs = "outputs"
+)
+
+// ReplaceStackRef replaces all stack refs in v with values read from the latest States of referenced stacks through
+// the StateStorage of the operation. An error is returned if the State, resource or attribute referenced is not found
+func ReplaceStackRef(v reflect.Value, o *opsmodels.Operation) (reflect.Value, status.Status) {
+	if !v.IsValid() {
+		return v, status.NewErrorStatusWithMsg(status.InvalidArgument, "invalid stack reference")
+	}
+
+	switch v.Type().Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		return ReplaceStackRef(v.Elem(), o)
+	case reflect.String:
+		vStr := v.String()
+		if strings.HasPrefix(vStr, StackRefPrefix) {
+			log.Infof("replace stack ref:%s", vStr)
+			return stackRefValue(o, strings.TrimPrefix(vStr, StackRefPrefix))
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return v, nil
+		}
+		vs := reflect.MakeSlice(v.Type(), 0, 0)
+		for i := 0; i < v.Len(); i++ {
+			tv, s := ReplaceStackRef(v.Index(i), o)
+			if status.IsErr(s) {
+				return tv, s
+			}
+			vs = reflect.Append(vs, tv)
+		}
+		v = vs
+	case reflect.Map:
+		if v.Len() == 0 {
+			return v, nil
+		}
+		makeMap := reflect.MakeMap(v.Type())
+		iter := v.MapRange()
+		for iter.Next() {
+			tv, s := ReplaceStackRef(iter.Value(), o)
+			if status.IsErr(s) {
+				return tv, s
+			}
+			makeMap.SetMapIndex(iter.Key(), tv)
+		}
+		v = makeMap
+	}
+	return v, nil
+}
+
+// stackRefValue returns the value referenced by a stack ref without the StackRefPrefix
+func stackRefValue(o *opsmodels.Operation, ref string) (reflect.Value, status.Status) {
+	stackPath, path, _ := strings.Cut(ref, ".")
+	project, stack, _ := strings.Cut(stackPath, "/")
+	if project == "" || stack == "" || path == "" {
+		msg := fmt.Sprintf("illegal stack ref:%s. Stack ref format: %sproject/stack.resourceKey.attribute or %sproject/stack.%s.name",
+			ref, StackRefPrefix, StackRefPrefix, StackRefOutputs)
+		return reflect.Value{}, status.NewErrorStatusWithMsg(status.IllegalManifest, msg)
+	}
+
+	state, err := o.LatestStackState(project, stack)
+	if err != nil {
+		return reflect.Value{}, status.NewErrorStatus(err)
+	}
+	if state == nil {
+		msg := fmt.Sprintf("can't find the State of stack %s when replacing %s%s. Please apply stack %s first",
+			stackPath, StackRefPrefix, ref, stackPath)
+		return reflect.Value{}, status.NewErrorStatusWithMsg(status.IllegalManifest, msg)
+	}
+
+	split := strings.Split(path, ".")
+	var value interface{}
+	if split[0] == StackRefOutputs {
+		value = state.Outputs
+	} else {
+		resource := state.Resources.Index()[split[0]]
+		if resource == nil {
+			msg := fmt.Sprintf("can't find resource:%s in the State of stack %s when replacing %s%s",
+				split[0], stackPath, StackRefPrefix, ref)
+			return reflect.Value{}, status.NewErrorStatusWithMsg(status.IllegalManifest, msg)
+		}
+		value = resource.Attributes
+	}
+	for i, k := range split[1:] {
+		m, ok := value.(map[string]interface{})
+		if !ok || m[k] == nil {
+			msg := fmt.Sprintf("can't find %s in the State of stack %s when replacing %s%s",
+				strings.Join(split[:i+2], "."), stackPath, StackRefPrefix, ref)
+			return reflect.Value{}, status.NewErrorStatusWithMsg(status.IllegalManifest, msg)
+		}
+		value = m[k]
+	}
+	if value == nil {
+		msg := fmt.Sprintf("can't find %s in the State of stack %s when replacing %s%s", path, stackPath, StackRefPrefix, ref)
+		return reflect.Value{}, status.NewErrorStatusWithMsg(status.IllegalManifest, msg)
+	}
+	return reflect.ValueOf(value), nil
+}
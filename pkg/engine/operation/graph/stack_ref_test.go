@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/engine/states/remote/http"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+func TestReplaceStackRef(t *testing.T) {
+	networkState := &states.State{
+		Project: "network",
+		Stack:   "prod",
+		Cluster: "east",
+		Resources: models.Resources{
+			{
+				ID:         "v1:Service:default:gateway",
+				Type:       "Kubernetes",
+				Attributes: map[string]interface{}{"spec": map[string]interface{}{"clusterIP": "10.0.0.1"}},
+			},
+		},
+		Outputs: map[string]interface{}{"vpcID": "vpc-123"},
+	}
+	tests := map[string]struct {
+		attributes map[string]interface{}
+		want       map[string]interface{}
+		wantErr    string
+	}{
+		"resource attribute": {
+			attributes: map[string]interface{}{"ip": []interface{}{"$kusion_stack.network/prod.v1:Service:default:gateway.spec.clusterIP"}},
+			want:       map[string]interface{}{"ip": []interface{}{"10.0.0.1"}},
+		},
+		"output": {
+			attributes: map[string]interface{}{"vpc": "$kusion_stack.network/prod.outputs.vpcID", "name": "app"},
+			want:       map[string]interface{}{"vpc": "vpc-123", "name": "app"},
+		},
+		"missing state": {
+			attributes: map[string]interface{}{"vpc": "$kusion_stack.network/dev.outputs.vpcID"},
+			wantErr:    "can't find the State of stack network/dev",
+		},
+		"missing resource": {
+			attributes: map[string]interface{}{"ip": "$kusion_stack.network/prod.v1:Service:default:other.spec.clusterIP"},
+			wantErr:    "can't find resource:v1:Service:default:other in the State of stack network/prod",
+		},
+		"missing attribute": {
+			attributes: map[string]interface{}{"ip": "$kusion_stack.network/prod.v1:Service:default:gateway.spec.ports"},
+			wantErr:    "can't find v1:Service:default:gateway.spec.ports in the State of stack network/prod",
+		},
+		"missing output": {
+			attributes: map[string]interface{}{"vpc": "$kusion_stack.network/prod.outputs.subnetID"},
+			wantErr:    "can't find outputs.subnetID in the State of stack network/prod",
+		},
+		"illegal ref": {
+			attributes: map[string]interface{}{"vpc": "$kusion_stack.network.outputs.vpcID"},
+			wantErr:    "illegal stack ref",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockey.PatchConvey(name, t, func() {
+				queried := 0
+				mockey.Mock((*http.HTTPState).GetLatestState).To(
+					func(f *http.HTTPState, query *states.StateQuery) (*states.State, error) {
+						queried++
+						// States of other stacks are located in the cluster of this operation
+						if query.Project == networkState.Project && query.Stack == networkState.Stack &&
+							query.Cluster == networkState.Cluster {
+							return networkState, nil
+						}
+						return nil, nil
+					}).Build()
+
+				o := &opsmodels.Operation{
+					StateStorage: &http.HTTPState{},
+					ResultState:  &states.State{Project: "app", Stack: "prod", Cluster: "east"},
+					Lock:         &sync.Mutex{},
+				}
+				got, s := ReplaceStackRef(reflect.ValueOf(tc.attributes), o)
+				if tc.wantErr != "" {
+					assert.True(t, status.IsErr(s))
+					assert.Contains(t, s.Message(), tc.wantErr)
+					return
+				}
+				assert.Nil(t, s)
+				assert.Equal(t, tc.want, got.Interface())
+
+				// the State is cached and read only once
+				_, s = ReplaceStackRef(reflect.ValueOf(tc.attributes), o)
+				assert.Nil(t, s)
+				assert.Equal(t, 1, queried)
+			})
+		})
+	}
+}
+
+func TestReplaceStackRef_LocalBackend(t *testing.T) {
+	// the state file of the local backend only keeps the State of this stack
+	stateStorage := &local.FileSystemState{Path: filepath.Join(t.TempDir(), local.KusionState)}
+	assert.NoError(t, stateStorage.Apply(&states.State{
+		Project:   "network",
+		Stack:     "dev",
+		Resources: models.Resources{{ID: "v1:Service:default:gateway", Type: "Kubernetes"}},
+		Outputs:   map[string]interface{}{"vpcID": "vpc-dev"},
+	}))
+
+	o := &opsmodels.Operation{StateStorage: stateStorage, Lock: &sync.Mutex{}}
+	_, s := ReplaceStackRef(reflect.ValueOf("$kusion_stack.network/prod.outputs.vpcID"), o)
+	assert.True(t, status.IsErr(s))
+	assert.Contains(t, s.Message(), "can't get the State of stack network/prod: the local backend only keeps the State of stack network/dev")
+	assert.Contains(t, s.Message(), "Please configure a remote backend to reference other stacks")
+}
@@ -0,0 +1,216 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	runtimeinit "kusionstack.io/kusion/pkg/engine/runtime/init"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+	jsonutil "kusionstack.io/kusion/pkg/util/json"
+	"kusionstack.io/kusion/pkg/vals"
+)
+
+type ImportOperation struct {
+	opsmodels.Operation
+}
+
+type ImportRequest struct {
+	opsmodels.Request `json:",inline" yaml:",inline"`
+
+	// ImportIDs maps the ID of a resource in the Spec to its ID in the actual infrastructure
+	ImportIDs map[string]string `json:"importIDs" yaml:"importIDs"`
+}
+
+type ImportResponse struct {
+	State *states.State
+
+	// Resources are the imported resources recorded in the State
+	Resources []*models.Resource
+}
+
+// Import adopts resources which already exist in the actual infrastructure into the State without changing them.
+// Each resource to import must be declared in the Spec, so the runtime knows how to locate it, and must not be
+// managed in the State yet. All resources are imported one by one, and the State is saved only if all of them
+// are imported successfully.
+func (imp *ImportOperation) Import(request *ImportRequest) (rsp *ImportResponse, st status.Status) {
+	log.Infof("engine: Import start!")
+	o := imp.Operation
+
+	defer func() {
+		if e := recover(); e != nil {
+			log.Error("import panic:%v", e)
+
+			switch x := e.(type) {
+			case string:
+				st = status.NewErrorStatus(fmt.Errorf("import panic:%s", e))
+			case error:
+				st = status.NewErrorStatus(x)
+			default:
+				st = status.NewErrorStatusWithCode(status.Unknown, errors.New("unknown panic"))
+			}
+		}
+	}()
+
+	if st = validateRequest(&request.Request); status.IsErr(st) {
+		return nil, st
+	}
+	if len(request.ImportIDs) == 0 {
+		return nil, status.NewErrorStatusWithMsg(status.InvalidArgument, "no resource to import")
+	}
+
+	// 0. lock the State to prevent concurrent operations on the same stack
+	unlock, st := lockState(o.StateStorage, &request.Request, opsmodels.Import)
+	if status.IsErr(st) {
+		return nil, st
+	}
+	defer unlock()
+
+	// 1. init & build Indexes
+	priorState, resultState := o.InitStates(&request.Request)
+	priorStateResourceIndex := priorState.Resources.Index()
+	stateResourceIndex := map[string]*models.Resource{}
+	for k, v := range priorStateResourceIndex {
+		stateResourceIndex[k] = v
+	}
+
+	planResourceIndex := request.Spec.Resources.Index()
+	keys := make([]string, 0, len(request.ImportIDs))
+	for key := range request.ImportIDs {
+		if planResourceIndex[key] == nil {
+			return nil, status.NewErrorStatusWithMsg(status.InvalidArgument,
+				fmt.Sprintf("can not find resource %s in the Spec. Please declare it before importing", key))
+		}
+		if priorStateResourceIndex[key] != nil {
+			return nil, status.NewErrorStatusWithMsg(status.InvalidArgument,
+				fmt.Sprintf("resource %s is already managed in the State", key))
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	planResources := make(models.Resources, 0, len(keys))
+	for _, key := range keys {
+		planResources = append(planResources, *planResourceIndex[key])
+	}
+	runtimesMap, s := runtimeinit.Runtimes(planResources, request.Project)
+	if status.IsErr(s) {
+		return nil, s
+	}
+
+	importOperation := &ImportOperation{
+		Operation: opsmodels.Operation{
+			OperationType:           opsmodels.Import,
+			StateStorage:            o.StateStorage,
+			CtxResourceIndex:        map[string]*models.Resource{},
+			PriorStateResourceIndex: priorStateResourceIndex,
+			StateResourceIndex:      stateResourceIndex,
+			RuntimeMap:              runtimesMap,
+			Stack:                   o.Stack,
+			ResultState:             resultState,
+			Lock:                    &sync.Mutex{},
+			SecretStores:            o.SecretStores,
+		},
+	}
+
+	// 2. import resources one by one
+	imported := make([]*models.Resource, 0, len(planResources))
+	for i := range planResources {
+		res, s := importOperation.importResource(&planResources[i], request.ImportIDs[planResources[i].ResourceKey()])
+		if status.IsErr(s) {
+			return nil, s
+		}
+		imported = append(imported, res)
+		stateResourceIndex[res.ResourceKey()] = res
+	}
+
+	// 3. save the State with all imported resources
+	if err := importOperation.UpdateState(stateResourceIndex); err != nil {
+		return nil, status.NewErrorStatus(err)
+	}
+
+	return &ImportResponse{State: resultState, Resources: imported}, nil
+}
+
+// ImportableResources returns Kubernetes resources declared in the Spec which are not managed in the State yet but
+// already exist in the cluster. Kubernetes resources can be located by their IDs in the Spec, so they can be
+// imported without specifying IDs in the actual infrastructure. Nothing is changed in the State or the cluster.
+func (imp *ImportOperation) ImportableResources(request *opsmodels.Request) (rsp []*models.Resource, st status.Status) {
+	o := imp.Operation
+
+	defer func() {
+		if e := recover(); e != nil {
+			log.Error("detect importable resources panic:%v", e)
+			st = status.NewErrorStatus(fmt.Errorf("detect importable resources panic:%v", e))
+		}
+	}()
+
+	if st = validateRequest(request); status.IsErr(st) {
+		return nil, st
+	}
+
+	priorState, _ := o.InitStates(request)
+	priorStateResourceIndex := priorState.Resources.Index()
+
+	var candidates models.Resources
+	for _, res := range request.Spec.Resources {
+		if res.Type == runtime.Kubernetes && priorStateResourceIndex[res.ResourceKey()] == nil {
+			candidates = append(candidates, res)
+		}
+	}
+	runtimesMap, s := runtimeinit.Runtimes(candidates, request.Project)
+	if status.IsErr(s) {
+		return nil, s
+	}
+
+	var importable []*models.Resource
+	for i := range candidates {
+		response := runtimesMap[candidates[i].Type].Read(o.Context(), &runtime.ReadRequest{
+			PlanResource: &candidates[i],
+			Stack:        o.Stack,
+		})
+		if status.IsErr(response.Status) {
+			return nil, response.Status
+		}
+		if response.Resource != nil {
+			importable = append(importable, &candidates[i])
+		}
+	}
+	return importable, nil
+}
+
+func (imp *ImportOperation) importResource(plan *models.Resource, id string) (*models.Resource, status.Status) {
+	o := &imp.Operation
+
+	// replace secret refs and implicit refs with resources already in the State
+	_, replaced, s := graph.ReplaceRef(reflect.ValueOf(plan.Attributes), o.PriorStateResourceIndex,
+		graph.OptionalImplicitReplaceFun, o.SecretStores, vals.ParseSecretRef)
+	if status.IsErr(s) {
+		return nil, s
+	}
+	if !replaced.IsZero() {
+		plan.Attributes = replaced.Interface().(map[string]interface{})
+	}
+
+	response := o.RuntimeMap[plan.Type].Import(o.Context(), &runtime.ImportRequest{
+		PlanResource: plan,
+		Stack:        o.Stack,
+		ID:           id,
+	})
+	if response == nil {
+		return nil, status.NewErrorStatusWithMsg(status.Unknown, fmt.Sprintf("import resource %s failed with an empty response", plan.ResourceKey()))
+	}
+	if status.IsErr(response.Status) {
+		return nil, response.Status
+	}
+	log.Infof("import resource:%s, resource:%v", plan.ResourceKey(), jsonutil.Marshal2String(response.Resource))
+	return response.Resource, nil
+}
@@ -0,0 +1,163 @@
+//go:build !arm64
+// +build !arm64
+
+package operation
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	runtimeinit "kusionstack.io/kusion/pkg/engine/runtime/init"
+	"kusionstack.io/kusion/pkg/engine/runtime/kubernetes"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+func TestImportOperation_Import(t *testing.T) {
+	const Jack = "jack"
+	const Pony = "pony"
+	mf := &models.Spec{Resources: []models.Resource{
+		{
+			ID:         Jack,
+			Type:       runtime.Kubernetes,
+			Attributes: map[string]interface{}{"a": "b"},
+		},
+		{
+			ID:         Pony,
+			Type:       runtime.Kubernetes,
+			Attributes: map[string]interface{}{"c": "d"},
+		},
+	}}
+
+	stack := &projectstack.Stack{
+		StackConfiguration: projectstack.StackConfiguration{Name: "fakeStack"},
+		Path:               "fakePath",
+	}
+	project := &projectstack.Project{
+		ProjectConfiguration: projectstack.ProjectConfiguration{
+			Name:   "fakeProject",
+			Tenant: "fakeTenant",
+		},
+		Path:   "fakePath",
+		Stacks: []*projectstack.Stack{stack},
+	}
+	newRequest := func(importIDs map[string]string) *ImportRequest {
+		return &ImportRequest{
+			Request: opsmodels.Request{
+				Tenant:   "fakeTenant",
+				Stack:    stack,
+				Project:  project,
+				Operator: "faker",
+				Spec:     mf,
+			},
+			ImportIDs: importIDs,
+		}
+	}
+
+	mockey.PatchConvey("import test", t, func() {
+		storage := &local.FileSystemState{Path: filepath.Join(t.TempDir(), local.KusionState)}
+		imp := &ImportOperation{Operation: opsmodels.Operation{StateStorage: storage, Stack: stack}}
+
+		mockey.Mock(runtimeinit.Runtimes).To(func(resources models.Resources, project *projectstack.Project) (map[models.Type]runtime.Runtime, status.Status) {
+			return map[models.Type]runtime.Runtime{runtime.Kubernetes: &kubernetes.KubernetesRuntime{}}, nil
+		}).Build()
+		mockey.Mock((*kubernetes.KubernetesRuntime).Import).To(func(
+			k *kubernetes.KubernetesRuntime,
+			ctx context.Context,
+			request *runtime.ImportRequest,
+		) *runtime.ImportResponse {
+			res := request.PlanResource.DeepCopy()
+			res.Attributes["id"] = request.ID
+			return &runtime.ImportResponse{Resource: res}
+		}).Build()
+
+		// resource not in the Spec
+		_, st := imp.Import(newRequest(map[string]string{"not-exist": "fake-id"}))
+		assert.True(t, status.IsErr(st))
+
+		rsp, st := imp.Import(newRequest(map[string]string{Jack: "jack-id"}))
+		assert.Nil(t, st)
+		assert.Len(t, rsp.Resources, 1)
+		assert.Equal(t, "jack-id", rsp.Resources[0].Attributes["id"])
+
+		state, err := storage.GetLatestState(&states.StateQuery{})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), state.Serial)
+		assert.Len(t, state.Resources, 1)
+
+		// resource already in the State
+		_, st = imp.Import(newRequest(map[string]string{Jack: "jack-id"}))
+		assert.True(t, status.IsErr(st))
+
+		_, st = imp.Import(newRequest(map[string]string{Pony: "pony-id"}))
+		assert.Nil(t, st)
+		state, err = storage.GetLatestState(&states.StateQuery{})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), state.Serial)
+		assert.Len(t, state.Resources, 2)
+	})
+}
+
+func TestImportOperation_ImportableResources(t *testing.T) {
+	stack := &projectstack.Stack{
+		StackConfiguration: projectstack.StackConfiguration{Name: "fakeStack"},
+		Path:               "fakePath",
+	}
+	project := &projectstack.Project{
+		ProjectConfiguration: projectstack.ProjectConfiguration{
+			Name:   "fakeProject",
+			Tenant: "fakeTenant",
+		},
+		Path:   "fakePath",
+		Stacks: []*projectstack.Stack{stack},
+	}
+	mf := &models.Spec{Resources: []models.Resource{
+		{ID: "v1:Namespace:managed", Type: runtime.Kubernetes},
+		{ID: "v1:Namespace:existing", Type: runtime.Kubernetes},
+		{ID: "v1:Namespace:not-exist", Type: runtime.Kubernetes},
+		{ID: "hashicorp:local:local_file:kusion_example", Type: runtime.Terraform},
+	}}
+
+	mockey.PatchConvey("importable resources", t, func() {
+		storage := &local.FileSystemState{Path: filepath.Join(t.TempDir(), local.KusionState)}
+		assert.NoError(t, storage.Apply(&states.State{Resources: models.Resources{mf.Resources[0]}}))
+		imp := &ImportOperation{Operation: opsmodels.Operation{StateStorage: storage, Stack: stack}}
+
+		mockey.Mock(runtimeinit.Runtimes).To(func(resources models.Resources, project *projectstack.Project) (map[models.Type]runtime.Runtime, status.Status) {
+			for _, res := range resources {
+				assert.Equal(t, runtime.Kubernetes, res.Type)
+			}
+			return map[models.Type]runtime.Runtime{runtime.Kubernetes: &kubernetes.KubernetesRuntime{}}, nil
+		}).Build()
+		mockey.Mock((*kubernetes.KubernetesRuntime).Read).To(func(
+			k *kubernetes.KubernetesRuntime,
+			ctx context.Context,
+			request *runtime.ReadRequest,
+		) *runtime.ReadResponse {
+			assert.NotEqual(t, "v1:Namespace:managed", request.PlanResource.ID)
+			if request.PlanResource.ID == "v1:Namespace:existing" {
+				return &runtime.ReadResponse{Resource: request.PlanResource}
+			}
+			return &runtime.ReadResponse{}
+		}).Build()
+
+		resources, st := imp.ImportableResources(&opsmodels.Request{
+			Tenant:  "fakeTenant",
+			Stack:   stack,
+			Project: project,
+			Spec:    mf,
+		})
+		assert.Nil(t, st)
+		assert.Len(t, resources, 1)
+		assert.Equal(t, "v1:Namespace:existing", resources[0].ID)
+	})
+}
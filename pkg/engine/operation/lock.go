@@ -0,0 +1,57 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pterm/pterm"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+// LockState acquires the lock of the State located by the query before the operation modifies it, and returns a
+// function to release the lock when the operation is finished together with the lock info. A *states.LockedError
+// is returned if the State is locked by others. If the backend doesn't support locking, a warning is printed and
+// the operation goes on without the lock
+func LockState(storage states.StateStorage, query *states.StateQuery, operation, operator string) (func(), *states.LockInfo, error) {
+	info := states.NewLockInfo(operation, operator)
+	if err := storage.Lock(query, info); err != nil {
+		var lockedErr *states.LockedError
+		if errors.As(err, &lockedErr) {
+			return nil, nil, err
+		}
+		var unsupportedErr *states.LockUnsupportedError
+		if errors.As(err, &unsupportedErr) {
+			// keep going without the lock, but make sure users know it
+			log.Warnf("%v", err)
+			pterm.Warning.Printfln("the State of stack %s/%s is NOT locked and concurrent operations may overwrite "+
+				"each other's State, because %s", query.Project, query.Stack, unsupportedErr.Reason)
+			return func() {}, info, nil
+		}
+		return nil, nil, fmt.Errorf("lock State failed. %w", err)
+	}
+	log.Infof("lock State success, lock ID: %s", info.ID)
+
+	return func() {
+		if err := storage.Unlock(query, info.ID); err != nil {
+			log.Errorf("unlock State failed, lock ID: %s. %v", info.ID, err)
+		}
+	}, info, nil
+}
+
+// lockState acquires the lock of the State in this request before an operation modifies it,
+// and returns a function to release the lock when the operation is finished
+func lockState(storage states.StateStorage, request *opsmodels.Request, operationType opsmodels.OperationType) (func(), status.Status) {
+	unlock, _, err := LockState(storage, request.StateQuery(), operationType.String(), request.Operator)
+	if err != nil {
+		var lockedErr *states.LockedError
+		if errors.As(err, &lockedErr) {
+			return nil, status.NewErrorStatusWithCode(status.Locked, err)
+		}
+		return nil, status.NewErrorStatus(err)
+	}
+	return unlock, nil
+}
@@ -0,0 +1,50 @@
+package operation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+)
+
+func TestLockState(t *testing.T) {
+	storage := &local.FileSystemState{Path: filepath.Join(t.TempDir(), local.KusionState)}
+	query := &states.StateQuery{Project: "project", Stack: "dev"}
+
+	unlock, info, err := LockState(storage, query, "StateMv", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "StateMv", info.Operation)
+	assert.Equal(t, "alice", info.Holder)
+
+	_, _, err = LockState(storage, query, "Apply", "bob")
+	var lockedErr *states.LockedError
+	assert.ErrorAs(t, err, &lockedErr)
+	assert.Equal(t, info.ID, lockedErr.Info.ID)
+
+	unlock()
+	unlock, _, err = LockState(storage, query, "Apply", "bob")
+	assert.NoError(t, err)
+	unlock()
+}
+
+type unsupportedLockStorage struct {
+	local.FileSystemState
+}
+
+func (s *unsupportedLockStorage) Lock(query *states.StateQuery, info *states.LockInfo) error {
+	return &states.LockUnsupportedError{Reason: "the test backend can't lock"}
+}
+
+func TestLockState_Unsupported(t *testing.T) {
+	storage := &unsupportedLockStorage{}
+	query := &states.StateQuery{Project: "project", Stack: "dev"}
+
+	unlock, info, err := LockState(storage, query, "Apply", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", info.Holder)
+	unlock()
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"kusionstack.io/kusion/pkg/util/pretty"
 )
@@ -16,16 +17,20 @@ const (
 	Create                      // creating a new resource.
 	Update                      // updating an existing resource.
 	Delete                      // deleting an existing resource.
+	Replace                     // replacing an existing resource which can't be updated in place.
 )
 
+var actionTypeNames = []string{
+	"Undefined",
+	"UnChanged",
+	"Create",
+	"Update",
+	"Delete",
+	"Replace",
+}
+
 func (t ActionType) String() string {
-	return []string{
-		"Undefined",
-		"UnChanged",
-		"Create",
-		"Update",
-		"Delete",
-	}[t]
+	return actionTypeNames[t]
 }
 
 func (t ActionType) MarshalJSON() ([]byte, error) {
@@ -40,6 +45,8 @@ func (t ActionType) Ing() string {
 		return "Updating"
 	case Delete:
 		return "Deleting"
+	case Replace:
+		return "Replacing"
 	default:
 		return "Unchanged"
 	}
@@ -55,7 +62,23 @@ func (t ActionType) PrettyString() string {
 		return pretty.Blue(t.Ing())
 	case Delete:
 		return pretty.Red(t.Ing())
+	case Replace:
+		return pretty.Yellow(t.Ing())
 	default:
 		return pretty.Normal(t.Ing())
 	}
 }
+
+func (t *ActionType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	for i, name := range actionTypeNames {
+		if s == name {
+			*t = ActionType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action type: %s", s)
+}
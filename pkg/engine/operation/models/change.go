@@ -25,6 +25,18 @@ type ChangeStep struct {
 	From interface{} `json:"from,omitempty" yaml:"from,omitempty"`
 	// new data
 	To interface{} `json:"to,omitempty" yaml:"to,omitempty"`
+	// fields owned by other managers that this step would overwrite, like ".spec.replicas (kube-controller-manager)"
+	Conflicts []string `json:"conflicts,omitempty" yaml:"conflicts,omitempty"`
+	// the resource is deleted with deletionPolicy Abandon, which is only dropped from the State and kept actually
+	Abandoned bool `json:"abandoned,omitempty" yaml:"abandoned,omitempty"`
+}
+
+// ActionString returns the name of the action performed by this step. Deleting an abandoned resource is "Abandon"
+func (cs *ChangeStep) ActionString() string {
+	if cs.Action == Delete && cs.Abandoned {
+		return "Abandon"
+	}
+	return cs.Action.String()
 }
 
 // Diff compares objects(from and to) which stores in ChangeStep,
@@ -51,7 +63,11 @@ func (cs *ChangeStep) Diff() (string, error) {
 	}
 	if cs.Action != Undefined {
 		buf.WriteString(pretty.GreenBold("Plan: "))
-		buf.WriteString(pterm.Sprintf("%s\n", cs.Action.PrettyString()))
+		if cs.Action == Delete && cs.Abandoned {
+			buf.WriteString(pretty.Gray("Abandoning, the actual resource is kept\n"))
+		} else {
+			buf.WriteString(pterm.Sprintf("%s\n", cs.Action.PrettyString()))
+		}
 	}
 	buf.WriteString(pretty.GreenBold("Diff: "))
 	if len(strings.TrimSpace(reportString)) == 0 && cs.Action == UnChanged {
@@ -60,6 +76,13 @@ func (cs *ChangeStep) Diff() (string, error) {
 		buf.WriteString("\n" + strings.TrimSpace(reportString))
 	}
 	buf.WriteString("\n")
+	if len(cs.Conflicts) > 0 {
+		buf.WriteString(pretty.YellowBold("Conflicts: "))
+		buf.WriteString(pretty.Yellow("fields managed by others will be overwritten, which requires --force-conflicts\n"))
+		for _, c := range cs.Conflicts {
+			buf.WriteString(pretty.Yellow("  %s\n", c))
+		}
+	}
 	return buf.String(), nil
 }
 
@@ -78,6 +101,7 @@ var (
 	CreateChangeStepFilter   = func(c *ChangeStep) bool { return c.Action == Create }
 	UpdateChangeStepFilter   = func(c *ChangeStep) bool { return c.Action == Update }
 	DeleteChangeStepFilter   = func(c *ChangeStep) bool { return c.Action == Delete }
+	ReplaceChangeStepFilter  = func(c *ChangeStep) bool { return c.Action == Replace }
 	UnChangeChangeStepFilter = func(c *ChangeStep) bool { return c.Action == UnChanged }
 )
 
@@ -175,7 +199,7 @@ func (p *Changes) Summary(writer io.Writer) {
 			itemPrefix = " * └─"
 		}
 
-		tableData = append(tableData, []string{itemPrefix, step.ID, step.Action.String()})
+		tableData = append(tableData, []string{itemPrefix, step.ID, step.ActionString()})
 	}
 
 	pterm.DefaultTable.WithHasHeader().
@@ -187,6 +211,13 @@ func (p *Changes) Summary(writer io.Writer) {
 		WithWriter(writer).
 		Render()
 	pterm.Println() // Blank line
+
+	for _, step := range p.Values() {
+		if len(step.Conflicts) > 0 {
+			pterm.Fprintln(writer, pretty.Yellow("%s would overwrite fields managed by others: %s",
+				step.ID, strings.Join(step.Conflicts, ", ")))
+		}
+	}
 }
 
 func (o *ChangeOrder) PromptDetails() (string, error) {
@@ -196,7 +227,7 @@ func (o *ChangeOrder) PromptDetails() (string, error) {
 
 	for _, key := range o.StepKeys {
 		cs := o.ChangeSteps[key]
-		humanKeyAndOp := pterm.Sprintf("%s %s", cs.ID, pretty.Gray(cs.Action.String()))
+		humanKeyAndOp := pterm.Sprintf("%s %s", cs.ID, pretty.Gray(cs.ActionString()))
 		options = append(options, humanKeyAndOp)
 		optionMaps[humanKeyAndOp] = cs.ID
 	}
@@ -146,6 +146,23 @@ func TestChangeStep_Diff(t *testing.T) {
 	}
 }
 
+func TestChangeStep_DiffConflicts(t *testing.T) {
+	cs := &ChangeStep{ID: "id", Action: Update, Conflicts: []string{".spec.replicas (kubectl-scale)"}}
+	got, err := cs.Diff()
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Conflicts: ")
+	assert.Contains(t, got, ".spec.replicas (kubectl-scale)")
+}
+
+func TestChangeStep_Abandoned(t *testing.T) {
+	cs := &ChangeStep{ID: "id", Action: Delete, Abandoned: true}
+	assert.Equal(t, "Abandon", cs.ActionString())
+	got, err := cs.Diff()
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Abandoning, the actual resource is kept")
+	assert.Equal(t, "Delete", TestChangeStepOpDelete.ActionString())
+}
+
 func TestChanges_Get(t *testing.T) {
 	type fields struct {
 		order   *ChangeOrder
@@ -0,0 +1,25 @@
+package models
+
+import "fmt"
+
+// FailurePolicy decides how to deal with resources that haven't been operated when a resource fails during an operation
+type FailurePolicy string
+
+const (
+	// ContinueOnFailure keeps operating resources in independent branches of the DAG, and only skips
+	// resources depending on the failed one. It is the default policy
+	ContinueOnFailure FailurePolicy = "continue"
+
+	// FailFast skips all resources that haven't started once any resource fails
+	FailFast FailurePolicy = "fail-fast"
+)
+
+// ValidateFailurePolicy returns an error if the policy is not supported. An empty policy means ContinueOnFailure
+func ValidateFailurePolicy(policy string) error {
+	switch FailurePolicy(policy) {
+	case "", ContinueOnFailure, FailFast:
+		return nil
+	default:
+		return fmt.Errorf("invalid failure policy %s, supported policies: %s, %s", policy, ContinueOnFailure, FailFast)
+	}
+}
@@ -1,8 +1,11 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/jinzhu/copier"
 
@@ -36,7 +39,8 @@ type Operation struct {
 	// IgnoreFields will be ignored in preview stage
 	IgnoreFields []string
 
-	// ChangeOrder is resources' change order during this operation
+	// ChangeOrder is resources' change order during this operation. For the Apply operation, it is the change order of
+	// a saved Plan if it is not nil, and resources whose actions differ from the Plan will not be applied
 	ChangeOrder *ChangeOrder
 
 	// RuntimeMap contains all infrastructure runtimes involved this operation. The key of this map is the Runtime type
@@ -57,12 +61,47 @@ type Operation struct {
 
 	// SecretStores contains all available secret stores
 	SecretStores *vals.SecretStores
+
+	// Parallelism limits the number of resources operated concurrently when walking the DAG. 0 means no limit
+	Parallelism int
+
+	// FailurePolicy decides how to deal with resources that haven't been operated when a resource fails
+	FailurePolicy FailurePolicy
+
+	// ReadinessTimeout is the max time to wait for each applied resource to be ready before operating resources
+	// depending on it. Resources are not waited if it is 0
+	ReadinessTimeout time.Duration
+
+	// RollbackOnFailure reverts resources applied successfully to the prior State in reverse dependency order
+	// if any resource fails during the Apply operation
+	RollbackOnFailure bool
+
+	// ForceConflicts takes the ownership of fields managed by others when applying resources, such as Kubernetes
+	// resources applied server-side
+	ForceConflicts bool
+
+	// Ctx is passed to all Runtime calls of this operation. Once it is canceled, such as receiving an interrupt
+	// signal, resources that haven't started are skipped, and in-flight Runtime calls are aborted.
+	// context.Background() is used if it is nil
+	Ctx context.Context
+
+	// NotAttempted contains IDs of resources never attempted, which are skipped since the operation is interrupted or
+	// resources they depend on failed
+	NotAttempted []string
+
+	// RetryPolicy decides how to retry Runtime calls failed with transient errors. Runtime calls are not retried if
+	// it is nil
+	RetryPolicy *RetryPolicy
+
+	// stackStates caches the latest States of other stacks referenced by resources, keyed by "project/stack"
+	stackStates map[string]*states.State
 }
 
 type Message struct {
 	ResourceID string   // ResourceNode.ID()
-	OpResult   OpResult // Success/Failed/Skip
+	OpResult   OpResult // Success/Failed/Skip/Retry/Progress
 	OpErr      error    // Operate error detail
+	Detail     string   // Progress detail reported by the Runtime
 }
 
 type Request struct {
@@ -72,6 +111,14 @@ type Request struct {
 	Cluster  string                `json:"cluster"`
 	Operator string                `json:"operator"`
 	Spec     *models.Spec          `json:"spec"`
+
+	// Targets limits the operation to resources matching these resource IDs or glob patterns,
+	// together with resources they require. All resources will be operated if it is empty
+	Targets []string `json:"targets,omitempty"`
+
+	// ExpectedSerial is the serial the latest State must have before the operation starts, which is used to
+	// apply a saved Plan. The serial is not checked if it is nil
+	ExpectedSerial *uint64 `json:"expectedSerial,omitempty"`
 }
 
 type OpResult string
@@ -81,8 +128,56 @@ const (
 	Success OpResult = "Success"
 	Failed  OpResult = "Failed"
 	Skip    OpResult = "Skip"
+	Retry   OpResult = "Retry"
+
+	// Progress is sent while a Runtime is applying the resource, such as the log lines of terraform apply
+	Progress OpResult = "Progress"
 )
 
+// Context returns the context of this operation
+func (o *Operation) Context() context.Context {
+	if o.Ctx == nil {
+		return context.Background()
+	}
+	return o.Ctx
+}
+
+// Interrupted returns whether the context of this operation has been canceled
+func (o *Operation) Interrupted() bool {
+	return o.Context().Err() != nil
+}
+
+// LatestStackState returns the latest State of another stack in the same tenant and cluster from the StateStorage
+// of this operation. States are read once and cached during the operation, and nil is returned if the State doesn't
+// exist. Storages which can't keep States of other stacks, like the local backend, return an error
+func (o *Operation) LatestStackState(project, stack string) (*states.State, error) {
+	o.Lock.Lock()
+	defer o.Lock.Unlock()
+
+	key := project + "/" + stack
+	if state, ok := o.stackStates[key]; ok {
+		return state, nil
+	}
+	query := &states.StateQuery{Project: project, Stack: stack}
+	if o.ResultState != nil {
+		query.Tenant = o.ResultState.Tenant
+		query.Cluster = o.ResultState.Cluster
+	}
+	state, err := o.StateStorage.GetLatestState(query)
+	if err != nil {
+		var unsupported *states.UnsupportedQueryError
+		if errors.As(err, &unsupported) {
+			return nil, fmt.Errorf("%w. Please configure a remote backend to reference other stacks", err)
+		}
+		return nil, fmt.Errorf("get the latest State of stack %s failed: %w", key, err)
+	}
+	if o.stackStates == nil {
+		o.stackStates = map[string]*states.State{}
+	}
+	o.stackStates[key] = state
+	return state, nil
+}
+
 // RefreshResourceIndex refresh resources in CtxResourceIndex & StateResourceIndex
 func (o *Operation) RefreshResourceIndex(resourceKey string, resource *models.Resource, actionType ActionType) error {
 	o.Lock.Lock()
@@ -92,7 +187,7 @@ func (o *Operation) RefreshResourceIndex(resourceKey string, resource *models.Re
 	case Delete:
 		o.CtxResourceIndex[resourceKey] = nil
 		o.StateResourceIndex[resourceKey] = nil
-	case Create, Update, UnChanged:
+	case Create, Update, Replace, UnChanged:
 		o.CtxResourceIndex[resourceKey] = resource
 		o.StateResourceIndex[resourceKey] = resource
 	default:
@@ -101,13 +196,18 @@ func (o *Operation) RefreshResourceIndex(resourceKey string, resource *models.Re
 	return nil
 }
 
-func (o *Operation) InitStates(request *Request) (*states.State, *states.State) {
-	query := &states.StateQuery{
-		Tenant:  request.Tenant,
-		Stack:   request.Stack.Name,
-		Project: request.Project.Name,
-		Cluster: request.Cluster,
+// StateQuery returns the query to locate the State of the stack in this request
+func (r *Request) StateQuery() *states.StateQuery {
+	return &states.StateQuery{
+		Tenant:  r.Tenant,
+		Stack:   r.Stack.Name,
+		Project: r.Project.Name,
+		Cluster: r.Cluster,
 	}
+}
+
+func (o *Operation) InitStates(request *Request) (*states.State, *states.State) {
+	query := request.StateQuery()
 	latestState, err := o.StateStorage.GetLatestState(query)
 	util.CheckNotError(err, fmt.Sprintf("get the latest State failed with query: %v", jsonutil.Marshal2PrettyString(query)))
 	if latestState == nil {
@@ -122,6 +222,8 @@ func (o *Operation) InitStates(request *Request) (*states.State, *states.State)
 	resultState.Project = request.Project.Name
 
 	resultState.Resources = nil
+	// outputs are kept until they are resolved again
+	resultState.Outputs = latestState.Outputs
 
 	return latestState, resultState
 }
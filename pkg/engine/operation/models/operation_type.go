@@ -9,4 +9,22 @@ const (
 	ApplyPreview
 	Destroy
 	DestroyPreview
+	Import
 )
+
+var operationTypeNames = []string{
+	"Undefined",
+	"Apply",
+	"ApplyPreview",
+	"Destroy",
+	"DestroyPreview",
+	"Import",
+}
+
+// String returns the name of the operation type, and "Undefined" for unknown ones
+func (t OperationType) String() string {
+	if t < 0 || int(t) >= len(operationTypeNames) {
+		return operationTypeNames[UndefinedOperation]
+	}
+	return operationTypeNames[t]
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationType_String(t *testing.T) {
+	assert.Equal(t, "Apply", Apply.String())
+	assert.Equal(t, "Import", Import.String())
+	assert.Equal(t, "Undefined", UndefinedOperation.String())
+	assert.Equal(t, "Undefined", OperationType(-1).String())
+	assert.Equal(t, "Undefined", OperationType(100).String())
+}
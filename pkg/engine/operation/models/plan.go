@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"kusionstack.io/kusion/pkg/models"
+)
+
+// Plan is the result of a preview saved in a file. Applying a Plan executes exactly the previewed changes, and it is
+// refused if the State has been modified since the preview
+type Plan struct {
+	Tenant  string `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	Project string `json:"project" yaml:"project"`
+	Stack   string `json:"stack" yaml:"stack"`
+	Cluster string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+
+	// Serial is the serial of the latest State when the changes are computed
+	Serial uint64 `json:"serial" yaml:"serial"`
+
+	// Targets are the resource IDs or glob patterns the preview is limited to
+	Targets []string `json:"targets,omitempty" yaml:"targets,omitempty"`
+
+	// Spec is the compiled Spec of the preview
+	Spec *models.Spec `json:"spec" yaml:"spec"`
+
+	// ChangeOrder is the changes computed by the preview
+	ChangeOrder *ChangeOrder `json:"changeOrder" yaml:"changeOrder"`
+}
+
+// Save writes the Plan into the file of the path in json format. The file is only readable by the owner, since the
+// previewed changes may contain resolved secrets in plain text
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		return fmt.Errorf("marshal plan failed: %w", err)
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadPlan reads the Plan saved in the file of the path
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{}
+	if err = json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("unmarshal plan file %s failed: %w", path, err)
+	}
+	if p.Spec == nil || p.ChangeOrder == nil {
+		return nil, fmt.Errorf("invalid plan file %s: spec and changeOrder are required", path)
+	}
+	return p, nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/models"
+)
+
+func TestPlan_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.kplan")
+	res := models.Resource{ID: "v1:ServiceAccount:default:foo", Type: "Kubernetes"}
+	plan := &Plan{
+		Project: "project",
+		Stack:   "dev",
+		Serial:  3,
+		Targets: []string{"v1:ServiceAccount:*"},
+		Spec:    &models.Spec{Resources: models.Resources{res}},
+		ChangeOrder: &ChangeOrder{
+			StepKeys:    []string{res.ID},
+			ChangeSteps: map[string]*ChangeStep{res.ID: NewChangeStep(res.ID, Update, &res, &res)},
+		},
+	}
+	assert.NoError(t, plan.Save(path))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := LoadPlan(path)
+	assert.NoError(t, err)
+	assert.Equal(t, plan.Serial, loaded.Serial)
+	assert.Equal(t, plan.Targets, loaded.Targets)
+	assert.Equal(t, plan.Spec, loaded.Spec)
+	assert.Equal(t, Update, loaded.ChangeOrder.Get(res.ID).Action)
+
+	_, err = LoadPlan(filepath.Join(t.TempDir(), "not-exist.kplan"))
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"project": "project", "stack": "dev"}`), 0o644))
+	_, err = LoadPlan(path)
+	assert.ErrorContains(t, err, "spec and changeOrder are required")
+}
+
+func TestActionType_UnmarshalJSON(t *testing.T) {
+	var a ActionType
+	assert.NoError(t, a.UnmarshalJSON([]byte(`"Delete"`)))
+	assert.Equal(t, Delete, a)
+	assert.Error(t, a.UnmarshalJSON([]byte(`"Rename"`)))
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+const (
+	DefaultMaxRetries     = 3
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// RetryPolicy decides whether and when to retry a Runtime call failed with a transient error
+type RetryPolicy struct {
+	// MaxRetries is the max number of retries of each Runtime call. 0 disables retrying
+	MaxRetries int
+
+	// InitialBackoff is the time to wait before the first retry, which is doubled for each subsequent retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the max time to wait before a retry
+	MaxBackoff time.Duration
+}
+
+// NewRetryPolicy returns the retry policy configured in the stack. Retrying is disabled if the stack doesn't configure
+// retry, otherwise unset configs are filled with default values
+func NewRetryPolicy(stack *projectstack.Stack) (*RetryPolicy, error) {
+	p := &RetryPolicy{
+		MaxRetries:     0,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+	}
+	if stack == nil || stack.Retry == nil {
+		return p, nil
+	}
+
+	config := stack.Retry
+	p.MaxRetries = DefaultMaxRetries
+	if config.MaxRetries != nil {
+		if *config.MaxRetries < 0 {
+			return nil, fmt.Errorf("invalid retry config of stack %s: maxRetries must not be negative", stack.Name)
+		}
+		p.MaxRetries = *config.MaxRetries
+	}
+	var err error
+	if config.InitialBackoff != "" {
+		if p.InitialBackoff, err = time.ParseDuration(config.InitialBackoff); err != nil || p.InitialBackoff <= 0 {
+			return nil, fmt.Errorf("invalid retry config of stack %s: initialBackoff must be a positive duration, got %s",
+				stack.Name, config.InitialBackoff)
+		}
+	}
+	if config.MaxBackoff != "" {
+		if p.MaxBackoff, err = time.ParseDuration(config.MaxBackoff); err != nil || p.MaxBackoff <= 0 {
+			return nil, fmt.Errorf("invalid retry config of stack %s: maxBackoff must be a positive duration, got %s",
+				stack.Name, config.MaxBackoff)
+		}
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	return p, nil
+}
+
+// Retryable returns whether the failed status is caused by a transient error, such as throttling or temporary
+// unavailability of the infrastructure
+func (p *RetryPolicy) Retryable(s status.Status) bool {
+	if !status.IsErr(s) {
+		return false
+	}
+	switch s.Code() {
+	case status.Unavailable, status.Throttled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Backoff returns the time to wait before the retry, which starts from 1
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	newStack := func(config *projectstack.RetryConfig) *projectstack.Stack {
+		return &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "dev", Retry: config}}
+	}
+	zero := 0
+	negative := -1
+
+	tests := map[string]struct {
+		stack   *projectstack.Stack
+		want    *RetryPolicy
+		wantErr string
+	}{
+		"default": {
+			stack: newStack(nil),
+			want:  &RetryPolicy{MaxRetries: 0, InitialBackoff: DefaultInitialBackoff, MaxBackoff: DefaultMaxBackoff},
+		},
+		"default of configured retry": {
+			stack: newStack(&projectstack.RetryConfig{}),
+			want:  &RetryPolicy{MaxRetries: DefaultMaxRetries, InitialBackoff: DefaultInitialBackoff, MaxBackoff: DefaultMaxBackoff},
+		},
+		"configured": {
+			stack: newStack(&projectstack.RetryConfig{MaxRetries: &zero, InitialBackoff: "500ms", MaxBackoff: "10s"}),
+			want:  &RetryPolicy{MaxRetries: 0, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 10 * time.Second},
+		},
+		"max backoff less than initial backoff": {
+			stack: newStack(&projectstack.RetryConfig{InitialBackoff: "1m"}),
+			want:  &RetryPolicy{MaxRetries: DefaultMaxRetries, InitialBackoff: time.Minute, MaxBackoff: time.Minute},
+		},
+		"negative max retries": {
+			stack:   newStack(&projectstack.RetryConfig{MaxRetries: &negative}),
+			wantErr: "maxRetries must not be negative",
+		},
+		"invalid backoff": {
+			stack:   newStack(&projectstack.RetryConfig{MaxBackoff: "ten seconds"}),
+			wantErr: "maxBackoff must be a positive duration, got ten seconds",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewRetryPolicy(tc.stack)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, p)
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	p := &RetryPolicy{MaxRetries: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, p.Backoff(1))
+	assert.Equal(t, 2*time.Second, p.Backoff(2))
+	assert.Equal(t, 4*time.Second, p.Backoff(3))
+	assert.Equal(t, 5*time.Second, p.Backoff(4))
+	assert.Equal(t, 5*time.Second, p.Backoff(10))
+
+	assert.True(t, p.Retryable(status.NewErrorStatusWithMsg(status.Throttled, "429")))
+	assert.True(t, p.Retryable(status.NewErrorStatusWithMsg(status.Unavailable, "503")))
+	assert.False(t, p.Retryable(status.NewErrorStatus(errors.New("invalid object"))))
+	assert.False(t, p.Retryable(nil))
+}
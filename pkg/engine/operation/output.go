@@ -0,0 +1,28 @@
+package operation
+
+import (
+	"fmt"
+	"reflect"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+// ResolveOutputs resolves outputs declared in the stack with resources in resourceIndex. Literal values are kept
+// as they are, and references prefixed with graph.ImplicitRefPrefix are replaced with the referenced attributes
+func ResolveOutputs(outputs map[string]string, resourceIndex map[string]*models.Resource) (map[string]interface{}, status.Status) {
+	if len(outputs) == 0 {
+		return nil, nil
+	}
+
+	resolved := make(map[string]interface{}, len(outputs))
+	for name, value := range outputs {
+		_, v, s := graph.ReplaceImplicitRef(reflect.ValueOf(value), resourceIndex, graph.MustImplicitReplaceFun)
+		if status.IsErr(s) {
+			return nil, status.NewErrorStatusWithMsg(s.Code(), fmt.Sprintf("resolve output %s failed: %s", name, s.Message()))
+		}
+		resolved[name] = v.Interface()
+	}
+	return resolved, nil
+}
@@ -0,0 +1,84 @@
+package operation
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+var outputResources = map[string]*models.Resource{
+	"v1:Service:default:nginx": {
+		ID: "v1:Service:default:nginx",
+		Attributes: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"clusterIP": "10.0.0.1",
+				"ports":     []interface{}{map[string]interface{}{"port": 80}},
+			},
+		},
+	},
+}
+
+func TestResolveOutputs(t *testing.T) {
+	outputs, s := ResolveOutputs(map[string]string{
+		"ip":    "$kusion_path.v1:Service:default:nginx.spec.clusterIP",
+		"ports": "$kusion_path.v1:Service:default:nginx.spec.ports",
+		"env":   "dev",
+	}, outputResources)
+	assert.Nil(t, s)
+	assert.Equal(t, map[string]interface{}{
+		"ip":    "10.0.0.1",
+		"ports": []interface{}{map[string]interface{}{"port": 80}},
+		"env":   "dev",
+	}, outputs)
+
+	_, s = ResolveOutputs(map[string]string{"ip": "$kusion_path.v1:Service:default:missing.spec.clusterIP"}, outputResources)
+	assert.True(t, status.IsErr(s))
+	assert.Contains(t, s.Message(), "resolve output ip failed")
+
+	outputs, s = ResolveOutputs(nil, outputResources)
+	assert.Nil(t, s)
+	assert.Nil(t, outputs)
+}
+
+func TestApplyOperation_updateOutputs(t *testing.T) {
+	mockey.PatchConvey("save outputs only if they are changed", t, func() {
+		storage := &local.FileSystemState{}
+		saved := 0
+		mockey.Mock((*local.FileSystemState).Apply).To(func(f *local.FileSystemState, state *states.State) error {
+			saved++
+			return nil
+		}).Build()
+
+		ao := &ApplyOperation{Operation: opsmodels.Operation{
+			StateStorage:       storage,
+			StateResourceIndex: outputResources,
+			ResultState:        states.NewState(),
+			Lock:               &sync.Mutex{},
+		}}
+		stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{
+			Name:    "dev",
+			Outputs: map[string]string{"ip": "$kusion_path.v1:Service:default:nginx.spec.clusterIP"},
+		}}
+
+		assert.Nil(t, ao.updateOutputs(stack))
+		assert.Equal(t, map[string]interface{}{"ip": "10.0.0.1"}, ao.ResultState.Outputs)
+		assert.Equal(t, 1, saved)
+
+		assert.Nil(t, ao.updateOutputs(stack))
+		assert.Equal(t, 1, saved)
+
+		// outputs removed from the stack are dropped
+		assert.Nil(t, ao.updateOutputs(&projectstack.Stack{}))
+		assert.Nil(t, ao.ResultState.Outputs)
+		assert.Equal(t, 2, saved)
+	})
+}
@@ -55,17 +55,26 @@ func (d *DeleteResourceParser) Parse(g *dag.AcyclicGraph) (s status.Status) {
 		}
 		rnID := rn.Hashcode().(string)
 
-		if !g.HasVertex(rn) && manifestGraphMap[rnID] == nil {
+		// the delete node may have been added when linking resources depending on it, so check it anyway
+		if manifestGraphMap[rnID] == nil {
 			log.Infof("resource:%v not found in models. Mark as delete node", key)
-			// we cannot delete this node if any node dependsOn this node
-			for _, v := range priorDependsOn[rnID] {
-				if manifestGraphMap[v] != nil {
-					msg := fmt.Sprintf("%s dependson %s, cannot delete resource %s", v, rnID, rnID)
-					return status.NewErrorStatusWithMsg(status.Internal, msg)
+			lifecycle, err := resource.Lifecycle()
+			if err != nil {
+				return status.NewErrorStatusWithMsg(status.IllegalManifest, err.Error())
+			}
+			// we cannot delete this node if any node dependsOn this node, unless it is abandoned and will be kept
+			if !lifecycle.Abandon() {
+				for _, v := range priorDependsOn[rnID] {
+					if manifestGraphMap[v] != nil {
+						msg := fmt.Sprintf("%s dependson %s, cannot delete resource %s", v, rnID, rnID)
+						return status.NewErrorStatusWithMsg(status.Internal, msg)
+					}
 				}
 			}
-			g.Add(rn)
-			g.Connect(dag.BasicEdge(root, rn))
+			if !g.HasVertex(rn) {
+				g.Add(rn)
+				g.Connect(dag.BasicEdge(root, rn))
+			}
 		}
 
 		// compute implicit and explicate dependencies
@@ -87,3 +96,24 @@ func (d *DeleteResourceParser) Parse(g *dag.AcyclicGraph) (s status.Status) {
 	g.TransitiveReduction()
 	return s
 }
+
+// CheckPreventDestroy returns an error if any resource to be deleted in the graph has preventDestroy set in its
+// lifecycle. Abandoned resources are not checked, since they are only dropped from the State and never destroyed.
+// It should be called after the graph is pruned, so that resources not operated are not checked
+func CheckPreventDestroy(g *dag.AcyclicGraph) status.Status {
+	for _, v := range g.Vertices() {
+		rn, ok := v.(*graph.ResourceNode)
+		if !ok || rn.Action != opsmodels.Delete {
+			continue
+		}
+		lifecycle, err := rn.State().Lifecycle()
+		if err != nil {
+			return status.NewErrorStatusWithMsg(status.IllegalManifest, err.Error())
+		}
+		if lifecycle.PreventDestroy && !lifecycle.Abandon() {
+			return status.NewErrorStatusWithMsg(status.InvalidArgument, fmt.Sprintf(
+				"can't delete resource %s since preventDestroy is set in its lifecycle", rn.Hashcode()))
+		}
+	}
+	return nil
+}
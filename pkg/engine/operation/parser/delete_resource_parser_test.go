@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
 	"kusionstack.io/kusion/pkg/models"
 	"kusionstack.io/kusion/third_party/terraform/dag"
 )
@@ -65,6 +66,75 @@ func TestDeleteResourceParser_Parse(t *testing.T) {
 	}
 }
 
+func TestDeleteResourceParser_ParseAbandoned(t *testing.T) {
+	// app is still in the Spec, and it depended on db which is removed from the Spec
+	resources := []models.Resource{
+		{ID: "db", Attributes: map[string]interface{}{}},
+		{ID: "app", Attributes: map[string]interface{}{}, DependsOn: []string{"db"}},
+	}
+	newGraph := func() *dag.AcyclicGraph {
+		ag := &dag.AcyclicGraph{}
+		root := &graph.RootNode{}
+		ag.Add(root)
+		app, _ := graph.NewResourceNode("app", &resources[1], opsmodels.Update)
+		ag.Add(app)
+		ag.Connect(dag.BasicEdge(root, app))
+		return ag
+	}
+
+	if s := NewDeleteResourceParser(resources).Parse(newGraph()); s == nil {
+		t.Errorf("expect an error when deleting a resource other resources depend on")
+	}
+
+	resources[0].Extensions = map[string]interface{}{
+		models.LifecycleKey: map[string]interface{}{"deletionPolicy": "Abandon"},
+	}
+	if s := NewDeleteResourceParser(resources).Parse(newGraph()); s != nil {
+		t.Errorf("unexpected error when abandoning a resource: %v", s)
+	}
+
+	resources[0].Extensions[models.LifecycleKey] = map[string]interface{}{"deletionPolicy": "Retain"}
+	if s := NewDeleteResourceParser(resources).Parse(newGraph()); s == nil || !strings.Contains(s.Message(), "invalid deletionPolicy Retain") {
+		t.Errorf("expect an error of invalid deletionPolicy, got: %v", s)
+	}
+}
+
+func TestCheckPreventDestroy(t *testing.T) {
+	ag := &dag.AcyclicGraph{}
+	ag.Add(&graph.RootNode{})
+	protected := &models.Resource{
+		ID:         "db",
+		Extensions: map[string]interface{}{models.LifecycleKey: map[string]interface{}{"preventDestroy": true}},
+	}
+	updated, _ := graph.NewResourceNode("db", protected, opsmodels.Update)
+	ag.Add(updated)
+	if s := CheckPreventDestroy(ag); s != nil {
+		t.Errorf("unexpected error when updating a protected resource: %v", s)
+	}
+
+	ag.Remove(updated)
+	deleted, _ := graph.NewResourceNode("db", protected, opsmodels.Delete)
+	ag.Add(deleted)
+	if s := CheckPreventDestroy(ag); s == nil || !strings.Contains(s.Message(), "can't delete resource db") {
+		t.Errorf("expect an error when deleting a protected resource, got: %v", s)
+	}
+
+	// the abandoned resource is only dropped from the State
+	ag.Remove(deleted)
+	abandoned := &models.Resource{
+		ID: "db",
+		Extensions: map[string]interface{}{models.LifecycleKey: map[string]interface{}{
+			"preventDestroy": true,
+			"deletionPolicy": "Abandon",
+		}},
+	}
+	deleted, _ = graph.NewResourceNode("db", abandoned, opsmodels.Delete)
+	ag.Add(deleted)
+	if s := CheckPreventDestroy(ag); s != nil {
+		t.Errorf("unexpected error when abandoning a protected resource: %v", s)
+	}
+}
+
 const testGraphTransReductionMultiple = `
 instance
   vsecurity
@@ -7,6 +7,7 @@ import (
 
 	"kusionstack.io/kusion/pkg/engine/operation/graph"
 	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/operation/parser"
 	runtimeinit "kusionstack.io/kusion/pkg/engine/runtime/init"
 	"kusionstack.io/kusion/pkg/engine/states"
 	"kusionstack.io/kusion/pkg/log"
@@ -64,7 +65,7 @@ func (po *PreviewOperation) Preview(request *PreviewRequest) (rsp *PreviewRespon
 	// Kusion is a multi-runtime system. We initialize runtimes dynamically by resource types
 	resources := request.Spec.Resources
 	resources = append(resources, priorState.Resources...)
-	runtimesMap, s := runtimeinit.Runtimes(resources)
+	runtimesMap, s := runtimeinit.Runtimes(resources, request.Project)
 	if status.IsErr(s) {
 		return nil, s
 	}
@@ -82,6 +83,16 @@ func (po *PreviewOperation) Preview(request *PreviewRequest) (rsp *PreviewRespon
 	if status.IsErr(s) {
 		return nil, s
 	}
+	if s = pruneGraph(ag, request.Targets); status.IsErr(s) {
+		return nil, s
+	}
+	if s = parser.CheckPreventDestroy(ag); status.IsErr(s) {
+		return nil, s
+	}
+	retry, s := retryPolicy(&o)
+	if status.IsErr(s) {
+		return nil, s
+	}
 	// copy priorStateResourceIndex into a new map
 	stateResourceIndex := map[string]*models.Resource{}
 	for k, v := range priorStateResourceIndex {
@@ -105,13 +116,18 @@ func (po *PreviewOperation) Preview(request *PreviewRequest) (rsp *PreviewRespon
 			ResultState:             resultState,
 			Lock:                    &sync.Mutex{},
 			SecretStores:            o.SecretStores,
+			Parallelism:             o.Parallelism,
+			FailurePolicy:           o.FailurePolicy,
+			Ctx:                     o.Ctx,
+			RetryPolicy:             retry,
 		},
 	}
 
-	w := &dag.Walker{Callback: previewOperation.previewWalkFun}
-	w.Update(ag)
-	// Wait
-	if diags := w.Wait(); diags.HasErrors() {
+	diags := walkResources(&previewOperation.Operation, ag, previewOperation.previewWalkFun)
+	if s := interruptedStatus(&previewOperation.Operation, diags); status.IsErr(s) {
+		return nil, s
+	}
+	if diags.HasErrors() {
 		return nil, status.NewErrorStatus(diags.Err())
 	}
 
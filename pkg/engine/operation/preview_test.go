@@ -159,6 +159,46 @@ func TestOperation_Preview(t *testing.T) {
 			},
 			wantS: nil,
 		},
+		{
+			name: "success-when-apply-with-targets",
+			fields: fields{
+				OperationType: opsmodels.ApplyPreview,
+				RuntimeMap:    map[models.Type]runtime.Runtime{runtime.Kubernetes: &fakePreviewRuntime{}},
+				StateStorage:  &local.FileSystemState{Path: local.KusionState},
+				Order:         &opsmodels.ChangeOrder{StepKeys: []string{}, ChangeSteps: map[string]*opsmodels.ChangeStep{}},
+			},
+			args: args{
+				request: &PreviewRequest{
+					Request: opsmodels.Request{
+						Tenant:   "fake-tenant",
+						Stack:    stack,
+						Project:  project,
+						Operator: "fake-operator",
+						Spec: &models.Spec{
+							Resources: []models.Resource{
+								FakeResourceState,
+								FakeResourceState2,
+							},
+						},
+						Targets: []string{"fake-id"},
+					},
+				},
+			},
+			wantRsp: &PreviewResponse{
+				Order: &opsmodels.ChangeOrder{
+					StepKeys: []string{"fake-id"},
+					ChangeSteps: map[string]*opsmodels.ChangeStep{
+						"fake-id": {
+							ID:     "fake-id",
+							Action: opsmodels.Create,
+							From:   (*models.Resource)(nil),
+							To:     &FakeResourceState,
+						},
+					},
+				},
+			},
+			wantS: nil,
+		},
 		{
 			name: "success-when-destroy",
 			fields: fields{
@@ -264,7 +304,7 @@ func TestOperation_Preview(t *testing.T) {
 				},
 			}
 
-			mockey.Mock(runtimeinit.Runtimes).To(func(resources models.Resources) (map[models.Type]runtime.Runtime, status.Status) {
+			mockey.Mock(runtimeinit.Runtimes).To(func(resources models.Resources, project *projectstack.Project) (map[models.Type]runtime.Runtime, status.Status) {
 				return map[models.Type]runtime.Runtime{runtime.Kubernetes: &fakePreviewRuntime{}}, nil
 			}).Build()
 			gotRsp, gotS := o.Preview(tt.args.request)
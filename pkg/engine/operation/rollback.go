@@ -0,0 +1,137 @@
+package operation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+	"kusionstack.io/kusion/third_party/terraform/dag"
+)
+
+// RollbackReport records the result of reverting applied resources after an apply fails
+type RollbackReport struct {
+	// RolledBack contains IDs of resources reverted to the prior State, in the order of rolling back
+	RolledBack []string
+
+	// Failed contains resources that are not reverted, keyed by resource IDs
+	Failed map[string]error
+}
+
+func (r *RollbackReport) String() string {
+	var b strings.Builder
+	b.WriteString("Rollback report:\n")
+	if len(r.RolledBack) == 0 {
+		b.WriteString("  rolled back: <none>\n")
+	} else {
+		b.WriteString(fmt.Sprintf("  rolled back: %s\n", strings.Join(r.RolledBack, ", ")))
+	}
+	if len(r.Failed) != 0 {
+		ids := make([]string, 0, len(r.Failed))
+		for id := range r.Failed {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		b.WriteString("  failed to roll back:\n")
+		for _, id := range ids {
+			b.WriteString(fmt.Sprintf("    %s: %v\n", id, r.Failed[id]))
+		}
+	}
+	return b.String()
+}
+
+// recordApplied saves the resource node applied successfully. Nodes are saved in the order of completion, which
+// always comes after the completion of all nodes it depends on
+func (ao *ApplyOperation) recordApplied(rn *graph.ResourceNode) {
+	ao.Lock.Lock()
+	defer ao.Lock.Unlock()
+	ao.applied = append(ao.applied, rn)
+}
+
+// rollback reverts the applied resource nodes to PriorStateResourceIndex in reverse dependency order. If a resource
+// fails to roll back, resources it depends on are kept as they are, since reverting them may break it
+func (ao *ApplyOperation) rollback(g *dag.AcyclicGraph) *RollbackReport {
+	report := &RollbackReport{Failed: map[string]error{}}
+	blocked := map[string]string{}
+
+	for i := len(ao.applied) - 1; i >= 0; i-- {
+		rn := ao.applied[i]
+		id := rn.Hashcode().(string)
+		if rn.Action == opsmodels.UnChanged {
+			continue
+		}
+		if by, ok := blocked[id]; ok {
+			report.Failed[id] = fmt.Errorf("resource %s depending on it failed to roll back", by)
+			continue
+		}
+
+		log.Infof("rolling back resource %s, action: %s", id, rn.Action)
+		if s := ao.rollbackResource(rn); status.IsErr(s) {
+			log.Errorf("roll back resource %s failed: %v", id, s)
+			report.Failed[id] = fmt.Errorf("%s", s.Message())
+			upstream, err := g.Descendents(rn)
+			if err != nil {
+				continue
+			}
+			for _, u := range upstream {
+				if n, ok := u.(*graph.ResourceNode); ok {
+					blocked[n.Hashcode().(string)] = id
+				}
+			}
+			continue
+		}
+		report.RolledBack = append(report.RolledBack, id)
+	}
+	return report
+}
+
+// rollbackResource reverts a resource to its prior State. Created resources are deleted, and updated, replaced or
+// deleted resources are applied with the prior State again
+func (ao *ApplyOperation) rollbackResource(rn *graph.ResourceNode) status.Status {
+	o := &ao.Operation
+	id := rn.Hashcode().(string)
+	prior := o.PriorStateResourceIndex[id]
+	o.Lock.Lock()
+	current := o.StateResourceIndex[id]
+	o.Lock.Unlock()
+
+	rt := o.RuntimeMap[rn.State().Type]
+	var res *models.Resource
+	switch {
+	case prior == nil:
+		if current == nil {
+			current = rn.State()
+		}
+		response := rt.Delete(context.Background(), &runtime.DeleteRequest{Resource: current, Stack: o.Stack})
+		if response != nil && status.IsErr(response.Status) {
+			return response.Status
+		}
+	default:
+		response := rt.Apply(context.Background(), &runtime.ApplyRequest{
+			PriorResource:    current,
+			PlanResource:     prior,
+			Stack:            o.Stack,
+			ReadinessTimeout: o.ReadinessTimeout,
+			Replace:          rn.Action == opsmodels.Replace,
+			ForceConflicts:   o.ForceConflicts,
+		})
+		if status.IsErr(response.Status) {
+			return response.Status
+		}
+		res = response.Resource
+	}
+
+	o.Lock.Lock()
+	o.StateResourceIndex[id] = res
+	o.Lock.Unlock()
+	if err := o.UpdateState(o.StateResourceIndex); err != nil {
+		return status.NewErrorStatus(err)
+	}
+	return nil
+}
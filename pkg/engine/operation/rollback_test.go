@@ -0,0 +1,97 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/engine/states/local"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+	"kusionstack.io/kusion/third_party/terraform/dag"
+)
+
+// rollbackRuntime records the operated resources, and fails to operate the resource of failID
+type rollbackRuntime struct {
+	fakerRuntime
+	failID string
+	calls  []string
+}
+
+func (r *rollbackRuntime) Apply(ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+	r.calls = append(r.calls, "apply "+request.PlanResource.ID)
+	if request.PlanResource.ID == r.failID {
+		return &runtime.ApplyResponse{Status: status.NewErrorStatus(errors.New("mock error"))}
+	}
+	return &runtime.ApplyResponse{Resource: request.PlanResource}
+}
+
+func (r *rollbackRuntime) Delete(ctx context.Context, request *runtime.DeleteRequest) *runtime.DeleteResponse {
+	r.calls = append(r.calls, "delete "+request.Resource.ID)
+	return &runtime.DeleteResponse{}
+}
+
+func TestApplyOperation_rollback(t *testing.T) {
+	// a is created, b depending on a is updated, and c depending on b is deleted
+	newOperation := func(t *testing.T, rt *rollbackRuntime) (*ApplyOperation, *dag.AcyclicGraph) {
+		resource := func(id string, v string) *models.Resource {
+			return &models.Resource{ID: id, Type: runtime.Kubernetes, Attributes: map[string]interface{}{"v": v}}
+		}
+		g := &dag.AcyclicGraph{}
+		root := &graph.RootNode{}
+		g.Add(root)
+		ao := &ApplyOperation{Operation: opsmodels.Operation{
+			OperationType:           opsmodels.Apply,
+			StateStorage:            &local.FileSystemState{Path: filepath.Join(t.TempDir(), local.KusionState)},
+			PriorStateResourceIndex: map[string]*models.Resource{"b": resource("b", "old"), "c": resource("c", "old")},
+			StateResourceIndex:      map[string]*models.Resource{"a": resource("a", "new"), "b": resource("b", "new"), "c": nil},
+			RuntimeMap:              map[models.Type]runtime.Runtime{runtime.Kubernetes: rt},
+			ResultState:             states.NewState(),
+			Lock:                    &sync.Mutex{},
+		}}
+		var prev dag.Vertex = root
+		for _, n := range []struct {
+			id     string
+			action opsmodels.ActionType
+		}{{"a", opsmodels.Create}, {"b", opsmodels.Update}, {"c", opsmodels.Delete}} {
+			rn, s := graph.NewResourceNode(n.id, resource(n.id, "new"), n.action)
+			assert.Nil(t, s)
+			g.Add(rn)
+			g.Connect(dag.BasicEdge(prev, rn))
+			prev = rn
+			ao.recordApplied(rn)
+		}
+		return ao, g
+	}
+
+	t.Run("all rolled back", func(t *testing.T) {
+		rt := &rollbackRuntime{}
+		ao, g := newOperation(t, rt)
+		report := ao.rollback(g)
+		assert.Equal(t, []string{"c", "b", "a"}, report.RolledBack)
+		assert.Empty(t, report.Failed)
+		assert.Equal(t, []string{"apply c", "apply b", "delete a"}, rt.calls)
+		assert.Nil(t, ao.StateResourceIndex["a"])
+		assert.Equal(t, "old", ao.StateResourceIndex["b"].Attributes["v"])
+		assert.Equal(t, "old", ao.StateResourceIndex["c"].Attributes["v"])
+	})
+
+	t.Run("dependencies are kept if rollback fails", func(t *testing.T) {
+		rt := &rollbackRuntime{failID: "b"}
+		ao, g := newOperation(t, rt)
+		report := ao.rollback(g)
+		assert.Equal(t, []string{"c"}, report.RolledBack)
+		assert.ErrorContains(t, report.Failed["b"], "mock error")
+		assert.ErrorContains(t, report.Failed["a"], "resource b depending on it failed to roll back")
+		assert.Equal(t, []string{"apply c", "apply b"}, rt.calls)
+		assert.Contains(t, report.String(), "rolled back: c")
+	})
+}
@@ -0,0 +1,103 @@
+package operation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	"kusionstack.io/kusion/pkg/status"
+	"kusionstack.io/kusion/third_party/terraform/dag"
+)
+
+// MatchTargets reports whether the resource ID matches any of the targets. A target is either a resource ID
+// or a glob pattern, where `*` matches any sequence of characters and `?` matches any single character
+func MatchTargets(id string, targets []string) bool {
+	for _, t := range targets {
+		if targetRegexp(t).MatchString(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func targetRegexp(target string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range target {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// pruneGraph removes resource nodes that are not required by targets from the graph. Resources matching targets
+// are kept together with all resources that must be operated before them, which are dependencies in an apply
+// graph and dependents in a destroy graph. An error is returned if any target doesn't match a resource
+func pruneGraph(g *dag.AcyclicGraph, targets []string) status.Status {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	patterns := make(map[string]*regexp.Regexp, len(targets))
+	for _, t := range targets {
+		patterns[t] = targetRegexp(t)
+	}
+	keep := make(dag.Set)
+	matched := make(map[string]bool)
+	for _, v := range g.Vertices() {
+		rn, ok := v.(*graph.ResourceNode)
+		if !ok {
+			continue
+		}
+		id := rn.Hashcode().(string)
+		hit := false
+		for t, p := range patterns {
+			if p.MatchString(id) {
+				matched[t] = true
+				hit = true
+			}
+		}
+		if !hit {
+			continue
+		}
+		keep.Add(v)
+		// Descendents walks up edges, which are the vertices this vertex waits for in the walk
+		upstream, err := g.Descendents(v)
+		if err != nil {
+			return status.NewErrorStatus(err)
+		}
+		for _, u := range upstream {
+			keep.Add(u)
+		}
+	}
+	for _, t := range targets {
+		if !matched[t] {
+			return status.NewErrorStatusWithMsg(status.InvalidArgument, fmt.Sprintf("target %s doesn't match any resource", t))
+		}
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return status.NewErrorStatus(err)
+	}
+	for _, v := range g.Vertices() {
+		if _, ok := v.(*graph.ResourceNode); ok && !keep.Include(v) {
+			g.Remove(v)
+		}
+	}
+	// keep all remaining nodes reachable from the root
+	for _, v := range g.Vertices() {
+		if v != root && g.UpEdges(v).Len() == 0 {
+			g.Connect(dag.BasicEdge(root, v))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package operation
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/engine/states"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+	"kusionstack.io/kusion/third_party/terraform/dag"
+)
+
+var targetResources = models.Resources{
+	{ID: "v1:Namespace:foo", Type: runtime.Kubernetes, Attributes: map[string]interface{}{}},
+	{ID: "apps/v1:Deployment:foo:bar", Type: runtime.Kubernetes, Attributes: map[string]interface{}{}, DependsOn: []string{"v1:Namespace:foo"}},
+	{ID: "v1:Service:foo:bar", Type: runtime.Kubernetes, Attributes: map[string]interface{}{}, DependsOn: []string{"v1:Namespace:foo"}},
+	{ID: "v1:Namespace:other", Type: runtime.Kubernetes, Attributes: map[string]interface{}{}},
+}
+
+func resourceIDs(g *dag.AcyclicGraph) []string {
+	var ids []string
+	for _, v := range g.Vertices() {
+		if rn, ok := v.(*graph.ResourceNode); ok {
+			ids = append(ids, rn.Hashcode().(string))
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestMatchTargets(t *testing.T) {
+	assert.True(t, MatchTargets("v1:Namespace:foo", []string{"v1:Namespace:foo"}))
+	assert.True(t, MatchTargets("apps/v1:Deployment:foo:bar", []string{"*:Deployment:*"}))
+	assert.True(t, MatchTargets("v1:Namespace:foo", []string{"none", "v1:Namespace:fo?"}))
+	assert.False(t, MatchTargets("v1:Namespace:foo", []string{"v1:Namespace"}))
+	assert.False(t, MatchTargets("v1:Namespace:foo", []string{"v1.Namespace.foo"}))
+	assert.False(t, MatchTargets("v1:Namespace:foo", nil))
+}
+
+func TestPruneGraph(t *testing.T) {
+	t.Run("apply includes dependencies", func(t *testing.T) {
+		g, s := NewApplyGraph(&models.Spec{Resources: targetResources}, &states.State{})
+		assert.Nil(t, s)
+		s = pruneGraph(g, []string{"apps/v1:Deployment:*"})
+		assert.Nil(t, s)
+		assert.Equal(t, []string{"apps/v1:Deployment:foo:bar", "v1:Namespace:foo"}, resourceIDs(g))
+		_, err := g.Root()
+		assert.NoError(t, err)
+	})
+
+	t.Run("destroy includes dependents", func(t *testing.T) {
+		g, s := NewDestroyGraph(targetResources)
+		assert.Nil(t, s)
+		s = pruneGraph(g, []string{"v1:Namespace:foo"})
+		assert.Nil(t, s)
+		assert.Equal(t, []string{"apps/v1:Deployment:foo:bar", "v1:Namespace:foo", "v1:Service:foo:bar"}, resourceIDs(g))
+		_, err := g.Root()
+		assert.NoError(t, err)
+	})
+
+	t.Run("no targets", func(t *testing.T) {
+		g, s := NewDestroyGraph(targetResources)
+		assert.Nil(t, s)
+		assert.Nil(t, pruneGraph(g, nil))
+		assert.Len(t, resourceIDs(g), len(targetResources))
+	})
+
+	t.Run("unmatched target", func(t *testing.T) {
+		g, s := NewApplyGraph(&models.Spec{Resources: targetResources}, &states.State{})
+		assert.Nil(t, s)
+		s = pruneGraph(g, []string{"v1:Namespace:foo", "v1:ConfigMap:*"})
+		assert.True(t, status.IsErr(s))
+		assert.Contains(t, s.Message(), "v1:ConfigMap:*")
+	})
+}
@@ -0,0 +1,142 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/status"
+	"kusionstack.io/kusion/third_party/terraform/dag"
+	"kusionstack.io/kusion/third_party/terraform/tfdiags"
+)
+
+// limitWalkFun wraps the walk function of an operation to honor its Parallelism, FailurePolicy and interruption.
+// Only resource nodes are limited, and a new wrapper should be created for each DAG walk.
+func limitWalkFun(o *opsmodels.Operation, walkFun dag.WalkFunc) dag.WalkFunc {
+	var sem chan struct{}
+	if o.Parallelism > 0 {
+		sem = make(chan struct{}, o.Parallelism)
+	}
+	var failed int32
+	var lock sync.Mutex
+
+	return func(v dag.Vertex) tfdiags.Diagnostics {
+		rn, ok := v.(*graph.ResourceNode)
+		if !ok {
+			return walkFun(v)
+		}
+
+		if sem != nil {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+		}
+
+		// resources not started are skipped once the operation is interrupted, and the walk goes on to let every
+		// resource be recorded as not attempted
+		if o.Interrupted() {
+			id := rn.Hashcode().(string)
+			log.Infof("skip resource %s because the operation is interrupted", id)
+			lock.Lock()
+			o.NotAttempted = append(o.NotAttempted, id)
+			lock.Unlock()
+			if o.MsgCh != nil {
+				o.MsgCh <- opsmodels.Message{ResourceID: id, OpResult: opsmodels.Skip}
+			}
+			return nil
+		}
+
+		if o.FailurePolicy == opsmodels.FailFast && atomic.LoadInt32(&failed) == 1 {
+			id := rn.Hashcode().(string)
+			log.Infof("skip resource %s because another resource failed", id)
+			lock.Lock()
+			o.NotAttempted = append(o.NotAttempted, id)
+			lock.Unlock()
+			if o.MsgCh != nil {
+				o.MsgCh <- opsmodels.Message{ResourceID: id, OpResult: opsmodels.Skip}
+			}
+			return nil
+		}
+
+		diags := walkFun(v)
+		if diags.HasErrors() {
+			atomic.StoreInt32(&failed, 1)
+		}
+		return diags
+	}
+}
+
+// walkResources walks the graph with the walk function limited by limitWalkFun, and waits until the walk completes.
+// The walker never calls back resources depending on failed ones, so resources not called back are recorded as not
+// attempted and skipped once the walk completes
+func walkResources(o *opsmodels.Operation, g *dag.AcyclicGraph, walkFun dag.WalkFunc) tfdiags.Diagnostics {
+	var lock sync.Mutex
+	calledBack := map[string]bool{}
+	limited := limitWalkFun(o, walkFun)
+	w := &dag.Walker{Callback: func(v dag.Vertex) tfdiags.Diagnostics {
+		if rn, ok := v.(*graph.ResourceNode); ok {
+			lock.Lock()
+			calledBack[rn.Hashcode().(string)] = true
+			lock.Unlock()
+		}
+		return limited(v)
+	}}
+	w.Update(g)
+	diags := w.Wait()
+
+	var ids []string
+	for _, v := range g.Vertices() {
+		if rn, ok := v.(*graph.ResourceNode); ok && !calledBack[rn.Hashcode().(string)] {
+			ids = append(ids, rn.Hashcode().(string))
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		log.Infof("skip resource %s because resources it depends on failed", id)
+		o.NotAttempted = append(o.NotAttempted, id)
+		if o.MsgCh != nil {
+			o.MsgCh <- opsmodels.Message{ResourceID: id, OpResult: opsmodels.Skip}
+		}
+	}
+	return diags
+}
+
+// interruptedStatus returns an error status with a summary of resources never attempted if the operation is
+// interrupted before all resources complete. It returns nil if the operation is not affected by an interruption
+func interruptedStatus(o *opsmodels.Operation, diags tfdiags.Diagnostics) status.Status {
+	if !o.Interrupted() || (len(o.NotAttempted) == 0 && !diags.HasErrors()) {
+		return nil
+	}
+	ids := append([]string{}, o.NotAttempted...)
+	sort.Strings(ids)
+	notAttempted := "<none>"
+	if len(ids) != 0 {
+		notAttempted = strings.Join(ids, ", ")
+	}
+	msg := fmt.Sprintf("%s is interrupted", o.OperationType)
+	if o.OperationType == opsmodels.Apply || o.OperationType == opsmodels.Destroy {
+		msg += ", and resources completed have been saved in the State"
+	}
+	msg += fmt.Sprintf(".\n%d resources were never attempted: %s", len(ids), notAttempted)
+	if diags.HasErrors() {
+		return status.NewErrorStatus(fmt.Errorf("%s\n%w", msg, diags.Err()))
+	}
+	return status.NewErrorStatus(errors.New(msg))
+}
+
+// retryPolicy returns the retry policy of the operation, or the one configured in its stack if it is not set
+func retryPolicy(o *opsmodels.Operation) (*opsmodels.RetryPolicy, status.Status) {
+	if o.RetryPolicy != nil {
+		return o.RetryPolicy, nil
+	}
+	p, err := opsmodels.NewRetryPolicy(o.Stack)
+	if err != nil {
+		return nil, status.NewErrorStatusWithCode(status.InvalidArgument, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,146 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"kusionstack.io/kusion/pkg/engine/operation/graph"
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/status"
+	"kusionstack.io/kusion/third_party/terraform/dag"
+	"kusionstack.io/kusion/third_party/terraform/tfdiags"
+)
+
+// newWalkGraph returns a graph with a root node and two independent chains: a -> b and c -> d
+func newWalkGraph(t *testing.T) *dag.AcyclicGraph {
+	g := &dag.AcyclicGraph{}
+	root := &graph.RootNode{}
+	g.Add(root)
+	nodes := map[string]*graph.ResourceNode{}
+	for _, key := range []string{"a", "b", "c", "d"} {
+		rn, s := graph.NewResourceNode(key, nil, opsmodels.Create)
+		assert.Nil(t, s)
+		nodes[key] = rn
+		g.Add(rn)
+	}
+	g.Connect(dag.BasicEdge(root, nodes["a"]))
+	g.Connect(dag.BasicEdge(root, nodes["c"]))
+	g.Connect(dag.BasicEdge(nodes["a"], nodes["b"]))
+	g.Connect(dag.BasicEdge(nodes["c"], nodes["d"]))
+	return g
+}
+
+func TestLimitWalkFun(t *testing.T) {
+	t.Run("parallelism", func(t *testing.T) {
+		var running, maxRunning int32
+		o := &opsmodels.Operation{Parallelism: 1}
+		w := &dag.Walker{Callback: limitWalkFun(o, func(v dag.Vertex) tfdiags.Diagnostics {
+			if _, ok := v.(*graph.ResourceNode); ok {
+				n := atomic.AddInt32(&running, 1)
+				if n > atomic.LoadInt32(&maxRunning) {
+					atomic.StoreInt32(&maxRunning, n)
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			}
+			return nil
+		})}
+		w.Update(newWalkGraph(t))
+		assert.False(t, w.Wait().HasErrors())
+		assert.Equal(t, int32(1), maxRunning)
+	})
+
+	walk := func(policy opsmodels.FailurePolicy) ([]string, []string, []string) {
+		var lock sync.Mutex
+		var operated, skipped []string
+		o := &opsmodels.Operation{
+			Parallelism:   1,
+			FailurePolicy: policy,
+			MsgCh:         make(chan opsmodels.Message, 4),
+		}
+		diags := walkResources(o, newWalkGraph(t), func(v dag.Vertex) tfdiags.Diagnostics {
+			var diags tfdiags.Diagnostics
+			rn, ok := v.(*graph.ResourceNode)
+			if !ok {
+				return diags
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			operated = append(operated, rn.Hashcode().(string))
+			if rn.Hashcode() == "a" || rn.Hashcode() == "c" {
+				return diags.Append(errors.New("mock error"))
+			}
+			return diags
+		})
+		assert.True(t, diags.HasErrors())
+		close(o.MsgCh)
+		for msg := range o.MsgCh {
+			assert.Equal(t, opsmodels.Skip, msg.OpResult)
+			skipped = append(skipped, msg.ResourceID)
+		}
+		return operated, skipped, o.NotAttempted
+	}
+
+	t.Run("continue on failure", func(t *testing.T) {
+		operated, skipped, notAttempted := walk(opsmodels.ContinueOnFailure)
+		assert.ElementsMatch(t, []string{"a", "c"}, operated)
+		// resources depending on failed ones are never attempted
+		assert.Equal(t, []string{"b", "d"}, skipped)
+		assert.Equal(t, []string{"b", "d"}, notAttempted)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		operated, skipped, notAttempted := walk(opsmodels.FailFast)
+		// only one of a and c is operated, and the other chain is skipped entirely
+		assert.Len(t, operated, 1)
+		assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, append(operated, skipped...))
+		assert.ElementsMatch(t, skipped, notAttempted)
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		o := &opsmodels.Operation{
+			OperationType: opsmodels.Apply,
+			Ctx:           ctx,
+			Parallelism:   1,
+			MsgCh:         make(chan opsmodels.Message, 4),
+		}
+		var operated []string
+		w := &dag.Walker{Callback: limitWalkFun(o, func(v dag.Vertex) tfdiags.Diagnostics {
+			if rn, ok := v.(*graph.ResourceNode); ok {
+				operated = append(operated, rn.Hashcode().(string))
+				// interrupted when operating a
+				if rn.Hashcode() == "a" {
+					cancel()
+				}
+			}
+			return nil
+		})}
+		w.Update(newWalkGraph(t))
+		diags := w.Wait()
+		assert.False(t, diags.HasErrors())
+		close(o.MsgCh)
+		assert.Len(t, o.MsgCh, len(o.NotAttempted))
+		assert.Len(t, append(operated, o.NotAttempted...), 4)
+
+		s := interruptedStatus(o, diags)
+		assert.True(t, status.IsErr(s))
+		assert.Contains(t, s.Message(), "Apply is interrupted, and resources completed have been saved in the State")
+		assert.Contains(t, s.Message(), fmt.Sprintf("%d resources were never attempted", len(o.NotAttempted)))
+	})
+
+	t.Run("not interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		// nothing is affected if the operation is interrupted after all resources complete
+		assert.Nil(t, interruptedStatus(&opsmodels.Operation{Ctx: ctx}, nil))
+		assert.Nil(t, interruptedStatus(&opsmodels.Operation{}, nil))
+	})
+}
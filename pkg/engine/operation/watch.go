@@ -18,6 +18,7 @@ import (
 	"kusionstack.io/kusion/pkg/engine/runtime"
 	runtimeinit "kusionstack.io/kusion/pkg/engine/runtime/init"
 	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
 	"kusionstack.io/kusion/pkg/status"
 	"kusionstack.io/kusion/pkg/util/pretty"
 )
@@ -36,7 +37,7 @@ func (wo *WatchOperation) Watch(req *WatchRequest) error {
 
 	// init runtimes
 	resources := req.Spec.Resources
-	runtimes, s := runtimeinit.Runtimes(resources)
+	runtimes, s := runtimeinit.Runtimes(resources, req.Project)
 	if status.IsErr(s) {
 		return errors.New(s.Message())
 	}
@@ -51,11 +52,11 @@ func (wo *WatchOperation) Watch(req *WatchRequest) error {
 		res := &resources[i]
 		t := res.Type
 
-		// Save id first, might have TF resources
+		// Save id first, might have unsupported resources
 		ids[i] = res.ResourceKey()
 
-		// Get watchers, only support k8s resources
-		resp := runtimes[t].Watch(ctx, &runtime.WatchRequest{Resource: res})
+		// Get watchers
+		resp := runtimes[t].Watch(ctx, &runtime.WatchRequest{Resource: res, Stack: req.Stack})
 		if resp == nil {
 			log.Debug("unsupported resource type: %s", t)
 			continue
@@ -74,6 +75,12 @@ func (wo *WatchOperation) Watch(req *WatchRequest) error {
 	writer.Start()
 	defer writer.Stop()
 
+	// Resource types
+	types := make(map[string]models.Type, len(ids))
+	for i := range resources {
+		types[resources[i].ResourceKey()] = resources[i].Type
+	}
+
 	// Table data
 	tables := make(map[string]*printers.Table, len(ids))
 	ticker := time.NewTicker(time.Second)
@@ -85,7 +92,7 @@ func (wo *WatchOperation) Watch(req *WatchRequest) error {
 	// Start go routine for each table
 	for _, id := range ids {
 		sw, ok := msgChs[id]
-		if !ok { // Terraform resource, skip
+		if !ok { // Unsupported resource, skip
 			continue
 		}
 		// New target table
@@ -93,7 +100,7 @@ func (wo *WatchOperation) Watch(req *WatchRequest) error {
 		// Save tables first
 		tables[id] = table
 		// Start watching resource
-		go func(id string, chs []<-chan k8swatch.Event, table *printers.Table) {
+		go func(id string, t models.Type, chs []<-chan k8swatch.Event, table *printers.Table) {
 			// Resources selects
 			cases := createSelectCases(chs)
 			// Default select
@@ -110,9 +117,15 @@ func (wo *WatchOperation) Watch(req *WatchRequest) error {
 				if recvOK {
 					e := recv.Interface().(k8swatch.Event)
 					o := e.Object.(*unstructured.Unstructured)
+					rowID := engine.BuildIDForKubernetes(o)
 					var detail string
 					var ready bool
-					if e.Type == k8swatch.Deleted {
+					if t != runtime.Kubernetes {
+						// runtimes other than Kubernetes, like Terraform and plugins, carry the progress message in the status
+						rowID = id
+						detail, _, _ = unstructured.NestedString(o.Object, "status", "message")
+						ready = e.Type == printers.READY
+					} else if e.Type == k8swatch.Deleted {
 						detail = fmt.Sprintf("%s has beed deleted", o.GetName())
 						ready = true
 					} else {
@@ -128,7 +141,7 @@ func (wo *WatchOperation) Watch(req *WatchRequest) error {
 
 					// Save watched msg
 					table.Update(
-						engine.BuildIDForKubernetes(o),
+						rowID,
 						printers.NewRow(e.Type, o.GetKind(), o.GetName(), detail))
 
 					// Write back
@@ -140,10 +153,10 @@ func (wo *WatchOperation) Watch(req *WatchRequest) error {
 					break
 				}
 			}
-		}(id, sw.Watchers, table)
+		}(id, types[id], sw.Watchers, table)
 	}
 
-	// No k8s resources
+	// No watchable resources
 	if len(tables) == 0 {
 		wo.printTables(writer, ids, tables)
 		return nil
@@ -178,8 +191,8 @@ func (wo *WatchOperation) printTables(w *uilive.Writer, ids []string, tables map
 
 		table, ok := tables[id]
 		if !ok {
-			// Terraform resource, leave a hint
-			_, _ = fmt.Fprintln(w, "Skip monitoring Terraform resources")
+			// Unsupported resource, leave a hint
+			_, _ = fmt.Fprintln(w, "Skip monitoring this resource")
 		} else {
 			// Print table
 			data := table.Print()
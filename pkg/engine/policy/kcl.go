@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	kcl "kcl-lang.io/kcl-go"
+)
+
+// InputOption is the name of the KCL option to read the input in policies
+const InputOption = "input"
+
+// kclOption is an option in the KCL settings file
+type kclOption struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// runKCL runs the KCL policy file with the input, and returns its top-level variables. The input is passed by a
+// temporary settings file readable by the owner only, which is removed once the policy is evaluated
+func runKCL(file string, input []byte) (map[string]interface{}, error) {
+	// JSON is valid YAML, so the settings file is written as JSON
+	settings, err := json.Marshal(map[string][]kclOption{
+		"kcl_options": {{Key: InputOption, Value: input}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	settingsFile, err := os.CreateTemp("", "kusion-policy-input-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(settingsFile.Name())
+	_, err = settingsFile.Write(settings)
+	if closeErr := settingsFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := kcl.RunFiles([]string{file},
+		kcl.WithWorkDir(filepath.Dir(file)),
+		kcl.WithSettings(settingsFile.Name()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	documents := result.Slice()
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	return documents[0], nil
+}
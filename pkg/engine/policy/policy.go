@@ -0,0 +1,229 @@
+// Package policy evaluates organization policies against the compiled Spec and the previewed changes of a stack
+// before they are applied.
+//
+// Policies are KCL files placed in the `policies` directory of the project. Each policy reads the input with
+// `option("input")`, which contains the project name, the stack name, the Spec and the ordered change steps, and
+// declares the top-level variables `deny` and `warn` as lists of messages. For example:
+//
+//	input = option("input")
+//	deny = ["can't delete Namespace ${c.id}" for c in input.changes if c.action == "Delete" and c.id.startswith("v1:Namespace:")]
+//
+// Any deny message blocks the operation, and warn messages are only displayed. Values in data and stringData of
+// Kubernetes Secrets are redacted from the input, so policies can only check their keys.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
+)
+
+const (
+	// Directory is the directory of policies in the project
+	Directory = "policies"
+
+	// FileExtension is the file extension of policies
+	FileExtension = ".k"
+
+	// RedactedValue replaces values of Secrets in the input
+	RedactedValue = "<redacted>"
+)
+
+// Level is the enforcement level of a policy result
+type Level string
+
+// Level values
+const (
+	Deny Level = "deny"
+	Warn Level = "warn"
+)
+
+// Input is the data evaluated by policies
+type Input struct {
+	Project string                  `json:"project"`
+	Stack   string                  `json:"stack"`
+	Spec    *models.Spec            `json:"spec"`
+	Changes []*opsmodels.ChangeStep `json:"changes"`
+}
+
+// NewInput builds the Input from the Spec and changes previewed for the stack
+func NewInput(spec *models.Spec, changes *opsmodels.Changes) *Input {
+	input := &Input{
+		Project: changes.Project().Name,
+		Stack:   changes.Stack().Name,
+		Spec:    spec,
+		Changes: []*opsmodels.ChangeStep{},
+	}
+	for _, key := range changes.StepKeys {
+		input.Changes = append(input.Changes, changes.ChangeSteps[key])
+	}
+	return input
+}
+
+// Result is a message returned by a policy
+type Result struct {
+	Policy  string `json:"policy"`
+	Level   Level  `json:"level"`
+	Message string `json:"message"`
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("[%s] %s", r.Policy, r.Message)
+}
+
+// Results are messages returned by all policies
+type Results []*Result
+
+// Denied returns results which block the operation
+func (rs Results) Denied() Results {
+	return rs.filter(Deny)
+}
+
+// Warnings returns results which are only displayed
+func (rs Results) Warnings() Results {
+	return rs.filter(Warn)
+}
+
+func (rs Results) filter(level Level) Results {
+	var filtered Results
+	for _, r := range rs {
+		if r.Level == level {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// Err returns an error containing all deny messages, and nil if nothing is denied
+func (rs Results) Err() error {
+	denied := rs.Denied()
+	if len(denied) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(denied))
+	for _, r := range denied {
+		msgs = append(msgs, r.String())
+	}
+	return fmt.Errorf("denied by policies:\n%s", strings.Join(msgs, "\n"))
+}
+
+// Evaluate evaluates all policies under the policy directory of the project against the input. Nothing is returned
+// if the project has no policy
+func Evaluate(projectPath string, input *Input) (Results, error) {
+	files, err := policyFiles(filepath.Join(projectPath, Directory))
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	data, err := marshalInput(input)
+	if err != nil {
+		return nil, fmt.Errorf("marshal policy input failed: %w", err)
+	}
+	var results Results
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), FileExtension)
+		log.Infof("evaluate policy %s", file)
+		values, err := runKCL(file, data)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate policy %s failed: %w", name, err)
+		}
+		for _, level := range []Level{Deny, Warn} {
+			msgs, err := messages(values[string(level)])
+			if err != nil {
+				return nil, fmt.Errorf("illegal %s in policy %s: %w", level, name, err)
+			}
+			for _, msg := range msgs {
+				results = append(results, &Result{Policy: name, Level: level, Message: msg})
+			}
+		}
+	}
+	return results, nil
+}
+
+// marshalInput marshals the input with values of Kubernetes Secrets redacted, since policies may come from anywhere
+// and don't need secrets to make decisions
+func marshalInput(input *Input) ([]byte, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	redactSecrets(value)
+	return json.Marshal(value)
+}
+
+// redactSecrets replaces values in data and stringData of all Kubernetes Secrets in the value with RedactedValue,
+// and keeps their keys
+func redactSecrets(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v["apiVersion"] == "v1" && v["kind"] == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				if data, ok := v[field].(map[string]interface{}); ok {
+					for key := range data {
+						data[key] = RedactedValue
+					}
+				}
+			}
+			return
+		}
+		for _, item := range v {
+			redactSecrets(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			redactSecrets(item)
+		}
+	}
+}
+
+// policyFiles returns all policy files in the directory sorted by name
+func policyFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read policy directory failed: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == FileExtension {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// messages converts the value of deny or warn into messages, which can be a string or a list of strings
+func messages(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		var msgs []string
+		for _, item := range v {
+			msg, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("message must be a string, but got %v", item)
+			}
+			msgs = append(msgs, msg)
+		}
+		return msgs, nil
+	default:
+		return nil, fmt.Errorf("must be a string or a list of strings, but got %v", value)
+	}
+}
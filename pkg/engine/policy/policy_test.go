@@ -0,0 +1,123 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+
+	opsmodels "kusionstack.io/kusion/pkg/engine/operation/models"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+func newProject(t *testing.T, policies ...string) string {
+	path := t.TempDir()
+	dir := filepath.Join(path, Directory)
+	assert.NoError(t, os.Mkdir(dir, 0o755))
+	for _, p := range policies {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, p), []byte(""), 0o600))
+	}
+	return path
+}
+
+func TestNewInput(t *testing.T) {
+	sp := &models.Spec{Resources: models.Resources{{ID: "v1:Namespace:default"}}}
+	changes := opsmodels.NewChanges(&projectstack.Project{ProjectConfiguration: projectstack.ProjectConfiguration{Name: "app"}},
+		&projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "prod"}},
+		&opsmodels.ChangeOrder{
+			StepKeys: []string{"v1:Namespace:default"},
+			ChangeSteps: map[string]*opsmodels.ChangeStep{
+				"v1:Namespace:default": {ID: "v1:Namespace:default", Action: opsmodels.Delete},
+			},
+		})
+
+	data, err := json.Marshal(NewInput(sp, changes))
+	assert.NoError(t, err)
+	var got map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "app", got["project"])
+	assert.Equal(t, "prod", got["stack"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "v1:Namespace:default", "action": "Delete"}}, got["changes"])
+}
+
+func TestMarshalInput(t *testing.T) {
+	secret := &models.Resource{ID: "v1:Secret:default:db", Attributes: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data":       map[string]interface{}{"password": "cGFzc3dvcmQ="},
+		"stringData": map[string]interface{}{"user": "admin"},
+	}}
+	input := &Input{
+		Spec:    &models.Spec{Resources: models.Resources{*secret}},
+		Changes: []*opsmodels.ChangeStep{{ID: secret.ID, Action: opsmodels.Create, To: secret}},
+	}
+
+	data, err := marshalInput(input)
+	assert.NoError(t, err)
+	var got Input
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, map[string]interface{}{"password": RedactedValue}, got.Spec.Resources[0].Attributes["data"])
+	assert.Equal(t, map[string]interface{}{"user": RedactedValue}, got.Spec.Resources[0].Attributes["stringData"])
+	assert.NotContains(t, string(data), "cGFzc3dvcmQ=")
+	assert.NotContains(t, string(data), "admin")
+	// the input itself is not changed
+	assert.Equal(t, "admin", secret.Attributes["stringData"].(map[string]interface{})["user"])
+}
+
+func TestEvaluate(t *testing.T) {
+	input := &Input{Project: "app", Stack: "prod"}
+
+	t.Run("no policy", func(t *testing.T) {
+		results, err := Evaluate(t.TempDir(), input)
+		assert.NoError(t, err)
+		assert.Nil(t, results)
+	})
+
+	mockey.PatchConvey("deny and warn", t, func() {
+		outputs := map[string]map[string]interface{}{
+			"limits.k":    {"warn": []interface{}{"container nginx has no resource limits"}},
+			"namespace.k": {"deny": "can't delete Namespace default", "input": map[string]interface{}{}},
+		}
+		mockey.Mock(runKCL).To(func(file string, data []byte) (map[string]interface{}, error) {
+			assert.JSONEq(t, `{"project":"app","stack":"prod","spec":null,"changes":null}`, string(data))
+			return outputs[filepath.Base(file)], nil
+		}).Build()
+
+		results, err := Evaluate(newProject(t, "namespace.k", "limits.k", "README.md"), input)
+		assert.NoError(t, err)
+		assert.Equal(t, Results{
+			{Policy: "limits", Level: Warn, Message: "container nginx has no resource limits"},
+			{Policy: "namespace", Level: Deny, Message: "can't delete Namespace default"},
+		}, results)
+		assert.Len(t, results.Warnings(), 1)
+		assert.EqualError(t, results.Err(), "denied by policies:\n[namespace] can't delete Namespace default")
+	})
+
+	mockey.PatchConvey("warn only", t, func() {
+		mockey.Mock(runKCL).Return(map[string]interface{}{"warn": []interface{}{"public Service port"}, "deny": []interface{}{}}, nil).Build()
+
+		results, err := Evaluate(newProject(t, "ports.k"), input)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.NoError(t, results.Err())
+	})
+
+	mockey.PatchConvey("illegal messages", t, func() {
+		mockey.Mock(runKCL).Return(map[string]interface{}{"deny": []interface{}{1}}, nil).Build()
+
+		_, err := Evaluate(newProject(t, "ports.k"), input)
+		assert.ErrorContains(t, err, "illegal deny in policy ports")
+	})
+
+	mockey.PatchConvey("evaluation failed", t, func() {
+		mockey.Mock(runKCL).Return(nil, errors.New("syntax error")).Build()
+
+		_, err := Evaluate(newProject(t, "ports.k"), input)
+		assert.EqualError(t, err, "evaluate policy ports failed: syntax error")
+	})
+}
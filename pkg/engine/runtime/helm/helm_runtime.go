@@ -0,0 +1,356 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+
+	"kusionstack.io/kusion/pkg/engine/printers"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/engine/runtime/kubernetes"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+var _ runtime.Runtime = (*HelmRuntime)(nil)
+
+var (
+	// defaultTimeout is the max time to wait for hooks of releases, and for objects of releases to be ready or
+	// deleted if the request doesn't specify one
+	defaultTimeout = 5 * time.Minute
+
+	// watchInterval is the interval to poll the status of releases when watching them
+	watchInterval = time.Second
+)
+
+// HelmRuntime installs, upgrades and uninstalls Helm releases with the Helm SDK. Releases are operated in the
+// Kubernetes cluster selected by the stack or the resource, like Kubernetes resources
+type HelmRuntime struct{}
+
+// NewHelmRuntime create a new HelmRuntime
+func NewHelmRuntime() (runtime.Runtime, error) {
+	return &HelmRuntime{}, nil
+}
+
+// Apply installs the release if it doesn't exist, otherwise upgrades it. A dry-run request renders the chart
+// without changing the release
+func (h *HelmRuntime) Apply(ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+	planState := request.PlanResource
+	if planState == nil {
+		return &runtime.ApplyResponse{Status: status.NewErrorStatus(errors.New("plan state is nil"))}
+	}
+	spec, err := releaseOf(planState)
+	if err != nil {
+		return &runtime.ApplyResponse{Status: status.NewErrorStatusWithCode(status.IllegalManifest, err)}
+	}
+	cfg, settings, err := configurationOf(planState, spec.Namespace, request.Stack)
+	if err != nil {
+		return &runtime.ApplyResponse{Status: kubernetes.ErrorStatus(err)}
+	}
+	live, err := lastRelease(cfg, spec.Name)
+	if err != nil {
+		return &runtime.ApplyResponse{Status: kubernetes.ErrorStatus(err)}
+	}
+
+	timeout := request.ReadinessTimeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	var rel *release.Release
+	if live == nil {
+		install := action.NewInstall(cfg)
+		install.ReleaseName = spec.Name
+		install.Namespace = spec.Namespace
+		install.CreateNamespace = spec.CreateNamespace
+		install.DryRun = request.DryRun
+		install.Wait = request.ReadinessTimeout > 0
+		install.Timeout = timeout
+		var chrt *chart.Chart
+		if chrt, err = loadChart(spec, &install.ChartPathOptions, settings, request.Stack); err == nil {
+			rel, err = install.RunWithContext(ctx, chrt, spec.Values)
+		}
+	} else {
+		upgrade := action.NewUpgrade(cfg)
+		upgrade.Namespace = spec.Namespace
+		upgrade.DryRun = request.DryRun
+		upgrade.Wait = request.ReadinessTimeout > 0
+		upgrade.Timeout = timeout
+		var chrt *chart.Chart
+		if chrt, err = loadChart(spec, &upgrade.ChartPathOptions, settings, request.Stack); err == nil {
+			rel, err = upgrade.RunWithContext(ctx, spec.Name, chrt, spec.Values)
+		}
+	}
+	if err != nil {
+		return &runtime.ApplyResponse{Status: kubernetes.ErrorStatus(err)}
+	}
+
+	attributes, err := spec.attributes(rel)
+	if err != nil {
+		return &runtime.ApplyResponse{Status: status.NewErrorStatus(err)}
+	}
+	return &runtime.ApplyResponse{Resource: &models.Resource{
+		ID:         planState.ResourceKey(),
+		Type:       planState.Type,
+		Attributes: attributes,
+		DependsOn:  planState.DependsOn,
+		Extensions: planState.Extensions,
+	}}
+}
+
+// Read the latest release
+func (h *HelmRuntime) Read(ctx context.Context, request *runtime.ReadRequest) *runtime.ReadResponse {
+	requestResource := request.PlanResource
+	if requestResource == nil {
+		requestResource = request.PriorResource
+	}
+	if requestResource == nil {
+		return &runtime.ReadResponse{Status: status.NewErrorStatus(errors.New("can not read Helm release with empty body"))}
+	}
+	spec, err := releaseOf(requestResource)
+	if err != nil {
+		return &runtime.ReadResponse{Status: status.NewErrorStatusWithCode(status.IllegalManifest, err)}
+	}
+	cfg, _, err := configurationOf(requestResource, spec.Namespace, request.Stack)
+	if err != nil {
+		return &runtime.ReadResponse{Status: kubernetes.ErrorStatus(err)}
+	}
+	rel, err := lastRelease(cfg, spec.Name)
+	if err != nil {
+		return &runtime.ReadResponse{Status: kubernetes.ErrorStatus(err)}
+	}
+	if rel == nil {
+		log.Infof("release %s not found, ignore", spec.Name)
+		return &runtime.ReadResponse{}
+	}
+
+	attributes, err := spec.attributes(rel)
+	if err != nil {
+		return &runtime.ReadResponse{Status: status.NewErrorStatus(err)}
+	}
+	return &runtime.ReadResponse{Resource: &models.Resource{
+		ID:         requestResource.ResourceKey(),
+		Type:       requestResource.Type,
+		Attributes: attributes,
+		DependsOn:  requestResource.DependsOn,
+		Extensions: requestResource.Extensions,
+	}}
+}
+
+// Import the release already installed
+func (h *HelmRuntime) Import(ctx context.Context, request *runtime.ImportRequest) *runtime.ImportResponse {
+	response := h.Read(ctx, &runtime.ReadRequest{PlanResource: request.PlanResource, Stack: request.Stack})
+	if status.IsErr(response.Status) {
+		return &runtime.ImportResponse{Status: response.Status}
+	}
+	if response.Resource == nil {
+		return &runtime.ImportResponse{
+			Status: status.NewErrorStatus(fmt.Errorf("can not find the release of %s", request.PlanResource.ResourceKey())),
+		}
+	}
+	return &runtime.ImportResponse{Resource: response.Resource}
+}
+
+// Delete uninstalls the release
+func (h *HelmRuntime) Delete(ctx context.Context, request *runtime.DeleteRequest) *runtime.DeleteResponse {
+	if request.Resource == nil {
+		return &runtime.DeleteResponse{Status: status.NewErrorStatus(errors.New("requestResource is nil"))}
+	}
+	spec, err := releaseOf(request.Resource)
+	if err != nil {
+		return &runtime.DeleteResponse{Status: status.NewErrorStatusWithCode(status.IllegalManifest, err)}
+	}
+	cfg, _, err := configurationOf(request.Resource, spec.Namespace, request.Stack)
+	if err != nil {
+		return &runtime.DeleteResponse{Status: kubernetes.ErrorStatus(err)}
+	}
+
+	uninstall := action.NewUninstall(cfg)
+	uninstall.Timeout = defaultTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < uninstall.Timeout {
+		uninstall.Timeout = time.Until(deadline)
+	}
+	if err = uninstallWithContext(ctx, uninstall, spec.Name); err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			log.Infof("release %s not found, ignore", spec.Name)
+			return &runtime.DeleteResponse{}
+		}
+		return &runtime.DeleteResponse{Status: kubernetes.ErrorStatus(err)}
+	}
+	return &runtime.DeleteResponse{}
+}
+
+// uninstallWithContext runs the uninstall action, and returns the error of ctx once it is done like RunWithContext
+// of the install and upgrade actions, which leaves the action running in the background
+func uninstallWithContext(ctx context.Context, uninstall *action.Uninstall, name string) error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := uninstall.Run(name)
+		errCh <- err
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("uninstall release %s interrupted: %w", name, ctx.Err())
+	}
+}
+
+// Watch polls the status of the release until it is deployed or failed
+func (h *HelmRuntime) Watch(ctx context.Context, request *runtime.WatchRequest) *runtime.WatchResponse {
+	if request == nil || request.Resource == nil {
+		return &runtime.WatchResponse{Status: status.NewErrorStatus(errors.New("requestResource is nil"))}
+	}
+	resource := request.Resource
+	spec, err := releaseOf(resource)
+	if err != nil {
+		return &runtime.WatchResponse{Status: status.NewErrorStatusWithCode(status.IllegalManifest, err)}
+	}
+	cfg, _, err := configurationOf(resource, spec.Namespace, request.Stack)
+	if err != nil {
+		return &runtime.WatchResponse{Status: kubernetes.ErrorStatus(err)}
+	}
+
+	eventCh := make(chan k8swatch.Event)
+	go func() {
+		defer close(eventCh)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			if rel, err := lastRelease(cfg, spec.Name); err != nil {
+				log.Warnf("failed to watch release %s: %v", spec.Name, err)
+			} else if rel != nil {
+				e := watchEvent(spec, rel)
+				select {
+				case eventCh <- e:
+				case <-ctx.Done():
+					return
+				}
+				if e.Type == printers.READY {
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	watchers := runtime.NewWatchers()
+	watchers.Insert(resource.ResourceKey(), eventCh)
+	return &runtime.WatchResponse{Watchers: watchers}
+}
+
+// watchEvent converts the status of the release to a watch event. Releases deployed or failed are marked as
+// printers.READY
+func watchEvent(spec *Release, rel *release.Release) k8swatch.Event {
+	eventType := k8swatch.Modified
+	message := rel.Info.Status.String()
+	switch rel.Info.Status {
+	case release.StatusDeployed:
+		eventType = printers.READY
+	case release.StatusFailed:
+		eventType = printers.READY
+		message = "Error: " + rel.Info.Description
+	}
+	return k8swatch.Event{
+		Type: eventType,
+		Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":     "Release",
+			"metadata": map[string]interface{}{"name": spec.Name, "namespace": spec.Namespace},
+			"status":   map[string]interface{}{"message": fmt.Sprintf("%s, revision %d", message, rel.Version)},
+		}},
+	}
+}
+
+// configurationOf returns the Helm action configuration of the namespace in the cluster the resource lives in
+func configurationOf(resource *models.Resource, namespace string, stack *projectstack.Stack) (*action.Configuration, *cli.EnvSettings, error) {
+	cluster, err := kubernetes.ClusterOf(resource, stack)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings := cli.New()
+	settings.KubeConfig = kubernetes.KubeConfigPath(cluster, stack)
+	settings.KubeContext = cluster.Context
+	settings.SetNamespace(namespace)
+
+	cfg, err := newConfiguration(settings, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, settings, nil
+}
+
+// newConfiguration initializes the Helm action configuration with the storage driver from $HELM_DRIVER
+func newConfiguration(settings *cli.EnvSettings, namespace string) (*action.Configuration, error) {
+	cfg := &action.Configuration{}
+	if err := cfg.Init(settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), log.Debugf); err != nil {
+		return nil, err
+	}
+	registryClient, err := registry.NewClient(registry.ClientOptCredentialsFile(settings.RegistryConfig))
+	if err != nil {
+		return nil, err
+	}
+	cfg.RegistryClient = registryClient
+	return cfg, nil
+}
+
+// lastRelease returns the last revision of the release, and nil if it is not installed
+func lastRelease(cfg *action.Configuration, name string) (*release.Release, error) {
+	rel, err := cfg.Releases.Last(name)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if rel.Info != nil && rel.Info.Status == release.StatusUninstalled {
+		return nil, nil
+	}
+	return rel, nil
+}
+
+// loadChart locates the chart of the release, downloading it from the repo if needed, and loads it
+func loadChart(spec *Release, options *action.ChartPathOptions, settings *cli.EnvSettings, stack *projectstack.Stack) (*chart.Chart, error) {
+	name := spec.Chart
+	if spec.Repo == "" && !registry.IsOCI(name) && !filepath.IsAbs(name) && stack != nil && stack.GetPath() != "" {
+		if path := filepath.Join(stack.GetPath(), name); fileExists(path) {
+			name = path
+		}
+	}
+	options.RepoURL = spec.Repo
+	options.Version = spec.Version
+	path, err := options.LocateChart(name, settings)
+	if err != nil {
+		return nil, err
+	}
+	chrt, err := loader.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = action.CheckDependencies(chrt, chrt.Metadata.Dependencies); err != nil {
+		return nil, fmt.Errorf("dependencies of chart %s are missing: %v", spec.Chart, err)
+	}
+	return chrt, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
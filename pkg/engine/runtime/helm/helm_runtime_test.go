@@ -0,0 +1,204 @@
+package helm
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+
+	"kusionstack.io/kusion/pkg/engine/printers"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+func newRelease(replicas int) *models.Resource {
+	return &models.Resource{
+		ID:   "helm:web:nginx",
+		Type: runtime.Helm,
+		Attributes: map[string]interface{}{
+			"name":      "nginx",
+			"namespace": "web",
+			"chart":     "nginx",
+			"values":    map[string]interface{}{"replicaCount": replicas},
+		},
+	}
+}
+
+// mockCluster serves releases in memory, and prints objects instead of applying them to a cluster
+func mockCluster() *action.Configuration {
+	cfg := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(string, ...interface{}) {},
+	}
+	mockey.Mock(newConfiguration).To(func(settings *cli.EnvSettings, namespace string) (*action.Configuration, error) {
+		return cfg, nil
+	}).Build()
+	return cfg
+}
+
+func replicasOf(t *testing.T, resource *models.Resource) interface{} {
+	manifests, ok := resource.Attributes["manifests"].(map[string]interface{})
+	require.True(t, ok)
+	deployment, ok := manifests["apps/v1:Deployment:web:nginx"].(map[string]interface{})
+	require.True(t, ok)
+	return deployment["spec"].(map[string]interface{})["replicas"]
+}
+
+func TestHelmRuntime(t *testing.T) {
+	stack := &projectstack.Stack{Path: mustAbs(t, "testdata")}
+	ctx := context.TODO()
+
+	mockey.PatchConvey("install, upgrade and uninstall releases", t, func() {
+		cfg := mockCluster()
+		h, err := NewHelmRuntime()
+		require.NoError(t, err)
+
+		// not installed
+		readRsp := h.Read(ctx, &runtime.ReadRequest{PlanResource: newRelease(1), Stack: stack})
+		require.Nil(t, readRsp.Status)
+		assert.Nil(t, readRsp.Resource)
+
+		// install
+		applyRsp := h.Apply(ctx, &runtime.ApplyRequest{PlanResource: newRelease(1), Stack: stack})
+		require.Nil(t, applyRsp.Status)
+		assert.Equal(t, "0.1.0", applyRsp.Resource.Attributes["version"])
+		assert.Equal(t, float64(1), replicasOf(t, applyRsp.Resource))
+		assert.Contains(t, applyRsp.Resource.Attributes["manifests"], "v1:Service:web:nginx")
+
+		readRsp = h.Read(ctx, &runtime.ReadRequest{PlanResource: newRelease(1), Stack: stack})
+		require.Nil(t, readRsp.Status)
+		assert.Equal(t, applyRsp.Resource.Attributes, readRsp.Resource.Attributes)
+
+		// preview the upgrade with rendered objects, and the release is not changed
+		dryRunRsp := h.Apply(ctx, &runtime.ApplyRequest{PlanResource: newRelease(3), Stack: stack, DryRun: true})
+		require.Nil(t, dryRunRsp.Status)
+		assert.Equal(t, float64(3), replicasOf(t, dryRunRsp.Resource))
+		rel, err := cfg.Releases.Last("nginx")
+		require.NoError(t, err)
+		assert.Equal(t, 1, rel.Version)
+
+		// upgrade
+		applyRsp = h.Apply(ctx, &runtime.ApplyRequest{PlanResource: newRelease(3), Stack: stack})
+		require.Nil(t, applyRsp.Status)
+		assert.Equal(t, float64(3), replicasOf(t, applyRsp.Resource))
+		rel, err = cfg.Releases.Last("nginx")
+		require.NoError(t, err)
+		assert.Equal(t, 2, rel.Version)
+		assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+
+		// watch until deployed
+		watchRsp := h.Watch(ctx, &runtime.WatchRequest{Resource: newRelease(3), Stack: stack})
+		require.Nil(t, watchRsp.Status)
+		e := <-watchRsp.Watchers.Watchers[0]
+		assert.Equal(t, printers.READY, e.Type)
+
+		// uninstall
+		deleteRsp := h.Delete(ctx, &runtime.DeleteRequest{Resource: newRelease(3), Stack: stack})
+		require.Nil(t, deleteRsp.Status)
+		readRsp = h.Read(ctx, &runtime.ReadRequest{PlanResource: newRelease(3), Stack: stack})
+		require.Nil(t, readRsp.Status)
+		assert.Nil(t, readRsp.Resource)
+
+		// uninstalling again is ignored
+		deleteRsp = h.Delete(ctx, &runtime.DeleteRequest{Resource: newRelease(3), Stack: stack})
+		assert.Nil(t, deleteRsp.Status)
+	})
+}
+
+func TestHelmRuntime_IllegalRelease(t *testing.T) {
+	h := &HelmRuntime{}
+	resource := &models.Resource{ID: "helm:web:nginx", Type: runtime.Helm, Attributes: map[string]interface{}{"name": "nginx"}}
+	rsp := h.Apply(context.TODO(), &runtime.ApplyRequest{PlanResource: resource})
+	assert.Equal(t, status.IllegalManifest, rsp.Status.Code())
+	assert.Contains(t, rsp.Status.Message(), "no chart in Helm resource helm:web:nginx")
+}
+
+func TestHelmRuntime_Errors(t *testing.T) {
+	stack := &projectstack.Stack{Path: mustAbs(t, "testdata")}
+	h := &HelmRuntime{}
+
+	mockey.PatchConvey("throttled by the cluster", t, func() {
+		mockCluster()
+		mockey.Mock(lastRelease).To(func(cfg *action.Configuration, name string) (*release.Release, error) {
+			return nil, k8serrors.NewTooManyRequests("too many requests", 1)
+		}).Build()
+
+		rsp := h.Read(context.TODO(), &runtime.ReadRequest{PlanResource: newRelease(1), Stack: stack})
+		assert.Equal(t, status.Throttled, rsp.Status.Code())
+	})
+
+	mockey.PatchConvey("uninstalling is canceled", t, func() {
+		mockCluster()
+		running := make(chan struct{})
+		mockey.Mock((*action.Uninstall).Run).To(func(u *action.Uninstall, name string) (*release.UninstallReleaseResponse, error) {
+			close(running)
+			select {}
+		}).Build()
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		go func() {
+			<-running
+			cancel()
+		}()
+		rsp := h.Delete(ctx, &runtime.DeleteRequest{Resource: newRelease(1), Stack: stack})
+		assert.Equal(t, status.Canceled, rsp.Status.Code())
+	})
+}
+
+func TestWatchEvent(t *testing.T) {
+	spec := &Release{Name: "nginx", Namespace: "web"}
+	rel := &release.Release{Version: 2, Info: &release.Info{Status: release.StatusPendingUpgrade}}
+	e := watchEvent(spec, rel)
+	assert.Equal(t, k8swatch.Modified, e.Type)
+
+	rel.Info = &release.Info{Status: release.StatusFailed, Description: "timed out"}
+	e = watchEvent(spec, rel)
+	assert.Equal(t, printers.READY, e.Type)
+}
+
+func TestParseManifests(t *testing.T) {
+	manifests, err := parseManifests(`---
+# Source: nginx/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: web
+---
+# Source: nginx/templates/empty.yaml
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"v1:Service:web:nginx": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": "nginx", "namespace": "web"},
+		},
+	}, manifests)
+
+	_, err = parseManifests("metadata:\n  name: nginx\n")
+	assert.Error(t, err)
+}
+
+func mustAbs(t *testing.T, path string) string {
+	abs, err := filepath.Abs(path)
+	require.NoError(t, err)
+	return abs
+}
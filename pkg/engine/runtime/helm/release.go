@@ -0,0 +1,132 @@
+package helm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"kusionstack.io/kusion/pkg/engine"
+	"kusionstack.io/kusion/pkg/models"
+)
+
+// defaultNamespace is the namespace of releases without one
+const defaultNamespace = "default"
+
+// Release is the desired state of a Helm release described by Attributes of Helm resources, like
+//
+//	{
+//	  "name": "nginx",
+//	  "namespace": "web",
+//	  "chart": "nginx",
+//	  "repo": "https://charts.bitnami.com/bitnami",
+//	  "version": "15.0.0",
+//	  "values": {"replicaCount": 2}
+//	}
+type Release struct {
+	// Name is the name of the release
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the release. Default to "default"
+	Namespace string `json:"namespace,omitempty"`
+
+	// CreateNamespace creates the namespace of the release if it doesn't exist when installing the release
+	CreateNamespace bool `json:"createNamespace,omitempty"`
+
+	// Chart is the chart name in the repo, a reference like "oci://registry/charts/nginx", or the path of a local
+	// chart directory or archive. Relative paths are relative to the stack directory
+	Chart string `json:"chart"`
+
+	// Repo is the URL of the chart repository
+	Repo string `json:"repo,omitempty"`
+
+	// Version is the version constraint of the chart. The latest version is used if it is empty
+	Version string `json:"version,omitempty"`
+
+	// Values are values of the chart
+	Values map[string]interface{} `json:"values,omitempty"`
+
+	// Manifests are objects rendered from the chart keyed by their IDs, which are filled by the runtime so that
+	// changes of rendered objects are previewed
+	Manifests map[string]interface{} `json:"manifests,omitempty"`
+}
+
+// releaseOf returns the Release described by the resource
+func releaseOf(resource *models.Resource) (*Release, error) {
+	data, err := json.Marshal(resource.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	r := &Release{}
+	if err = json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("invalid Helm release of resource %s: %v", resource.ID, err)
+	}
+	if r.Name == "" {
+		return nil, fmt.Errorf("no release name in Helm resource %s", resource.ID)
+	}
+	if r.Chart == "" {
+		return nil, fmt.Errorf("no chart in Helm resource %s", resource.ID)
+	}
+	if r.Namespace == "" {
+		r.Namespace = defaultNamespace
+	}
+	return r, nil
+}
+
+// attributes returns Attributes of the resource with the released chart version, values and rendered objects
+func (r *Release) attributes(rel *release.Release) (map[string]interface{}, error) {
+	out := *r
+	out.Namespace = rel.Namespace
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		out.Version = rel.Chart.Metadata.Version
+	}
+	out.Values = rel.Config
+	manifests, err := parseManifests(rel.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	out.Manifests = manifests
+
+	// Convert to values decoded from JSON, which are comparable with prior and live Attributes
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	attributes := map[string]interface{}{}
+	if err = json.Unmarshal(data, &attributes); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// parseManifests splits the rendered manifest of a release into objects keyed by their IDs
+func parseManifests(manifest string) (map[string]interface{}, error) {
+	docs := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(docs))
+	for k := range docs {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	manifests := map[string]interface{}{}
+	for _, k := range keys {
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(docs[k]), &obj); err != nil {
+			return nil, fmt.Errorf("invalid manifest rendered: %v", err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: obj}
+		if u.GetKind() == "" || u.GetName() == "" {
+			return nil, errors.New("invalid manifest rendered: no kind or name in object")
+		}
+		manifests[engine.BuildIDForKubernetes(u)] = obj
+	}
+	return manifests, nil
+}
@@ -2,24 +2,29 @@ package init
 
 import (
 	"fmt"
-	"reflect"
+	"sort"
 
 	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/engine/runtime/helm"
 	"kusionstack.io/kusion/pkg/engine/runtime/kubernetes"
+	"kusionstack.io/kusion/pkg/engine/runtime/plugin"
 	"kusionstack.io/kusion/pkg/engine/runtime/terraform"
 	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
 	"kusionstack.io/kusion/pkg/status"
 )
 
 var SupportRuntimes = map[models.Type]InitFn{
 	runtime.Kubernetes: kubernetes.NewKubernetesRuntime,
 	runtime.Terraform:  terraform.NewTerraformRuntime,
+	runtime.Helm:       helm.NewHelmRuntime,
 }
 
 // InitFn runtime init func
 type InitFn func() (runtime.Runtime, error)
 
-func Runtimes(resources models.Resources) (map[models.Type]runtime.Runtime, status.Status) {
+// Runtimes returns runtimes of resource types of the resources, where plugins configured by the project are found
+func Runtimes(resources models.Resources, project *projectstack.Project) (map[models.Type]runtime.Runtime, status.Status) {
 	runtimesMap := map[models.Type]runtime.Runtime{}
 	if resources == nil {
 		return runtimesMap, nil
@@ -31,17 +36,58 @@ func Runtimes(resources models.Resources) (map[models.Type]runtime.Runtime, stat
 			return nil, status.NewErrorStatusWithCode(status.IllegalManifest, fmt.Errorf("no resource type in resource: %v", resource.ID))
 		}
 
-		if SupportRuntimes[rt] == nil {
-			return nil, status.NewErrorStatusWithCode(status.IllegalManifest, fmt.Errorf("unknow resource type: %s. Currently supported resource types are: %v",
-				rt, reflect.ValueOf(SupportRuntimes).MapKeys()))
-		} else if runtimesMap[rt] == nil {
-			r, err := SupportRuntimes[rt]()
-			if err != nil {
-				return nil, status.NewErrorStatus(fmt.Errorf("init %s runtime failed. %w", rt, err))
-			}
-			runtimesMap[rt] = r
+		if runtimesMap[rt] != nil {
+			continue
 		}
+		initFn, s := runtimeInitFn(rt, project)
+		if status.IsErr(s) {
+			return nil, s
+		}
+		r, err := initFn()
+		if err != nil {
+			return nil, status.NewErrorStatus(fmt.Errorf("init %s runtime failed. %w", rt, err))
+		}
+		runtimesMap[rt] = r
 	}
 
 	return runtimesMap, nil
 }
+
+// runtimeInitFn returns the InitFn of the resource type, which is one of SupportRuntimes or a plugin found in the
+// plugins directory configured by the project or the one in the Kusion data folder
+func runtimeInitFn(rt models.Type, project *projectstack.Project) (InitFn, status.Status) {
+	if initFn := SupportRuntimes[rt]; initFn != nil {
+		return initFn, nil
+	}
+	dirs, err := plugin.Dirs(project)
+	if err != nil {
+		return nil, status.NewErrorStatus(err)
+	}
+	plugins := map[models.Type]string{}
+	// plugins in directories in front take precedence
+	for i := len(dirs) - 1; i >= 0; i-- {
+		found, err := plugin.Discover(dirs[i])
+		if err != nil {
+			return nil, status.NewErrorStatus(err)
+		}
+		for t, path := range found {
+			plugins[t] = path
+		}
+	}
+	if path, ok := plugins[rt]; ok {
+		return func() (runtime.Runtime, error) {
+			return plugin.Start(rt, path)
+		}, nil
+	}
+
+	var types []string
+	for t := range SupportRuntimes {
+		types = append(types, string(t))
+	}
+	for t := range plugins {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	return nil, status.NewErrorStatusWithCode(status.IllegalManifest, fmt.Errorf("unknow resource type: %s. Currently supported resource types are: %v",
+		rt, types))
+}
@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"path/filepath"
+
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/util/kube/config"
+)
+
+// ClusterOf returns the cluster of the resource. Settings in Extensions of the resource override those of the stack
+func ClusterOf(resource *models.Resource, stack *projectstack.Stack) (*models.Cluster, error) {
+	var cluster *models.Cluster
+	if stack != nil {
+		cluster = stack.Cluster
+	}
+	if resource == nil {
+		return cluster.Override(nil), nil
+	}
+	rc, err := resource.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	return cluster.Override(rc), nil
+}
+
+// KubeConfigPath returns the absolute path of the kubeconfig file of the cluster
+func KubeConfigPath(cluster *models.Cluster, stack *projectstack.Stack) string {
+	path := cluster.KubeConfig
+	if path == "" {
+		return config.GetKubeConfig()
+	}
+	if !filepath.IsAbs(path) && stack != nil && stack.GetPath() != "" {
+		path = filepath.Join(stack.GetPath(), path)
+	}
+	return path
+}
+
+// forCluster returns the runtime of the cluster the resource lives in, and clients of each cluster are created once.
+// Runtimes of a specific cluster return themselves
+func (k *KubernetesRuntime) forCluster(resource *models.Resource, stack *projectstack.Stack) (*KubernetesRuntime, error) {
+	if k.clusters == nil {
+		return k, nil
+	}
+	cluster, err := ClusterOf(resource, stack)
+	if err != nil {
+		return nil, err
+	}
+	path := KubeConfigPath(cluster, stack)
+	key := path + "#" + cluster.Context
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if rt, ok := k.clusters[key]; ok {
+		return rt, nil
+	}
+	client, mapper, err := getKubernetesClient(path, cluster.Context)
+	if err != nil {
+		return nil, err
+	}
+	rt := &KubernetesRuntime{client: client, mapper: mapper, cluster: cluster}
+	k.clusters[key] = rt
+	return rt, nil
+}
+
+// extensions returns Extensions of the resource operated by this runtime, where the cluster is recorded unless it
+// is the default one, so that the resource can be found after the cluster of the stack is changed
+func (k *KubernetesRuntime) extensions(resource *models.Resource) map[string]interface{} {
+	if k.cluster.IsZero() {
+		return resource.Extensions
+	}
+	r := &models.Resource{Extensions: map[string]interface{}{}}
+	for key, v := range resource.Extensions {
+		r.Extensions[key] = v
+	}
+	r.SetCluster(k.cluster)
+	return r.Extensions
+}
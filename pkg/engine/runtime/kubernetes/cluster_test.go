@@ -0,0 +1,110 @@
+package kubernetes
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/util/kube/config"
+)
+
+func TestClusterOf(t *testing.T) {
+	stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{
+		Cluster: &models.Cluster{KubeConfig: "kubeconfig", Context: "prod"},
+	}}
+	tests := map[string]struct {
+		resource *models.Resource
+		stack    *projectstack.Stack
+		want     *models.Cluster
+	}{
+		"default": {
+			resource: &models.Resource{ID: "a"},
+			want:     &models.Cluster{},
+		},
+		"stack": {
+			resource: &models.Resource{ID: "a"},
+			stack:    stack,
+			want:     &models.Cluster{KubeConfig: "kubeconfig", Context: "prod"},
+		},
+		"resource overrides stack": {
+			resource: &models.Resource{ID: "a", Extensions: map[string]interface{}{
+				models.ClusterKey: map[string]interface{}{"context": "staging"},
+			}},
+			stack: stack,
+			want:  &models.Cluster{KubeConfig: "kubeconfig", Context: "staging"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ClusterOf(tc.resource, tc.stack)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestKubeConfigPath(t *testing.T) {
+	stack := &projectstack.Stack{Path: "/project/prod"}
+	assert.Equal(t, config.GetKubeConfig(), KubeConfigPath(&models.Cluster{}, stack))
+	assert.Equal(t, filepath.Join("/project/prod", "kubeconfig"), KubeConfigPath(&models.Cluster{KubeConfig: "kubeconfig"}, stack))
+	assert.Equal(t, "/etc/kubeconfig", KubeConfigPath(&models.Cluster{KubeConfig: "/etc/kubeconfig"}, stack))
+}
+
+func TestKubernetesRuntime_MultiCluster(t *testing.T) {
+	newConfigMap := func(data string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "foo", "namespace": "default"},
+			"data":       map[string]interface{}{"cluster": data},
+		}}
+	}
+	newResource := func(kubeContext string) *models.Resource {
+		r := &models.Resource{ID: "v1:ConfigMap:default:foo", Type: runtime.Kubernetes, Attributes: newConfigMap("").Object}
+		if kubeContext != "" {
+			r.SetCluster(&models.Cluster{Context: kubeContext})
+		}
+		return r
+	}
+
+	mockey.PatchConvey("dispatch requests to clusters", t, func() {
+		created := map[string]int{}
+		mockey.Mock(getKubernetesClient).To(func(kubeConfig, kubeContext string) (dynamic.Interface, meta.RESTMapper, error) {
+			created[kubeContext]++
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+			return fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), newConfigMap(kubeContext)), mapper, nil
+		}).Build()
+
+		k, err := NewKubernetesRuntime()
+		require.NoError(t, err)
+		for _, kubeContext := range []string{"a", "b", "a", ""} {
+			rsp := k.Read(context.TODO(), &runtime.ReadRequest{PlanResource: newResource(kubeContext)})
+			require.Nil(t, rsp.Status)
+			data, _, _ := unstructured.NestedString(rsp.Resource.Attributes, "data", "cluster")
+			assert.Equal(t, kubeContext, data)
+
+			cluster, err := rsp.Resource.Cluster()
+			assert.NoError(t, err)
+			if kubeContext == "" {
+				assert.Nil(t, cluster, "the default cluster is not recorded")
+			} else {
+				assert.Equal(t, &models.Cluster{Context: kubeContext}, cluster)
+			}
+		}
+		assert.Equal(t, map[string]int{"a": 1, "b": 1, "": 1}, created)
+	})
+}
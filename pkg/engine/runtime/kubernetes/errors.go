@@ -0,0 +1,27 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+
+	"kusionstack.io/kusion/pkg/status"
+)
+
+// ErrorStatus converts the error to a status, whose code tells transient errors of the API server, such as
+// throttling and temporary unavailability, from others
+func ErrorStatus(err error) status.Status {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.NewErrorStatusWithCode(status.Canceled, err)
+	case k8serrors.IsTooManyRequests(err):
+		return status.NewErrorStatusWithCode(status.Throttled, err)
+	case k8serrors.IsServiceUnavailable(err), k8serrors.IsServerTimeout(err), k8serrors.IsTimeout(err),
+		utilnet.IsConnectionReset(err), utilnet.IsProbableEOF(err):
+		return status.NewErrorStatusWithCode(status.Unavailable, err)
+	default:
+		return status.NewErrorStatus(err)
+	}
+}
@@ -0,0 +1,36 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"kusionstack.io/kusion/pkg/status"
+)
+
+func TestErrorStatus(t *testing.T) {
+	gr := schema.GroupResource{Resource: "deployments"}
+	tests := map[string]struct {
+		err  error
+		want status.Code
+	}{
+		"too many requests":   {err: k8serrors.NewTooManyRequests("slow down", 1), want: status.Throttled},
+		"service unavailable": {err: k8serrors.NewServiceUnavailable("unavailable"), want: status.Unavailable},
+		"server timeout":      {err: k8serrors.NewServerTimeout(gr, "create", 1), want: status.Unavailable},
+		"canceled":            {err: fmt.Errorf("apply failed: %w", context.Canceled), want: status.Canceled},
+		"not found":           {err: k8serrors.NewNotFound(gr, "nginx"), want: status.Internal},
+		"other":               {err: errors.New("invalid object"), want: status.Internal},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := ErrorStatus(tc.err)
+			assert.True(t, status.IsErr(s))
+			assert.Equal(t, tc.want, s.Code())
+		})
+	}
+}
@@ -3,6 +3,8 @@ package kubernetes
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	yamlv2 "gopkg.in/yaml.v2"
@@ -32,7 +34,6 @@ import (
 	"kusionstack.io/kusion/pkg/models"
 	"kusionstack.io/kusion/pkg/status"
 	jsonutil "kusionstack.io/kusion/pkg/util/json"
-	"kusionstack.io/kusion/pkg/util/kube/config"
 )
 
 var _ runtime.Runtime = (*KubernetesRuntime)(nil)
@@ -40,18 +41,21 @@ var _ runtime.Runtime = (*KubernetesRuntime)(nil)
 type KubernetesRuntime struct {
 	client dynamic.Interface
 	mapper meta.RESTMapper
+
+	// cluster is the cluster operated by this runtime, which is selected by the stack or the resource
+	cluster *models.Cluster
+
+	// clusters contains runtimes of each cluster operated, keyed by the kubeconfig path and the context. Requests
+	// are dispatched to the runtime of the cluster their resources live in if it is not nil
+	clusters map[string]*KubernetesRuntime
+	lock     sync.Mutex
 }
 
-// NewKubernetesRuntime create a new KubernetesRuntime
+// NewKubernetesRuntime create a new KubernetesRuntime. Clients of a cluster are created when resources in the cluster
+// are operated for the first time
 func NewKubernetesRuntime() (runtime.Runtime, error) {
-	client, mapper, err := getKubernetesClient()
-	if err != nil {
-		return nil, err
-	}
-
 	return &KubernetesRuntime{
-		client: client,
-		mapper: mapper,
+		clusters: map[string]*KubernetesRuntime{},
 	}, nil
 }
 
@@ -64,15 +68,20 @@ func (k *KubernetesRuntime) Apply(ctx context.Context, request *runtime.ApplyReq
 	if planState == nil {
 		return &runtime.ApplyResponse{Status: status.NewErrorStatus(errors.New("plan state is nil"))}
 	}
+	if rt, err := k.forCluster(planState, request.Stack); err != nil {
+		return &runtime.ApplyResponse{Status: ErrorStatus(err)}
+	} else if rt != k {
+		return rt.Apply(ctx, request)
+	}
 
 	// Get kubernetes Resource interface from plan state
 	planObj, resource, err := k.buildKubernetesResourceByState(planState)
 	if err != nil {
-		return &runtime.ApplyResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.ApplyResponse{Status: ErrorStatus(err)}
 	}
 
 	// Get live state
-	response := k.Read(ctx, &runtime.ReadRequest{PlanResource: planState})
+	response := k.Read(ctx, &runtime.ReadRequest{PlanResource: planState, Stack: request.Stack})
 	if status.IsErr(response.Status) {
 		return &runtime.ApplyResponse{Status: response.Status}
 	}
@@ -94,13 +103,27 @@ func (k *KubernetesRuntime) Apply(ctx context.Context, request *runtime.ApplyReq
 	// Create 3-way merge patch body
 	patchBody, err := jsonmergepatch.CreateThreeWayJSONMergePatch([]byte(original), []byte(modified), []byte(current))
 	if err != nil {
-		return &runtime.ApplyResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.ApplyResponse{Status: ErrorStatus(err)}
+	}
+
+	// Server-side apply is used instead of patching if it is enabled by the stack
+	fieldManager := serverSideFieldManager(request.Stack)
+	var liveObj *unstructured.Unstructured
+	if liveState != nil {
+		liveObj = &unstructured.Unstructured{Object: liveState.Attributes}
 	}
 
 	// Final result, dry-run to diff, otherwise to save in states
 	var res *unstructured.Unstructured
+	var requiresReplace bool
+	var conflicts []string
 	if request.DryRun {
-		if liveState == nil {
+		if fieldManager != "" {
+			res, requiresReplace, conflicts, err = serverSideDryRun(ctx, planObj, liveObj, resource, fieldManager)
+			if err != nil {
+				return &runtime.ApplyResponse{Status: ErrorStatus(err)}
+			}
+		} else if liveState == nil {
 			// Try ServerSideDryRun first
 			createOptions := metav1.CreateOptions{
 				DryRun: []string{metav1.DryRunAll},
@@ -122,35 +145,64 @@ func (k *KubernetesRuntime) Apply(ctx context.Context, request *runtime.ApplyReq
 			if patchedObj, err := resource.Patch(ctx, planObj.GetName(), types.MergePatchType, patchBody, patchOptions); err == nil {
 				res = patchedObj
 			} else {
+				// Changes on immutable fields can only be applied by replacing the object
+				requiresReplace = isImmutableFieldError(err)
+
 				// Fall back to ClientSideDryRun
 				log.Errorf("ServerSideDryRun patch %s failed, fall back to ClientSideDryRun; err: %v", planState.ID, err)
 
 				// Merge 3-way patch
 				mergedPatch, err := jsonpatch.MergePatch([]byte(current), patchBody)
 				if err != nil {
-					return &runtime.ApplyResponse{Status: status.NewErrorStatus(err)}
+					return &runtime.ApplyResponse{Status: ErrorStatus(err)}
 				}
 
 				// Unmarshall and return
 				res = &unstructured.Unstructured{}
 				if err = res.UnmarshalJSON(mergedPatch); err != nil {
-					return &runtime.ApplyResponse{Status: status.NewErrorStatus(err)}
+					return &runtime.ApplyResponse{Status: ErrorStatus(err)}
 				}
 			}
 		}
+		// Fail the preview if the object can't be replaced, instead of failing after it is confirmed
+		if requiresReplace {
+			if err = checkReplace(planState); err != nil {
+				return &runtime.ApplyResponse{Status: ErrorStatus(err)}
+			}
+		}
 	} else {
-		if liveState == nil {
+		var deleted bool
+		if request.Replace && liveState != nil {
+			// Delete the live object first, since objects with the same name can't coexist
+			deleted, err = k.replace(ctx, planState, planObj, resource)
+		} else if fieldManager != "" {
+			err = serverSideApply(ctx, planObj, liveObj, resource, fieldManager, request.ForceConflicts)
+		} else if liveState == nil {
 			// LiveState is nil, fall back to create planObj
 			_, err = resource.Create(ctx, planObj, metav1.CreateOptions{})
 		} else {
 			// LiveState isn't nil, continue to patch liveObj
-			_, err = resource.Patch(ctx, planObj.GetName(), types.MergePatchType, patchBody, metav1.PatchOptions{FieldManager: "kusion"})
+			_, err = resource.Patch(ctx, planObj.GetName(), types.MergePatchType, patchBody, metav1.PatchOptions{FieldManager: clientSideFieldManager})
 		}
 		if err != nil {
-			return &runtime.ApplyResponse{Status: status.NewErrorStatus(err)}
+			return &runtime.ApplyResponse{Deleted: deleted, Status: ErrorStatus(err)}
 		}
 		// Save modified
 		res = planObj
+
+		// Wait for the applied object to be ready, and return the applied resource together with the error
+		// since the object has been changed anyway
+		if request.ReadinessTimeout > 0 {
+			if err = k.waitReady(ctx, planObj, resource, request.ReadinessTimeout); err != nil {
+				return &runtime.ApplyResponse{Resource: &models.Resource{
+					ID:         planState.ResourceKey(),
+					Type:       planState.Type,
+					Attributes: res.Object,
+					DependsOn:  planState.DependsOn,
+					Extensions: k.extensions(planState),
+				}, Status: status.NewErrorStatus(err)}
+			}
+		}
 	}
 
 	return &runtime.ApplyResponse{Resource: &models.Resource{
@@ -158,8 +210,8 @@ func (k *KubernetesRuntime) Apply(ctx context.Context, request *runtime.ApplyReq
 		Type:       planState.Type,
 		Attributes: res.Object,
 		DependsOn:  planState.DependsOn,
-		Extensions: planState.Extensions,
-	}}
+		Extensions: k.extensions(planState),
+	}, RequiresReplace: requiresReplace, Conflicts: conflicts}
 }
 
 // Read kubernetes Resource by client-go
@@ -172,6 +224,11 @@ func (k *KubernetesRuntime) Read(ctx context.Context, request *runtime.ReadReque
 	if requestResource == nil {
 		return &runtime.ReadResponse{Status: status.NewErrorStatus(errors.New("can not read k8s resource with empty body"))}
 	}
+	if rt, err := k.forCluster(requestResource, request.Stack); err != nil {
+		return &runtime.ReadResponse{Status: ErrorStatus(err)}
+	} else if rt != k {
+		return rt.Read(ctx, request)
+	}
 
 	// Get resource by attribute
 	obj, resource, err := k.buildKubernetesResourceByState(requestResource)
@@ -181,7 +238,7 @@ func (k *KubernetesRuntime) Read(ctx context.Context, request *runtime.ReadReque
 			log.Infof("%v, ignore", err)
 			return &runtime.ReadResponse{}
 		}
-		return &runtime.ReadResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.ReadResponse{Status: ErrorStatus(err)}
 	}
 
 	// Read resource
@@ -191,7 +248,7 @@ func (k *KubernetesRuntime) Read(ctx context.Context, request *runtime.ReadReque
 			log.Infof("%s not found, ignore", requestResource.ResourceKey())
 			return &runtime.ReadResponse{}
 		}
-		return &runtime.ReadResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.ReadResponse{Status: ErrorStatus(err)}
 	}
 
 	return &runtime.ReadResponse{Resource: &models.Resource{
@@ -199,7 +256,7 @@ func (k *KubernetesRuntime) Read(ctx context.Context, request *runtime.ReadReque
 		Type:       requestResource.Type,
 		Attributes: v.Object,
 		DependsOn:  requestResource.DependsOn,
-		Extensions: requestResource.Extensions,
+		Extensions: k.extensions(requestResource),
 	}}
 }
 
@@ -216,6 +273,12 @@ func (k *KubernetesRuntime) Import(ctx context.Context, request *runtime.ImportR
 			Status:   response.Status,
 		}
 	}
+	if response.Resource == nil {
+		return &runtime.ImportResponse{
+			Resource: nil,
+			Status:   status.NewErrorStatus(fmt.Errorf("can not find %s in the cluster", request.PlanResource.ResourceKey())),
+		}
+	}
 
 	// clean up resource to make it looks like last-applied-config
 	ur := &unstructured.Unstructured{Object: response.Resource.Attributes}
@@ -282,11 +345,16 @@ func (k *KubernetesRuntime) Delete(ctx context.Context, request *runtime.DeleteR
 	if requestResource == nil {
 		return &runtime.DeleteResponse{Status: status.NewErrorStatus(errors.New("requestResource is nil"))}
 	}
+	if rt, err := k.forCluster(requestResource, request.Stack); err != nil {
+		return &runtime.DeleteResponse{Status: ErrorStatus(err)}
+	} else if rt != k {
+		return rt.Delete(ctx, request)
+	}
 
 	// Get Resource by attribute
 	obj, resource, err := k.buildKubernetesResourceByState(requestResource)
 	if err != nil {
-		return &runtime.DeleteResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.DeleteResponse{Status: ErrorStatus(err)}
 	}
 
 	// Delete Resource
@@ -296,7 +364,7 @@ func (k *KubernetesRuntime) Delete(ctx context.Context, request *runtime.DeleteR
 			log.Infof("%s not found, ignore", requestResource.ResourceKey())
 			return &runtime.DeleteResponse{}
 		}
-		return &runtime.DeleteResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.DeleteResponse{Status: ErrorStatus(err)}
 	}
 
 	return &runtime.DeleteResponse{}
@@ -307,16 +375,21 @@ func (k *KubernetesRuntime) Watch(ctx context.Context, request *runtime.WatchReq
 	if request == nil || request.Resource == nil {
 		return &runtime.WatchResponse{Status: status.NewErrorStatus(errors.New("requestResource is nil"))}
 	}
+	if rt, err := k.forCluster(request.Resource, request.Stack); err != nil {
+		return &runtime.WatchResponse{Status: ErrorStatus(err)}
+	} else if rt != k {
+		return rt.Watch(ctx, request)
+	}
 
 	reqObj, resource, err := k.buildKubernetesResourceByState(request.Resource)
 	if err != nil {
-		return &runtime.WatchResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.WatchResponse{Status: ErrorStatus(err)}
 	}
 
 	// Root watcher
 	w, err := resource.Watch(ctx, metav1.ListOptions{})
 	if err != nil {
-		return &runtime.WatchResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.WatchResponse{Status: ErrorStatus(err)}
 	}
 	rootCh := doWatch(ctx, w, func(watched *unstructured.Unstructured) bool {
 		return watched.GetName() == reqObj.GetName()
@@ -336,14 +409,14 @@ func (k *KubernetesRuntime) Watch(ctx context.Context, request *runtime.WatchReq
 			namedGVK := getNamedGVK(reqObj.GroupVersionKind())
 			ch, dependent, err := k.WatchByRelation(ctx, reqObj, namedGVK, namedBy)
 			if err != nil {
-				return &runtime.WatchResponse{Status: status.NewErrorStatus(err)}
+				return &runtime.WatchResponse{Status: ErrorStatus(err)}
 			}
 			watchers.Insert(engine.BuildIDForKubernetes(dependent), ch)
 		} else { // Watch EndpointSlice
 			dependentGVK := getDependentGVK(reqObj.GroupVersionKind())
 			ch, dependent, err := k.WatchByRelation(ctx, reqObj, dependentGVK, ownedBy)
 			if err != nil {
-				return &runtime.WatchResponse{Status: status.NewErrorStatus(err)}
+				return &runtime.WatchResponse{Status: ErrorStatus(err)}
 			}
 			watchers.Insert(engine.BuildIDForKubernetes(dependent), ch)
 		}
@@ -355,7 +428,7 @@ func (k *KubernetesRuntime) Watch(ctx context.Context, request *runtime.WatchReq
 			for !dependentGVK.Empty() {
 				ch, dependent, err := k.WatchByRelation(ctx, owner, dependentGVK, ownedBy)
 				if err != nil {
-					return &runtime.WatchResponse{Status: status.NewErrorStatus(err)}
+					return &runtime.WatchResponse{Status: ErrorStatus(err)}
 				}
 
 				if dependent == nil {
@@ -374,10 +447,14 @@ func (k *KubernetesRuntime) Watch(ctx context.Context, request *runtime.WatchReq
 	return &runtime.WatchResponse{Watchers: watchers}
 }
 
-// getKubernetesClient get kubernetes client
-func getKubernetesClient() (dynamic.Interface, meta.RESTMapper, error) {
+// getKubernetesClient get kubernetes client of the context in the kubeconfig file. The current context is used if
+// kubeContext is empty
+func getKubernetesClient(kubeConfig, kubeContext string) (dynamic.Interface, meta.RESTMapper, error) {
 	// build config
-	cfg, err := clientcmd.BuildConfigFromFlags("", config.GetKubeConfig())
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeConfig},
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	).ClientConfig()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,235 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"kusionstack.io/kusion/pkg/log"
+)
+
+// readinessInterval is the interval to poll the live object when waiting for it to be ready
+var readinessInterval = 2 * time.Second
+
+// readinessChecker evaluates whether the live object is ready, and returns a detail message.
+// A non-nil error means the object will never be ready without another change, such as a failed rollout
+type readinessChecker func(obj *unstructured.Unstructured) (bool, string, error)
+
+var readinessCheckers = map[schema.GroupKind]readinessChecker{
+	{Group: "apps", Kind: "Deployment"}:              deploymentReady,
+	{Group: "apps", Kind: "StatefulSet"}:             statefulSetReady,
+	{Group: "apps", Kind: "DaemonSet"}:               daemonSetReady,
+	{Group: "batch", Kind: "Job"}:                    jobReady,
+	{Group: "", Kind: "Pod"}:                         podReady,
+	{Group: "", Kind: "Service"}:                     serviceReady,
+	{Group: "apps.kusionstack.io", Kind: "CollaSet"}: collaSetReady,
+}
+
+// waitReady polls the live object until it is ready according to its kind. Objects of kinds without a checker are
+// ready once applied. An error is returned if the object fails, or it is not ready before the timeout
+func (k *KubernetesRuntime) waitReady(ctx context.Context, obj *unstructured.Unstructured, resource dynamic.ResourceInterface, timeout time.Duration) error {
+	gvk := obj.GroupVersionKind()
+	checker, ok := readinessCheckers[gvk.GroupKind()]
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+
+	detail := "not observed yet"
+	for {
+		live, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if err == nil {
+			var ready bool
+			ready, detail, err = checker(live)
+			if err != nil {
+				return fmt.Errorf("%s %s failed: %v", gvk.Kind, obj.GetName(), err)
+			}
+			if ready && gvk.Kind == "Service" {
+				ready, detail = k.endpointsReady(ctx, live)
+			}
+			if ready {
+				log.Infof("%s %s is ready: %s", gvk.Kind, obj.GetName(), detail)
+				return nil
+			}
+		} else if ctx.Err() == nil {
+			log.Errorf("get %s %s failed when waiting for it to be ready: %v", gvk.Kind, obj.GetName(), err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s %s to be ready after %s, last status: %s", gvk.Kind, obj.GetName(), timeout, detail)
+		}
+	}
+}
+
+// endpointsReady checks whether the Service has at least one ready address. Services without selectors are ready directly
+func (k *KubernetesRuntime) endpointsReady(ctx context.Context, svc *unstructured.Unstructured) (bool, string) {
+	selector, _, _ := unstructured.NestedMap(svc.Object, "spec", "selector")
+	svcType, _, _ := unstructured.NestedString(svc.Object, "spec", "type")
+	if len(selector) == 0 || svcType == "ExternalName" {
+		return true, "no selector"
+	}
+
+	gvk := &schema.GroupVersionKind{Version: "v1", Kind: "Endpoints"}
+	resource, err := buildDynamicResource(k.client, k.mapper, gvk, svc.GetNamespace())
+	if err != nil {
+		return false, err.Error()
+	}
+	ep, err := resource.Get(ctx, svc.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Sprintf("get endpoints failed: %v", err)
+	}
+	return readyAddresses(ep)
+}
+
+func readyAddresses(ep *unstructured.Unstructured) (bool, string) {
+	subsets, _, _ := unstructured.NestedSlice(ep.Object, "subsets")
+	count := 0
+	for _, s := range subsets {
+		subset, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		addresses, _, _ := unstructured.NestedSlice(subset, "addresses")
+		count += len(addresses)
+	}
+	return count > 0, fmt.Sprintf("Ready endpoints: %d", count)
+}
+
+func deploymentReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if !generationObserved(obj) {
+		return false, "waiting for the rollout to be observed", nil
+	}
+	if c := findCondition(obj, "Progressing"); c != nil && c["reason"] == "ProgressDeadlineExceeded" {
+		return false, "", fmt.Errorf("rollout exceeded its progress deadline: %v", c["message"])
+	}
+	replicas := int64Field(obj, 1, "spec", "replicas")
+	updated := int64Field(obj, 0, "status", "updatedReplicas")
+	current := int64Field(obj, 0, "status", "replicas")
+	available := int64Field(obj, 0, "status", "availableReplicas")
+	detail := fmt.Sprintf("Desired: %d, Updated: %d, Available: %d", replicas, updated, available)
+	return updated == replicas && current == replicas && available == replicas, detail, nil
+}
+
+func statefulSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if !generationObserved(obj) {
+		return false, "waiting for the rollout to be observed", nil
+	}
+	replicas := int64Field(obj, 1, "spec", "replicas")
+	ready := int64Field(obj, 0, "status", "readyReplicas")
+	updated := int64Field(obj, 0, "status", "updatedReplicas")
+	detail := fmt.Sprintf("Desired: %d, Updated: %d, Ready: %d", replicas, updated, ready)
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+	if strategy == "OnDelete" {
+		return ready == replicas, detail, nil
+	}
+	currentRevision, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+	updateRevision, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+	return ready == replicas && updated == replicas && currentRevision == updateRevision, detail, nil
+}
+
+func daemonSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if !generationObserved(obj) {
+		return false, "waiting for the rollout to be observed", nil
+	}
+	desired := int64Field(obj, 0, "status", "desiredNumberScheduled")
+	updated := int64Field(obj, 0, "status", "updatedNumberScheduled")
+	available := int64Field(obj, 0, "status", "numberAvailable")
+	detail := fmt.Sprintf("Desired: %d, Updated: %d, Available: %d", desired, updated, available)
+	return updated == desired && available == desired, detail, nil
+}
+
+func jobReady(obj *unstructured.Unstructured) (bool, string, error) {
+	succeeded := int64Field(obj, 0, "status", "succeeded")
+	detail := fmt.Sprintf("Succeeded: %d", succeeded)
+	if c := findCondition(obj, "Failed"); c != nil && c["status"] == "True" {
+		return false, detail, fmt.Errorf("job failed: %v", c["message"])
+	}
+	if c := findCondition(obj, "Complete"); c != nil && c["status"] == "True" {
+		return true, detail, nil
+	}
+	return false, detail, nil
+}
+
+func podReady(obj *unstructured.Unstructured) (bool, string, error) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	detail := fmt.Sprintf("Phase: %s", phase)
+	switch phase {
+	case "Failed":
+		reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason")
+		return false, detail, fmt.Errorf("pod failed: %s", reason)
+	case "Succeeded":
+		return true, detail, nil
+	}
+	c := findCondition(obj, "Ready")
+	return c != nil && c["status"] == "True", detail, nil
+}
+
+// serviceReady checks whether a LoadBalancer Service has been assigned an ingress. Endpoints are checked by endpointsReady
+func serviceReady(obj *unstructured.Unstructured) (bool, string, error) {
+	svcType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+	if svcType != "LoadBalancer" {
+		return true, fmt.Sprintf("Type: %s", svcType), nil
+	}
+	ingress, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+	if len(ingress) == 0 {
+		return false, "waiting for the load balancer ingress", nil
+	}
+	return true, fmt.Sprintf("Type: %s, Ingress: %d", svcType, len(ingress)), nil
+}
+
+func collaSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if !generationObserved(obj) {
+		return false, "waiting for the rollout to be observed", nil
+	}
+	replicas := int64Field(obj, 0, "spec", "replicas")
+	updated := int64Field(obj, 0, "status", "updatedReplicas")
+	available := int64Field(obj, 0, "status", "updatedAvailableReplicas")
+	detail := fmt.Sprintf("Desired: %d, Updated: %d, UpdatedAvailable: %d", replicas, updated, available)
+	return updated == replicas && available == replicas, detail, nil
+}
+
+// generationObserved returns whether the controller has observed the latest spec of the object
+func generationObserved(obj *unstructured.Unstructured) bool {
+	observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	return found && observed >= obj.GetGeneration()
+}
+
+func findCondition(obj *unstructured.Unstructured, conditionType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionType {
+			return condition
+		}
+	}
+	return nil
+}
+
+func int64Field(obj *unstructured.Unstructured, defaultValue int64, fields ...string) int64 {
+	v, found, err := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+	if !found || err != nil {
+		return defaultValue
+	}
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case float64:
+		return int64(n)
+	}
+	return defaultValue
+}
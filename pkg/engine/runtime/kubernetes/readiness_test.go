@@ -0,0 +1,166 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newDeployment(generation, observed, replicas, updated, available int64, conditions ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":       "foo",
+			"namespace":  "default",
+			"generation": generation,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+		"status": map[string]interface{}{
+			"observedGeneration": observed,
+			"replicas":           updated,
+			"updatedReplicas":    updated,
+			"availableReplicas":  available,
+			"conditions":         conditions,
+		},
+	}}
+}
+
+func TestReadinessCheckers(t *testing.T) {
+	t.Run("deployment", func(t *testing.T) {
+		ready, _, err := deploymentReady(newDeployment(2, 1, 2, 2, 2))
+		assert.NoError(t, err)
+		assert.False(t, ready, "latest generation is not observed")
+
+		ready, detail, err := deploymentReady(newDeployment(2, 2, 2, 2, 1))
+		assert.NoError(t, err)
+		assert.False(t, ready)
+		assert.Equal(t, "Desired: 2, Updated: 2, Available: 1", detail)
+
+		ready, _, err = deploymentReady(newDeployment(2, 2, 2, 2, 2))
+		assert.NoError(t, err)
+		assert.True(t, ready)
+
+		_, _, err = deploymentReady(newDeployment(2, 2, 2, 1, 1, map[string]interface{}{
+			"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded",
+		}))
+		assert.Error(t, err)
+	})
+
+	t.Run("job", func(t *testing.T) {
+		job := func(conditionType string) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": conditionType, "status": "True", "message": "backoff limit exceeded"},
+					},
+				},
+			}}
+		}
+		ready, _, err := jobReady(job("Complete"))
+		assert.NoError(t, err)
+		assert.True(t, ready)
+
+		_, _, err = jobReady(job("Failed"))
+		assert.ErrorContains(t, err, "backoff limit exceeded")
+
+		ready, _, err = jobReady(&unstructured.Unstructured{Object: map[string]interface{}{}})
+		assert.NoError(t, err)
+		assert.False(t, ready)
+	})
+
+	t.Run("service", func(t *testing.T) {
+		svc := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"type": "LoadBalancer"},
+		}}
+		ready, _, _ := serviceReady(svc)
+		assert.False(t, ready)
+
+		_ = unstructured.SetNestedSlice(svc.Object, []interface{}{map[string]interface{}{"ip": "1.1.1.1"}}, "status", "loadBalancer", "ingress")
+		ready, _, _ = serviceReady(svc)
+		assert.True(t, ready)
+
+		ep := &unstructured.Unstructured{Object: map[string]interface{}{
+			"subsets": []interface{}{
+				map[string]interface{}{"notReadyAddresses": []interface{}{map[string]interface{}{"ip": "10.0.0.1"}}},
+			},
+		}}
+		ready, _ = readyAddresses(ep)
+		assert.False(t, ready)
+		ep.Object["subsets"] = []interface{}{
+			map[string]interface{}{"addresses": []interface{}{map[string]interface{}{"ip": "10.0.0.1"}}},
+		}
+		ready, detail := readyAddresses(ep)
+		assert.True(t, ready)
+		assert.Equal(t, "Ready endpoints: 1", detail)
+	})
+
+	t.Run("pod", func(t *testing.T) {
+		pod := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"phase": "Failed", "reason": "Evicted"},
+		}}
+		_, _, err := podReady(pod)
+		assert.ErrorContains(t, err, "Evicted")
+	})
+
+	t.Run("collaset", func(t *testing.T) {
+		cs := &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"generation": int64(1)},
+			"spec":     map[string]interface{}{"replicas": int64(3)},
+			"status": map[string]interface{}{
+				"observedGeneration":       int64(1),
+				"updatedReplicas":          int64(3),
+				"updatedAvailableReplicas": int64(3),
+			},
+		}}
+		ready, _, err := collaSetReady(cs)
+		assert.NoError(t, err)
+		assert.True(t, ready)
+	})
+}
+
+func TestWaitReady(t *testing.T) {
+	readinessInterval = 10 * time.Millisecond
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	k := &KubernetesRuntime{}
+
+	t.Run("ready", func(t *testing.T) {
+		obj := newDeployment(1, 1, 1, 1, 1)
+		client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), obj)
+		err := k.waitReady(context.TODO(), obj, client.Resource(gvr).Namespace("default"), time.Second)
+		assert.NoError(t, err)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		obj := newDeployment(1, 1, 1, 1, 0)
+		client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), obj)
+		err := k.waitReady(context.TODO(), obj, client.Resource(gvr).Namespace("default"), 50*time.Millisecond)
+		assert.ErrorContains(t, err, "timed out waiting for Deployment foo to be ready")
+	})
+
+	t.Run("failed rollout", func(t *testing.T) {
+		obj := newDeployment(1, 1, 1, 0, 0, map[string]interface{}{
+			"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded",
+		})
+		client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), obj)
+		err := k.waitReady(context.TODO(), obj, client.Resource(gvr).Namespace("default"), time.Second)
+		assert.ErrorContains(t, err, "Deployment foo failed")
+	})
+
+	t.Run("kind without checker", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "foo"},
+		}}
+		assert.NoError(t, k.waitReady(context.TODO(), obj, nil, time.Second))
+	})
+}
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"kusionstack.io/kusion/pkg/models"
+)
+
+// replaceTimeout is the max time to wait for the old object to be deleted when replacing it
+var replaceTimeout = 5 * time.Minute
+
+// replace deletes the live object and creates it again after it is gone. It returns whether the live object has been
+// deleted together with the error, since the object is gone if creating it fails after the deletion
+func (k *KubernetesRuntime) replace(ctx context.Context, planState *models.Resource, planObj *unstructured.Unstructured, resource dynamic.ResourceInterface) (bool, error) {
+	if err := checkReplace(planState); err != nil {
+		return false, err
+	}
+
+	propagation := metav1.DeletePropagationForeground
+	err := resource.Delete(ctx, planObj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	if err = waitDeleted(ctx, planObj.GetName(), resource, replaceTimeout); err != nil {
+		return true, err
+	}
+	_, err = resource.Create(ctx, planObj, metav1.CreateOptions{})
+	return true, err
+}
+
+// checkReplace returns an error if the resource can't be replaced. Creating before destroying is not supported,
+// since Kubernetes objects with the same name can't coexist
+func checkReplace(planState *models.Resource) error {
+	lifecycle, err := planState.Lifecycle()
+	if err != nil {
+		return err
+	}
+	if lifecycle.CreateBeforeDestroy {
+		return fmt.Errorf("can't replace %s with createBeforeDestroy, since Kubernetes objects with the same name can't coexist", planState.ID)
+	}
+	return nil
+}
+
+// isImmutableFieldError returns whether the error is caused by changing immutable fields of an object
+func isImmutableFieldError(err error) bool {
+	if !k8serrors.IsInvalid(err) {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "field is immutable") || strings.Contains(msg, "may not change once set")
+}
+
+// waitDeleted polls the object until it is not found
+func waitDeleted(ctx context.Context, name string, resource dynamic.ResourceInterface, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s to be deleted after %s", name, timeout)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+func TestIsImmutableFieldError(t *testing.T) {
+	gk := schema.GroupKind{Group: "batch", Kind: "Job"}
+	immutable := k8serrors.NewInvalid(gk, "foo", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "template"), nil, "field is immutable"),
+	})
+	assert.True(t, isImmutableFieldError(immutable))
+
+	invalid := k8serrors.NewInvalid(gk, "foo", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "parallelism"), -1, "must be greater than or equal to 0"),
+	})
+	assert.False(t, isImmutableFieldError(invalid))
+	assert.False(t, isImmutableFieldError(k8serrors.NewNotFound(schema.GroupResource{}, "foo")))
+}
+
+func TestKubernetesRuntime_replace(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	newJob := func(image string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata":   map[string]interface{}{"name": "foo", "namespace": "default"},
+			"spec":       map[string]interface{}{"image": image},
+		}}
+	}
+	k := &KubernetesRuntime{}
+
+	t.Run("delete then create", func(t *testing.T) {
+		client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), newJob("old"))
+		resource := client.Resource(gvr).Namespace("default")
+		deleted, err := k.replace(context.TODO(), &models.Resource{ID: "batch/v1:Job:default:foo"}, newJob("new"), resource)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+
+		live, err := resource.Get(context.TODO(), "foo", metav1.GetOptions{})
+		assert.NoError(t, err)
+		image, _, _ := unstructured.NestedString(live.Object, "spec", "image")
+		assert.Equal(t, "new", image)
+	})
+
+	t.Run("create before destroy", func(t *testing.T) {
+		client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), newJob("old"))
+		plan := &models.Resource{
+			ID:         "batch/v1:Job:default:foo",
+			Extensions: map[string]interface{}{models.LifecycleKey: map[string]interface{}{"createBeforeDestroy": true}},
+		}
+		deleted, err := k.replace(context.TODO(), plan, newJob("new"), client.Resource(gvr).Namespace("default"))
+		assert.ErrorContains(t, err, "can't replace batch/v1:Job:default:foo with createBeforeDestroy")
+		assert.False(t, deleted)
+	})
+
+	t.Run("create failed after deletion", func(t *testing.T) {
+		client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), newJob("old"))
+		client.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+			return true, nil, errors.New("quota exceeded")
+		})
+		resource := client.Resource(gvr).Namespace("default")
+		deleted, err := k.replace(context.TODO(), &models.Resource{ID: "batch/v1:Job:default:foo"}, newJob("new"), resource)
+		assert.EqualError(t, err, "quota exceeded")
+		assert.True(t, deleted, "the old object is gone")
+	})
+}
+
+func TestKubernetesRuntime_ApplyReplace(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "foo", "namespace": "default"},
+		"spec":       map[string]interface{}{"image": "old"},
+	}}
+	client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), live)
+	client.PrependReactor("patch", "jobs", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		return true, nil, k8serrors.NewInvalid(schema.GroupKind{Group: "batch", Kind: "Job"}, "foo", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "template"), nil, "field is immutable"),
+		})
+	})
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, meta.RESTScopeNamespace)
+	k := &KubernetesRuntime{client: client, mapper: mapper}
+
+	plan := &models.Resource{ID: "batch/v1:Job:default:foo", Type: runtime.Kubernetes, Attributes: live.DeepCopy().Object}
+	plan.Attributes["spec"] = map[string]interface{}{"image": "new"}
+	rsp := k.Apply(context.TODO(), &runtime.ApplyRequest{PlanResource: plan, DryRun: true})
+	assert.Nil(t, rsp.Status)
+	assert.True(t, rsp.RequiresReplace)
+
+	// the preview fails if the object can't be replaced
+	plan.Extensions = map[string]interface{}{models.LifecycleKey: map[string]interface{}{"createBeforeDestroy": true}}
+	rsp = k.Apply(context.TODO(), &runtime.ApplyRequest{PlanResource: plan, DryRun: true})
+	assert.True(t, status.IsErr(rsp.Status))
+	assert.Contains(t, rsp.Status.Message(), "can't replace batch/v1:Job:default:foo with createBeforeDestroy")
+}
@@ -0,0 +1,238 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/csaupgrade"
+
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+// clientSideFieldManager is the field manager of objects patched client-side by Kusion
+const clientSideFieldManager = "kusion"
+
+// serverSideFieldManager returns the field manager of server-side apply if it is enabled by the stack, and an empty
+// string otherwise
+func serverSideFieldManager(stack *projectstack.Stack) string {
+	if stack == nil || stack.ServerSideApply == nil || !stack.ServerSideApply.Enabled {
+		return ""
+	}
+	return stack.ServerSideApply.GetFieldManager()
+}
+
+// serverSideDryRun previews the result of applying the plan object server-side, together with fields owned by other
+// managers that would be overwritten. It falls back to merging the plan into the live object if the dry-run fails
+func serverSideDryRun(
+	ctx context.Context,
+	planObj, liveObj *unstructured.Unstructured,
+	resource dynamic.ResourceInterface,
+	fieldManager string,
+) (res *unstructured.Unstructured, requiresReplace bool, conflicts []string, err error) {
+	if liveObj != nil {
+		conflicts = fieldConflicts(liveObj, planObj, fieldManager)
+	}
+
+	// Force the dry-run to preview the result even if there are conflicts, which are reported instead
+	options := metav1.ApplyOptions{FieldManager: fieldManager, Force: true, DryRun: []string{metav1.DryRunAll}}
+	appliedObj, err := resource.Apply(ctx, planObj.GetName(), planObj, options)
+	if err == nil {
+		return appliedObj, false, conflicts, nil
+	}
+
+	// Changes on immutable fields can only be applied by replacing the object
+	requiresReplace = isImmutableFieldError(err)
+	log.Errorf("ServerSideDryRun apply %s failed, fall back to ClientSideDryRun; err: %v", planObj.GetName(), err)
+	if liveObj == nil {
+		return planObj, requiresReplace, conflicts, nil
+	}
+
+	current, err := liveObj.MarshalJSON()
+	if err != nil {
+		return nil, false, nil, err
+	}
+	modified, err := planObj.MarshalJSON()
+	if err != nil {
+		return nil, false, nil, err
+	}
+	merged, err := jsonpatch.MergePatch(current, modified)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	res = &unstructured.Unstructured{}
+	if err = res.UnmarshalJSON(merged); err != nil {
+		return nil, false, nil, err
+	}
+	return res, requiresReplace, conflicts, nil
+}
+
+// serverSideApply applies the plan object server-side. Fields of the live object patched client-side by Kusion are
+// migrated to the field manager first, so that fields removed from the plan are removed from the object as well
+func serverSideApply(
+	ctx context.Context,
+	planObj, liveObj *unstructured.Unstructured,
+	resource dynamic.ResourceInterface,
+	fieldManager string,
+	force bool,
+) error {
+	if liveObj != nil {
+		if err := migrateManagedFields(ctx, liveObj, resource, fieldManager); err != nil {
+			return err
+		}
+	}
+
+	_, err := resource.Apply(ctx, planObj.GetName(), planObj, metav1.ApplyOptions{FieldManager: fieldManager, Force: force})
+	if k8serrors.IsConflict(err) && !force {
+		return fmt.Errorf("%w. Apply with --force-conflicts to take the ownership of these fields", err)
+	}
+	return err
+}
+
+// migrateManagedFields transfers the ownership of fields patched client-side by Kusion to the field manager of
+// server-side apply
+func migrateManagedFields(ctx context.Context, liveObj *unstructured.Unstructured, resource dynamic.ResourceInterface, fieldManager string) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(liveObj, sets.New(clientSideFieldManager), fieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+	_, err = resource.Patch(ctx, liveObj.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// fieldConflicts returns fields of the live object owned by other managers which the plan object sets to different
+// values, like ".spec.replicas (kube-controller-manager)"
+func fieldConflicts(liveObj, planObj *unstructured.Unstructured, fieldManager string) []string {
+	var conflicts []string
+	for _, entry := range liveObj.GetManagedFields() {
+		if entry.Manager == fieldManager || entry.Manager == clientSideFieldManager || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			log.Warnf("invalid managed fields of %s in %s: %v", entry.Manager, liveObj.GetName(), err)
+			continue
+		}
+		for _, path := range conflictingFields(fields, "", planObj.Object, liveObj.Object) {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", path, entry.Manager))
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// conflictingFields walks the fields of a managedFields entry in the FieldsV1 format, where "f:<name>" keys are
+// fields and "k:<json>" keys are items of lists selected by their keys, and returns paths of owned leaves whose
+// values differ between the plan and the live object
+func conflictingFields(fields map[string]interface{}, path string, plan, live interface{}) []string {
+	var paths []string
+	for key, child := range fields {
+		var p string
+		var planValue, liveValue interface{}
+		var found bool
+		switch {
+		case strings.HasPrefix(key, "f:"):
+			name := strings.TrimPrefix(key, "f:")
+			p = path + "." + name
+			planValue, found = fieldOf(plan, name)
+			liveValue, _ = fieldOf(live, name)
+		case strings.HasPrefix(key, "k:"):
+			selector := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &selector); err != nil {
+				continue
+			}
+			p = path + "[" + formatSelector(selector) + "]"
+			planValue, found = itemOf(plan, selector)
+			liveValue, _ = itemOf(live, selector)
+		default:
+			// "." marks the ownership of the parent itself, and items of sets ("v:") or lists without keys ("i:")
+			// are not overwritten with different values
+			continue
+		}
+		// Fields not set by the plan are left to their managers
+		if !found {
+			continue
+		}
+
+		children, _ := child.(map[string]interface{})
+		if len(children) == 0 {
+			if !jsonEqual(planValue, liveValue) {
+				paths = append(paths, p)
+			}
+			continue
+		}
+		paths = append(paths, conflictingFields(children, p, planValue, liveValue)...)
+	}
+	return paths
+}
+
+func fieldOf(obj interface{}, name string) (interface{}, bool) {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	v, ok := m[name]
+	return v, ok
+}
+
+func itemOf(list interface{}, selector map[string]interface{}) (interface{}, bool) {
+	items, ok := list.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		matched := true
+		for k, v := range selector {
+			if !jsonEqual(m[k], v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func formatSelector(selector map[string]interface{}) string {
+	keys := make([]string, 0, len(selector))
+	for k := range selector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, selector[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// jsonEqual compares values by their JSON encoding, since numbers are decoded as different types by different
+// decoders
+func jsonEqual(a, b interface{}) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(x, y)
+}
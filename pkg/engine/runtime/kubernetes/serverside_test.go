@@ -0,0 +1,195 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+func newManagedDeployment(replicas int64, image, label string, managedFields ...metav1.ManagedFieldsEntry) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "nginx",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": label},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "nginx", "image": image},
+					},
+				},
+			},
+		},
+	}}
+	obj.SetManagedFields(managedFields)
+	return obj
+}
+
+func managedFieldsEntry(manager string, operation metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  operation,
+		APIVersion: "apps/v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+var liveManagedFields = []metav1.ManagedFieldsEntry{
+	managedFieldsEntry(clientSideFieldManager, metav1.ManagedFieldsOperationUpdate, `{"f:metadata":{"f:labels":{".":{},"f:app":{}}}}`),
+	managedFieldsEntry("kubectl-scale", metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:replicas":{}}}`),
+	managedFieldsEntry("other", metav1.ManagedFieldsOperationApply,
+		`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"nginx\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`),
+}
+
+func TestServerSideFieldManager(t *testing.T) {
+	assert.Equal(t, "", serverSideFieldManager(nil))
+	assert.Equal(t, "", serverSideFieldManager(&projectstack.Stack{}))
+
+	stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{
+		ServerSideApply: &projectstack.ServerSideApplyConfig{Enabled: true},
+	}}
+	assert.Equal(t, projectstack.DefaultFieldManager, serverSideFieldManager(stack))
+	stack.ServerSideApply.FieldManager = "ci"
+	assert.Equal(t, "ci", serverSideFieldManager(stack))
+}
+
+func TestFieldConflicts(t *testing.T) {
+	live := newManagedDeployment(3, "nginx:1.0", "a", liveManagedFields...)
+	tests := map[string]struct {
+		plan *unstructured.Unstructured
+		want []string
+	}{
+		"no conflicts": {
+			plan: newManagedDeployment(3, "nginx:1.0", "b"),
+		},
+		"overwrite fields of others": {
+			plan: newManagedDeployment(2, "nginx:1.1", "b"),
+			want: []string{
+				".spec.replicas (kubectl-scale)",
+				".spec.template.spec.containers[name=nginx].image (other)",
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, fieldConflicts(live, tc.plan, "ci"))
+		})
+	}
+
+	// fields not set by the plan are left to their managers
+	plan := newManagedDeployment(2, "nginx:1.0", "a")
+	unstructured.RemoveNestedField(plan.Object, "spec", "replicas")
+	assert.Empty(t, fieldConflicts(live, plan, "ci"))
+}
+
+func newPatchRecorder(t *testing.T, live *unstructured.Unstructured, applyErr error) (*fake.FakeDynamicClient, *[]types.PatchType) {
+	client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), live)
+	patches := &[]types.PatchType{}
+	client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		*patches = append(*patches, patch.GetPatchType())
+		if patch.GetPatchType() == types.JSONPatchType {
+			// the client-side manager is merged into the server-side one
+			var ops []map[string]interface{}
+			require.NoError(t, json.Unmarshal(patch.GetPatch(), &ops))
+			managers := map[string]interface{}{}
+			for _, entry := range ops[0]["value"].([]interface{}) {
+				e := entry.(map[string]interface{})
+				managers[e["manager"].(string)] = e["operation"]
+			}
+			assert.Equal(t, map[string]interface{}{"ci": "Apply", "kubectl-scale": "Update", "other": "Apply"}, managers)
+			return true, live, nil
+		}
+		if applyErr != nil {
+			return true, nil, applyErr
+		}
+		obj := &unstructured.Unstructured{}
+		require.NoError(t, obj.UnmarshalJSON(patch.GetPatch()))
+		return true, obj, nil
+	})
+	return client, patches
+}
+
+func TestServerSideApply(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	plan := newManagedDeployment(2, "nginx:1.1", "b")
+
+	t.Run("migrate then apply", func(t *testing.T) {
+		live := newManagedDeployment(3, "nginx:1.0", "a", liveManagedFields...)
+		client, patches := newPatchRecorder(t, live, nil)
+		resource := client.Resource(gvr).Namespace("default")
+
+		err := serverSideApply(context.TODO(), plan, live, resource, "ci", true)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.PatchType{types.JSONPatchType, types.ApplyPatchType}, *patches)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		client, patches := newPatchRecorder(t, newManagedDeployment(1, "", ""), nil)
+		resource := client.Resource(gvr).Namespace("default")
+
+		err := serverSideApply(context.TODO(), plan, nil, resource, "ci", false)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.PatchType{types.ApplyPatchType}, *patches)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		conflict := k8serrors.NewConflict(gvr.GroupResource(), "nginx", errors.New(`conflict with "kubectl-scale": .spec.replicas`))
+		client, _ := newPatchRecorder(t, newManagedDeployment(1, "", ""), conflict)
+		resource := client.Resource(gvr).Namespace("default")
+
+		err := serverSideApply(context.TODO(), plan, nil, resource, "ci", false)
+		assert.True(t, k8serrors.IsConflict(err))
+		assert.Contains(t, err.Error(), "--force-conflicts")
+	})
+}
+
+func TestServerSideDryRun(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	live := newManagedDeployment(3, "nginx:1.0", "a", liveManagedFields...)
+	plan := newManagedDeployment(2, "nginx:1.0", "b")
+
+	t.Run("server-side", func(t *testing.T) {
+		client, patches := newPatchRecorder(t, live, nil)
+		resource := client.Resource(gvr).Namespace("default")
+
+		res, requiresReplace, conflicts, err := serverSideDryRun(context.TODO(), plan, live, resource, "ci")
+		assert.NoError(t, err)
+		assert.False(t, requiresReplace)
+		assert.Equal(t, []string{".spec.replicas (kubectl-scale)"}, conflicts)
+		assert.Equal(t, plan.Object, res.Object)
+		assert.Equal(t, []types.PatchType{types.ApplyPatchType}, *patches, "managed fields are not migrated in dry-run")
+	})
+
+	t.Run("fall back to client-side", func(t *testing.T) {
+		client, _ := newPatchRecorder(t, live, k8serrors.NewServiceUnavailable("unavailable"))
+		resource := client.Resource(gvr).Namespace("default")
+
+		res, _, conflicts, err := serverSideDryRun(context.TODO(), plan, live, resource, "ci")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{".spec.replicas (kubectl-scale)"}, conflicts)
+		replicas, _, _ := unstructured.NestedInt64(res.Object, "spec", "replicas")
+		assert.Equal(t, int64(2), replicas)
+		assert.NotEmpty(t, res.GetManagedFields(), "fields not in the plan are kept")
+	})
+}
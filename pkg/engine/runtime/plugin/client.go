@@ -0,0 +1,199 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os/exec"
+	"sync"
+
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+// Runtime is the runtime.Runtime calling a plugin by JSON-RPC
+type Runtime struct {
+	typ models.Type
+
+	// path is the executable of the plugin started by Start, which is restarted once its connection is broken, like
+	// the plugin crashes. It is empty if the Runtime calls the plugin over a given connection
+	path string
+
+	lock   sync.Mutex
+	client *rpc.Client
+	cmd    *exec.Cmd
+}
+
+var _ runtime.Runtime = (*Runtime)(nil)
+
+// NewRuntime returns the Runtime calling the plugin of the resource type over the connection. It shakes hands with
+// the plugin, and returns an error if the plugin serves another resource type or protocol version
+func NewRuntime(typ models.Type, conn io.ReadWriteCloser) (*Runtime, error) {
+	client, err := handshake(typ, conn)
+	if err != nil {
+		return nil, err
+	}
+	return &Runtime{typ: typ, client: client}, nil
+}
+
+// handshake returns the client of the plugin over the connection after shaking hands with the plugin, and the
+// connection is closed if it fails
+func handshake(typ models.Type, conn io.ReadWriteCloser) (*rpc.Client, error) {
+	client := jsonrpc.NewClient(conn)
+	reply := &HandshakeReply{}
+	if err := client.Call(ServiceName+".Handshake", &HandshakeArgs{ProtocolVersion: ProtocolVersion}, reply); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("call Handshake of the %s plugin failed: %w", typ, err)
+	}
+	if reply.ProtocolVersion != ProtocolVersion || reply.Type != typ {
+		_ = client.Close()
+		return nil, fmt.Errorf("the %s plugin serves %s resources with protocol version %d, but %s resources with protocol version %d are expected",
+			typ, reply.Type, reply.ProtocolVersion, typ, ProtocolVersion)
+	}
+	return client, nil
+}
+
+// currentClient returns the client of the running plugin
+func (r *Runtime) currentClient() *rpc.Client {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.client
+}
+
+// call invokes the method of the plugin, and gives up waiting for the reply once the context is done. Errors
+// returned by the plugin keep their status codes, and the plugin is restarted if the connection to it is broken
+func (r *Runtime) call(ctx context.Context, method string, args, reply interface{}) status.Status {
+	client := r.currentClient()
+	call := client.Go(ServiceName+"."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		if call.Error == nil {
+			return nil
+		}
+		var serverErr rpc.ServerError
+		if errors.As(call.Error, &serverErr) {
+			code, msg := decodeError(serverErr)
+			return status.NewErrorStatusWithMsg(code, fmt.Sprintf("call %s of the %s plugin failed: %s", method, r.typ, msg))
+		}
+
+		// the connection is broken, such as rpc.ErrShutdown or io.ErrUnexpectedEOF once the plugin exits
+		err := fmt.Errorf("call %s of the %s plugin failed: %w", method, r.typ, call.Error)
+		if r.path != "" {
+			if restartErr := r.restart(client); restartErr != nil {
+				err = fmt.Errorf("%v, and restarting it failed: %v", err, restartErr)
+			}
+		}
+		return status.NewErrorStatusWithCode(status.Unavailable, err)
+	case <-ctx.Done():
+		return status.NewErrorStatusWithCode(status.Canceled, ctx.Err())
+	}
+}
+
+// restart reaps the plugin process whose client is broken and starts it again, so that this Runtime keeps working
+// in operations holding it. Nothing is done if the plugin has been restarted by another call
+func (r *Runtime) restart(broken *rpc.Client) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.client != broken {
+		return nil
+	}
+
+	_ = broken.Close()
+	if r.cmd != nil {
+		_ = r.cmd.Process.Kill()
+		if err := r.cmd.Wait(); err != nil {
+			log.Infof("plugin %s exited: %v", r.path, err)
+		}
+		r.cmd = nil
+	}
+	client, cmd, err := startProcess(r.typ, r.path)
+	if err != nil {
+		return err
+	}
+	r.client, r.cmd = client, cmd
+	return nil
+}
+
+func (r *Runtime) Apply(ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+	reply := &ApplyReply{}
+	if s := r.call(ctx, "Apply", request, reply); s != nil {
+		return &runtime.ApplyResponse{Status: s}
+	}
+	return &runtime.ApplyResponse{Resource: reply.Resource, RequiresReplace: reply.RequiresReplace, Conflicts: reply.Conflicts, Deleted: reply.Deleted, Status: reply.Status.toStatus()}
+}
+
+func (r *Runtime) Read(ctx context.Context, request *runtime.ReadRequest) *runtime.ReadResponse {
+	reply := &ReadReply{}
+	if s := r.call(ctx, "Read", request, reply); s != nil {
+		return &runtime.ReadResponse{Status: s}
+	}
+	return &runtime.ReadResponse{Resource: reply.Resource, Status: reply.Status.toStatus()}
+}
+
+func (r *Runtime) Import(ctx context.Context, request *runtime.ImportRequest) *runtime.ImportResponse {
+	reply := &ImportReply{}
+	if s := r.call(ctx, "Import", request, reply); s != nil {
+		return &runtime.ImportResponse{Status: s}
+	}
+	return &runtime.ImportResponse{Resource: reply.Resource, Status: reply.Status.toStatus()}
+}
+
+func (r *Runtime) Delete(ctx context.Context, request *runtime.DeleteRequest) *runtime.DeleteResponse {
+	reply := &DeleteReply{}
+	if s := r.call(ctx, "Delete", request, reply); s != nil {
+		return &runtime.DeleteResponse{Status: s}
+	}
+	return &runtime.DeleteResponse{Status: reply.Status.toStatus()}
+}
+
+// Watch starts a watch in the plugin, and pulls events of each resource watched until the context is done
+func (r *Runtime) Watch(ctx context.Context, request *runtime.WatchRequest) *runtime.WatchResponse {
+	reply := &WatchReply{}
+	if s := r.call(ctx, "Watch", request, reply); s != nil {
+		return &runtime.WatchResponse{Status: s}
+	}
+	if reply.ID == "" {
+		if s := reply.Status.toStatus(); s != nil {
+			return &runtime.WatchResponse{Status: s}
+		}
+		return nil
+	}
+
+	watchers := runtime.NewWatchers()
+	for i, id := range reply.ResourceIDs {
+		watchers.Insert(id, r.events(ctx, reply.ID, i))
+	}
+	go func() {
+		<-ctx.Done()
+		r.currentClient().Go(ServiceName+".Stop", &StopArgs{ID: reply.ID}, &StopReply{}, make(chan *rpc.Call, 1))
+	}()
+	return &runtime.WatchResponse{Watchers: watchers, Status: reply.Status.toStatus()}
+}
+
+// events pulls events of the resource at the index of the watch, and the channel is closed once there are no more
+// events or the context is done
+func (r *Runtime) events(ctx context.Context, id string, index int) <-chan k8swatch.Event {
+	eventCh := make(chan k8swatch.Event)
+	go func() {
+		defer close(eventCh)
+		for {
+			reply := &NextReply{}
+			if s := r.call(ctx, "Next", &NextArgs{ID: id, Index: index}, reply); s != nil || reply.Done {
+				return
+			}
+			select {
+			case eventCh <- reply.Event.toEvent():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return eventCh
+}
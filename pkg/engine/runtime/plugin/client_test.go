@@ -0,0 +1,271 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+const (
+	fakeType  models.Type = "Fake"
+	pluginEnv             = "KUSION_TEST_PLUGIN"
+)
+
+// TestMain serves the fakeRuntime as a plugin if the test binary is started as a plugin
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginEnv) != "" {
+		_ = Serve(fakeType, &fakeRuntime{})
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+var fakeResource = &models.Resource{ID: "fake:a", Type: fakeType, Attributes: map[string]interface{}{"a": "b"}}
+
+type fakeRuntime struct {
+	block chan struct{}
+}
+
+func (f *fakeRuntime) Apply(ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+	if f.block != nil {
+		<-f.block
+	}
+	return &runtime.ApplyResponse{Resource: request.PlanResource, RequiresReplace: request.DryRun}
+}
+
+func (f *fakeRuntime) Read(ctx context.Context, request *runtime.ReadRequest) *runtime.ReadResponse {
+	return &runtime.ReadResponse{Status: status.NewErrorStatusWithMsg(status.Throttled, "too many requests")}
+}
+
+func (f *fakeRuntime) Import(ctx context.Context, request *runtime.ImportRequest) *runtime.ImportResponse {
+	return &runtime.ImportResponse{Resource: request.PlanResource}
+}
+
+func (f *fakeRuntime) Delete(ctx context.Context, request *runtime.DeleteRequest) *runtime.DeleteResponse {
+	return &runtime.DeleteResponse{}
+}
+
+func (f *fakeRuntime) Watch(ctx context.Context, request *runtime.WatchRequest) *runtime.WatchResponse {
+	eventCh := make(chan k8swatch.Event)
+	go func() {
+		defer close(eventCh)
+		eventCh <- k8swatch.Event{Type: k8swatch.Added, Object: &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Fake"}}}
+		<-ctx.Done()
+	}()
+	watchers := runtime.NewWatchers()
+	watchers.Insert(request.Resource.ResourceKey(), eventCh)
+	return &runtime.WatchResponse{Watchers: watchers}
+}
+
+func newFakeRuntime(t *testing.T, fake *fakeRuntime) *Runtime {
+	server, client := net.Pipe()
+	go func() {
+		_ = ServeConn(fakeType, fake, server)
+	}()
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	r, err := NewRuntime(fakeType, client)
+	require.NoError(t, err)
+	return r
+}
+
+func TestRuntime(t *testing.T) {
+	r := newFakeRuntime(t, &fakeRuntime{})
+	ctx := context.Background()
+
+	applyRsp := r.Apply(ctx, &runtime.ApplyRequest{PlanResource: fakeResource, DryRun: true})
+	assert.Nil(t, applyRsp.Status)
+	assert.Equal(t, fakeResource, applyRsp.Resource)
+	assert.True(t, applyRsp.RequiresReplace)
+
+	readRsp := r.Read(ctx, &runtime.ReadRequest{PlanResource: fakeResource})
+	assert.Nil(t, readRsp.Resource)
+	assert.Equal(t, status.NewErrorStatusWithMsg(status.Throttled, "too many requests"), readRsp.Status)
+
+	importRsp := r.Import(ctx, &runtime.ImportRequest{PlanResource: fakeResource})
+	assert.Nil(t, importRsp.Status)
+	assert.Equal(t, fakeResource, importRsp.Resource)
+
+	deleteRsp := r.Delete(ctx, &runtime.DeleteRequest{Resource: fakeResource})
+	assert.Nil(t, deleteRsp.Status)
+}
+
+func TestRuntime_Watch(t *testing.T) {
+	r := newFakeRuntime(t, &fakeRuntime{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rsp := r.Watch(ctx, &runtime.WatchRequest{Resource: fakeResource})
+	require.Nil(t, rsp.Status)
+	assert.Equal(t, []string{fakeResource.ID}, rsp.Watchers.IDs)
+
+	e := <-rsp.Watchers.Watchers[0]
+	assert.Equal(t, k8swatch.Added, e.Type)
+	assert.Equal(t, "Fake", e.Object.(*unstructured.Unstructured).GetKind())
+
+	// the watch is stopped in the plugin once the context is canceled
+	cancel()
+	select {
+	case _, ok := <-rsp.Watchers.Watchers[0]:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("events are not closed after the context is canceled")
+	}
+}
+
+func TestRuntime_Canceled(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	r := newFakeRuntime(t, &fakeRuntime{block: block})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	rsp := r.Apply(ctx, &runtime.ApplyRequest{PlanResource: fakeResource})
+	assert.Equal(t, status.Canceled, rsp.Status.Code())
+}
+
+func TestNewRuntime_TypeMismatch(t *testing.T) {
+	server, client := net.Pipe()
+	go func() {
+		_ = ServeConn("Other", &fakeRuntime{}, server)
+	}()
+	defer client.Close()
+
+	_, err := NewRuntime(fakeType, client)
+	assert.EqualError(t, err, "the Fake plugin serves Other resources with protocol version 1, but Fake resources with protocol version 1 are expected")
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"kusion-runtime-Fake", "kusion-runtime-Other.exe", "kusion-runtime-", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o755))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "kusion-runtime-Dir"), 0o755))
+
+	plugins, err := Discover(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, map[models.Type]string{
+		"Fake":  filepath.Join(dir, "kusion-runtime-Fake"),
+		"Other": filepath.Join(dir, "kusion-runtime-Other.exe"),
+	}, plugins)
+
+	plugins, err = Discover(filepath.Join(dir, "not-existed"))
+	assert.NoError(t, err)
+	assert.Empty(t, plugins)
+}
+
+func TestDirs(t *testing.T) {
+	dir, err := Dir()
+	require.NoError(t, err)
+
+	dirs, err := Dirs(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{dir}, dirs)
+
+	project := &projectstack.Project{
+		ProjectConfiguration: projectstack.ProjectConfiguration{Plugins: &projectstack.PluginsConfig{Dir: "plugins"}},
+		Path:                 "/project",
+	}
+	dirs, err = Dirs(project)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("/project", "plugins"), dir}, dirs)
+
+	project.Plugins.Dir = "/opt/plugins"
+	dirs, err = Dirs(project)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/opt/plugins", dir}, dirs)
+}
+
+func TestStart(t *testing.T) {
+	// start the test binary itself as the plugin
+	t.Setenv(pluginEnv, "1")
+	path, err := os.Executable()
+	require.NoError(t, err)
+
+	r, err := Start(fakeType, path)
+	require.NoError(t, err)
+	defer func() {
+		_ = r.currentClient().Close()
+		delete(started, path)
+	}()
+
+	rsp := r.Apply(context.Background(), &runtime.ApplyRequest{PlanResource: fakeResource})
+	assert.Nil(t, rsp.Status)
+	assert.Equal(t, fakeResource, rsp.Resource)
+
+	// the plugin is started only once
+	again, err := Start(fakeType, path)
+	assert.NoError(t, err)
+	assert.Same(t, r, again)
+
+	// the plugin is restarted in place after the connection is shut down
+	require.NoError(t, r.currentClient().Close())
+	rsp = r.Apply(context.Background(), &runtime.ApplyRequest{PlanResource: fakeResource})
+	assert.Equal(t, status.Unavailable, rsp.Status.Code())
+	rsp = r.Apply(context.Background(), &runtime.ApplyRequest{PlanResource: fakeResource})
+	assert.Nil(t, rsp.Status)
+}
+
+func TestStart_Killed(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is required to build the sample plugin")
+	}
+	path := filepath.Join(t.TempDir(), ExecutablePrefix+"File")
+	out, err := exec.Command(goBin, "build", "-o", path, "./sample").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	r, err := Start("File", path)
+	require.NoError(t, err)
+	defer func() {
+		_ = r.currentClient().Close()
+		delete(started, path)
+	}()
+	resource := &models.Resource{
+		ID:         "file:hello",
+		Type:       "File",
+		Attributes: map[string]interface{}{"path": "hello.txt", "content": "hello"},
+	}
+	stack := &projectstack.Stack{Path: t.TempDir()}
+	rsp := r.Apply(context.Background(), &runtime.ApplyRequest{PlanResource: resource, Stack: stack})
+	require.Nil(t, rsp.Status)
+
+	// kill the plugin between two calls, and the call in between fails as the plugin is unavailable
+	killed := r.cmd
+	require.NoError(t, killed.Process.Kill())
+	readRsp := r.Read(context.Background(), &runtime.ReadRequest{PlanResource: resource, Stack: stack})
+	assert.Equal(t, status.Unavailable, readRsp.Status.Code())
+
+	// the same Runtime keeps working with the restarted plugin, and the killed one is reaped
+	readRsp = r.Read(context.Background(), &runtime.ReadRequest{PlanResource: resource, Stack: stack})
+	require.Nil(t, readRsp.Status)
+	assert.Equal(t, "hello", readRsp.Resource.Attributes["content"])
+	assert.NotNil(t, killed.ProcessState)
+	assert.NotSame(t, killed, r.cmd)
+}
+
+func TestDecodeError(t *testing.T) {
+	code, msg := decodeError(rpc.ServerError(encodeError(status.Throttled, errors.New("too many requests")).Error()))
+	assert.Equal(t, status.Throttled, code)
+	assert.Equal(t, "too many requests", msg)
+
+	code, msg = decodeError("rpc: can't find method Runtime.Foo")
+	assert.Equal(t, status.Internal, code)
+	assert.Equal(t, "rpc: can't find method Runtime.Foo", msg)
+}
@@ -0,0 +1,147 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/util/kfile"
+)
+
+const (
+	// Directory is the directory of plugins in the Kusion data folder
+	Directory = "plugins"
+
+	// ExecutablePrefix is the prefix of plugin executables, which is followed by the resource type they serve
+	ExecutablePrefix = "kusion-runtime-"
+)
+
+var (
+	// started contains plugins started by this process, keyed by the path of the plugin executable
+	started     = map[string]*Runtime{}
+	startedLock sync.Mutex
+)
+
+// Dir returns the plugins directory
+func Dir() (string, error) {
+	folder, err := kfile.KusionDataFolder()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(folder, Directory), nil
+}
+
+// Dirs returns directories to discover plugins in, where the directory configured by the project comes before the
+// plugins directory
+func Dirs(project *projectstack.Project) ([]string, error) {
+	var dirs []string
+	if project != nil && project.Plugins != nil && project.Plugins.Dir != "" {
+		dir := project.Plugins.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(project.GetPath(), dir)
+		}
+		dirs = append(dirs, dir)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return append(dirs, dir), nil
+}
+
+// Discover returns paths of plugin executables in the directory, keyed by the resource types they serve
+func Discover(dir string) (map[models.Type]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[models.Type]string{}, nil
+		}
+		return nil, fmt.Errorf("read plugins directory failed: %w", err)
+	}
+
+	plugins := map[models.Type]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, ExecutablePrefix) {
+			continue
+		}
+		typ := strings.TrimSuffix(strings.TrimPrefix(name, ExecutablePrefix), ".exe")
+		if typ == "" {
+			continue
+		}
+		plugins[models.Type(typ)] = filepath.Join(dir, name)
+	}
+	return plugins, nil
+}
+
+// Start starts the plugin executable serving the resource type, and returns the Runtime calling it. Each plugin
+// is started once in a process, and it exits when the process exits
+func Start(typ models.Type, path string) (*Runtime, error) {
+	startedLock.Lock()
+	defer startedLock.Unlock()
+	if r, ok := started[path]; ok {
+		return r, nil
+	}
+
+	client, cmd, err := startProcess(typ, path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Runtime{typ: typ, path: path, client: client, cmd: cmd}
+	started[path] = r
+	return r, nil
+}
+
+// startProcess starts the plugin process, and returns the client of it after shaking hands
+func startProcess(typ models.Type, path string) (*rpc.Client, *exec.Cmd, error) {
+	cmd := exec.Command(path)
+	cmd.Stderr = &logWriter{typ: typ}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("start plugin %s failed: %w", path, err)
+	}
+	log.Infof("plugin %s started with pid %d", path, cmd.Process.Pid)
+
+	client, err := handshake(typ, &processConn{ReadCloser: stdout, WriteCloser: stdin})
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, nil, err
+	}
+	return client, cmd, nil
+}
+
+// processConn is the connection to a plugin process, and closing it closes the stdin of the plugin
+type processConn struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+func (c *processConn) Close() error {
+	return c.WriteCloser.Close()
+}
+
+// logWriter writes the stderr of plugins to logs
+type logWriter struct {
+	typ models.Type
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	log.Infof("%s plugin: %s", w.typ, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
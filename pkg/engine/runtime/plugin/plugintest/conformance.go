@@ -0,0 +1,84 @@
+// Package plugintest provides conformance tests for runtime plugins.
+package plugintest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+// Conformance checks the runtime behaves as Kusion expects during the whole lifecycle of the resource, which must
+// not exist before the test. Plugins are usually tested through plugin.NewRuntime to cover the protocol as well
+func Conformance(t *testing.T, rt runtime.Runtime, resource *models.Resource) {
+	ctx := context.Background()
+	stack := &projectstack.Stack{StackConfiguration: projectstack.StackConfiguration{Name: "conformance"}, Path: t.TempDir()}
+
+	read := func(t *testing.T) *models.Resource {
+		rsp := rt.Read(ctx, &runtime.ReadRequest{PlanResource: resource, Stack: stack})
+		require.NotNil(t, rsp)
+		require.False(t, status.IsErr(rsp.Status), "read failed: %v", rsp.Status)
+		return rsp.Resource
+	}
+
+	t.Run("read a resource not existed", func(t *testing.T) {
+		assert.Nil(t, read(t))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		rsp := rt.Apply(ctx, &runtime.ApplyRequest{PlanResource: resource, Stack: stack, DryRun: true})
+		require.NotNil(t, rsp)
+		require.False(t, status.IsErr(rsp.Status), "dry run failed: %v", rsp.Status)
+		require.NotNil(t, rsp.Resource)
+		assert.Equal(t, resource.ResourceKey(), rsp.Resource.ResourceKey())
+		assert.Nil(t, read(t), "dry run must not make any changes")
+	})
+
+	var applied *models.Resource
+	t.Run("create", func(t *testing.T) {
+		rsp := rt.Apply(ctx, &runtime.ApplyRequest{PlanResource: resource, Stack: stack})
+		require.NotNil(t, rsp)
+		require.False(t, status.IsErr(rsp.Status), "apply failed: %v", rsp.Status)
+		require.NotNil(t, rsp.Resource)
+		assert.Equal(t, resource.ResourceKey(), rsp.Resource.ResourceKey())
+		applied = rsp.Resource
+
+		live := read(t)
+		require.NotNil(t, live)
+		assert.Equal(t, resource.ResourceKey(), live.ResourceKey())
+	})
+
+	t.Run("apply again", func(t *testing.T) {
+		rsp := rt.Apply(ctx, &runtime.ApplyRequest{PriorResource: applied, PlanResource: resource, Stack: stack})
+		require.NotNil(t, rsp)
+		require.False(t, status.IsErr(rsp.Status), "apply failed: %v", rsp.Status)
+		require.NotNil(t, rsp.Resource)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		rsp := rt.Import(ctx, &runtime.ImportRequest{PlanResource: resource, Stack: stack, ID: resource.ResourceKey()})
+		require.NotNil(t, rsp)
+		require.False(t, status.IsErr(rsp.Status), "import failed: %v", rsp.Status)
+		require.NotNil(t, rsp.Resource)
+		assert.Equal(t, resource.ResourceKey(), rsp.Resource.ResourceKey())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		rsp := rt.Delete(ctx, &runtime.DeleteRequest{Resource: resource, Stack: stack})
+		require.NotNil(t, rsp)
+		require.False(t, status.IsErr(rsp.Status), "delete failed: %v", rsp.Status)
+		assert.Nil(t, read(t))
+	})
+
+	t.Run("delete a resource not existed", func(t *testing.T) {
+		rsp := rt.Delete(ctx, &runtime.DeleteRequest{Resource: resource, Stack: stack})
+		require.NotNil(t, rsp)
+		assert.False(t, status.IsErr(rsp.Status), "delete must succeed if the resource doesn't exist: %v", rsp.Status)
+	})
+}
@@ -0,0 +1,152 @@
+// Package plugin provides runtimes of resource types out of the Kusion tree.
+//
+// A runtime plugin is an executable named with the ExecutablePrefix and the resource type it serves, such as
+// "kusion-runtime-File" for resources of the type "File", and placed in the plugins directory of the Kusion data
+// folder. Kusion starts the plugin when resources of its type are operated, and calls the runtime.Runtime methods
+// of the plugin by JSON-RPC over the stdin and stdout of the plugin process. Plugins implement runtime.Runtime and
+// call Serve in their main function, and they exit once their stdin is closed. Failures are replied in the status of
+// responses with their codes, and a plugin exited unexpectedly is started again by Kusion.
+package plugin
+
+import (
+	"errors"
+	"net/rpc"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+const (
+	// ServiceName is the name of the RPC service served by plugins
+	ServiceName = "Runtime"
+
+	// ProtocolVersion is the version of the protocol between Kusion and plugins. Plugins with a different version
+	// are refused
+	ProtocolVersion = 1
+)
+
+// HandshakeArgs is sent to the plugin right after it is started
+type HandshakeArgs struct {
+	ProtocolVersion int
+}
+
+// HandshakeReply tells the protocol version and the resource type of the plugin
+type HandshakeReply struct {
+	ProtocolVersion int
+	Type            models.Type
+}
+
+// Status is the wire format of status.Status
+type Status struct {
+	Kind    status.Kind
+	Code    status.Code
+	Message string
+}
+
+func newStatus(s status.Status) *Status {
+	if s == nil {
+		return nil
+	}
+	return &Status{Kind: s.Kind(), Code: s.Code(), Message: s.Message()}
+}
+
+func (s *Status) toStatus() status.Status {
+	if s == nil {
+		return nil
+	}
+	return status.NewBaseStatus(s.Kind, s.Code, s.Message)
+}
+
+// codeSeparator separates the status code and the message in errors returned by plugins, like
+// "THROTTLED: too many requests"
+const codeSeparator = ": "
+
+// encodeError returns the error carrying the status code across the protocol
+func encodeError(code status.Code, err error) error {
+	return errors.New(string(code) + codeSeparator + err.Error())
+}
+
+// decodeError returns the status code and the message of the error returned by the plugin. Errors without a code,
+// like those of the RPC server, are internal errors
+func decodeError(err rpc.ServerError) (status.Code, string) {
+	code, msg, ok := strings.Cut(string(err), codeSeparator)
+	if !ok || code == "" || strings.TrimFunc(code, func(r rune) bool { return r == '_' || (r >= 'A' && r <= 'Z') }) != "" {
+		return status.Internal, string(err)
+	}
+	return status.Code(code), msg
+}
+
+type ApplyReply struct {
+	Resource        *models.Resource
+	RequiresReplace bool
+	Conflicts       []string
+	Deleted         bool
+	Status          *Status
+}
+
+type ReadReply struct {
+	Resource *models.Resource
+	Status   *Status
+}
+
+type ImportReply struct {
+	Resource *models.Resource
+	Status   *Status
+}
+
+type DeleteReply struct {
+	Status *Status
+}
+
+// WatchReply contains the ID of the watch started in the plugin and IDs of resources watched. Events of each
+// resource are pulled by NextArgs with the index of the resource. The watch is not supported if the ID is empty
+type WatchReply struct {
+	ID          string
+	ResourceIDs []string
+	Status      *Status
+}
+
+// NextArgs pulls the next event of a resource watched
+type NextArgs struct {
+	ID    string
+	Index int
+}
+
+// NextReply contains the next event of a resource watched, or Done if there are no more events
+type NextReply struct {
+	Event *Event
+	Done  bool
+}
+
+// StopArgs stops a watch started in the plugin
+type StopArgs struct {
+	ID string
+}
+
+type StopReply struct{}
+
+// Event is the wire format of watch.Event
+type Event struct {
+	Type   k8swatch.EventType
+	Object map[string]interface{}
+}
+
+func newEvent(e k8swatch.Event) (*Event, error) {
+	if u, ok := e.Object.(*unstructured.Unstructured); ok {
+		return &Event{Type: e.Type, Object: u.Object}, nil
+	}
+	obj, err := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(e.Object)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{Type: e.Type, Object: obj}, nil
+}
+
+func (e *Event) toEvent() k8swatch.Event {
+	return k8swatch.Event{Type: e.Type, Object: &unstructured.Unstructured{Object: e.Object}}
+}
@@ -0,0 +1,160 @@
+// This is a sample runtime plugin managing local files as resources of the type "File", whose attributes are the
+// path and the content of the file. Relative paths are resolved against the stack directory.
+//
+// Build it into the plugins directory to make Kusion apply File resources:
+//
+//	go build -o ~/.kusion/plugins/kusion-runtime-File ./pkg/engine/runtime/plugin/sample
+//
+// or into the plugins directory of a project, which is configured in project.yaml like:
+//
+//	plugins:
+//	  dir: plugins
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+
+	"kusionstack.io/kusion/pkg/engine/printers"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/engine/runtime/plugin"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+// File is the resource type served by this plugin
+const File models.Type = "File"
+
+func main() {
+	if err := plugin.Serve(File, &FileRuntime{}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// FileRuntime manages local files
+type FileRuntime struct{}
+
+var _ runtime.Runtime = (*FileRuntime)(nil)
+
+func (f *FileRuntime) Apply(ctx context.Context, request *runtime.ApplyRequest) *runtime.ApplyResponse {
+	path, content, err := fileAttributes(request.PlanResource, request.Stack)
+	if err != nil {
+		return &runtime.ApplyResponse{Status: status.NewErrorStatusWithCode(status.IllegalManifest, err)}
+	}
+	if !request.DryRun {
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			err = os.WriteFile(path, []byte(content), 0o644)
+		}
+		if err != nil {
+			return &runtime.ApplyResponse{Status: status.NewErrorStatus(err)}
+		}
+	}
+	return &runtime.ApplyResponse{Resource: fileResource(request.PlanResource, content)}
+}
+
+func (f *FileRuntime) Read(ctx context.Context, request *runtime.ReadRequest) *runtime.ReadResponse {
+	resource := request.PlanResource
+	if resource == nil {
+		resource = request.PriorResource
+	}
+	live, err := readFile(resource, request.Stack)
+	if err != nil {
+		return &runtime.ReadResponse{Status: status.NewErrorStatus(err)}
+	}
+	return &runtime.ReadResponse{Resource: live}
+}
+
+func (f *FileRuntime) Import(ctx context.Context, request *runtime.ImportRequest) *runtime.ImportResponse {
+	live, err := readFile(request.PlanResource, request.Stack)
+	if err != nil {
+		return &runtime.ImportResponse{Status: status.NewErrorStatus(err)}
+	}
+	if live == nil {
+		return &runtime.ImportResponse{Status: status.NewErrorStatusWithMsg(status.NotFound,
+			fmt.Sprintf("file of resource %s doesn't exist", request.PlanResource.ResourceKey()))}
+	}
+	return &runtime.ImportResponse{Resource: live}
+}
+
+func (f *FileRuntime) Delete(ctx context.Context, request *runtime.DeleteRequest) *runtime.DeleteResponse {
+	path, _, err := fileAttributes(request.Resource, request.Stack)
+	if err != nil {
+		return &runtime.DeleteResponse{Status: status.NewErrorStatusWithCode(status.IllegalManifest, err)}
+	}
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return &runtime.DeleteResponse{Status: status.NewErrorStatus(err)}
+	}
+	return &runtime.DeleteResponse{}
+}
+
+// Watch reports the file is ready at once, since it is written synchronously
+func (f *FileRuntime) Watch(ctx context.Context, request *runtime.WatchRequest) *runtime.WatchResponse {
+	path, _, err := fileAttributes(request.Resource, request.Stack)
+	if err != nil {
+		return &runtime.WatchResponse{Status: status.NewErrorStatusWithCode(status.IllegalManifest, err)}
+	}
+	eventCh := make(chan k8swatch.Event, 1)
+	eventCh <- k8swatch.Event{
+		Type: printers.READY,
+		Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":     string(File),
+			"metadata": map[string]interface{}{"name": filepath.Base(path)},
+			"status":   map[string]interface{}{"message": "written to " + path},
+		}},
+	}
+	close(eventCh)
+
+	watchers := runtime.NewWatchers()
+	watchers.Insert(request.Resource.ResourceKey(), eventCh)
+	return &runtime.WatchResponse{Watchers: watchers}
+}
+
+// fileAttributes returns the absolute path and the content of the file resource
+func fileAttributes(resource *models.Resource, stack *projectstack.Stack) (string, string, error) {
+	if resource == nil {
+		return "", "", errors.New("resource is nil")
+	}
+	path, _ := resource.Attributes["path"].(string)
+	if path == "" {
+		return "", "", fmt.Errorf("path is required in resource %s", resource.ResourceKey())
+	}
+	if !filepath.IsAbs(path) && stack != nil {
+		path = filepath.Join(stack.GetPath(), path)
+	}
+	content, _ := resource.Attributes["content"].(string)
+	return path, content, nil
+}
+
+// readFile returns the live resource of the file, and nil if the file doesn't exist
+func readFile(resource *models.Resource, stack *projectstack.Stack) (*models.Resource, error) {
+	path, _, err := fileAttributes(resource, stack)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return fileResource(resource, string(content)), nil
+}
+
+// fileResource returns a copy of the resource with the content of the file
+func fileResource(resource *models.Resource, content string) *models.Resource {
+	live := *resource
+	live.Attributes = map[string]interface{}{
+		"path":    resource.Attributes["path"],
+		"content": content,
+	}
+	return &live
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"kusionstack.io/kusion/pkg/engine/runtime/plugin"
+	"kusionstack.io/kusion/pkg/engine/runtime/plugin/plugintest"
+	"kusionstack.io/kusion/pkg/models"
+)
+
+func TestFileRuntimeConformance(t *testing.T) {
+	server, client := net.Pipe()
+	go func() {
+		_ = plugin.ServeConn(File, &FileRuntime{}, server)
+	}()
+	rt, err := plugin.NewRuntime(File, client)
+	require.NoError(t, err)
+	defer client.Close()
+
+	plugintest.Conformance(t, rt, &models.Resource{
+		ID:         "file:hello",
+		Type:       File,
+		Attributes: map[string]interface{}{"path": "hello.txt", "content": "hello kusion"},
+	})
+}
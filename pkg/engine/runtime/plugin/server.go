@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"sync"
+
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/status"
+)
+
+// Serve serves the runtime of the resource type as a plugin over stdin and stdout, and returns when stdin is closed.
+// Plugins must not write anything else to stdout, and logs should be written to stderr
+func Serve(typ models.Type, rt runtime.Runtime) error {
+	return ServeConn(typ, rt, &stdio{})
+}
+
+// ServeConn serves the runtime of the resource type as a plugin over the connection until it is closed
+func ServeConn(typ models.Type, rt runtime.Runtime, conn io.ReadWriteCloser) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(ServiceName, &runtimeServer{typ: typ, runtime: rt, watches: map[string]*watch{}}); err != nil {
+		return err
+	}
+	server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	return nil
+}
+
+// stdio is the connection of the plugin process to Kusion
+type stdio struct{}
+
+func (s *stdio) Read(p []byte) (int, error) {
+	return os.Stdin.Read(p)
+}
+
+func (s *stdio) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+func (s *stdio) Close() error {
+	if err := os.Stdin.Close(); err != nil {
+		return err
+	}
+	return os.Stdout.Close()
+}
+
+// runtimeServer serves RPC calls from Kusion with the runtime
+type runtimeServer struct {
+	typ     models.Type
+	runtime runtime.Runtime
+
+	lock    sync.Mutex
+	seq     int
+	watches map[string]*watch
+}
+
+// watch is a watch started in the plugin, whose events are pulled by Kusion
+type watch struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	watchers []<-chan k8swatch.Event
+}
+
+func (s *runtimeServer) Handshake(args *HandshakeArgs, reply *HandshakeReply) error {
+	*reply = HandshakeReply{ProtocolVersion: ProtocolVersion, Type: s.typ}
+	return nil
+}
+
+func (s *runtimeServer) Apply(args *runtime.ApplyRequest, reply *ApplyReply) error {
+	rsp := s.runtime.Apply(context.Background(), args)
+	*reply = ApplyReply{Resource: rsp.Resource, RequiresReplace: rsp.RequiresReplace, Conflicts: rsp.Conflicts, Deleted: rsp.Deleted, Status: newStatus(rsp.Status)}
+	return nil
+}
+
+func (s *runtimeServer) Read(args *runtime.ReadRequest, reply *ReadReply) error {
+	rsp := s.runtime.Read(context.Background(), args)
+	*reply = ReadReply{Resource: rsp.Resource, Status: newStatus(rsp.Status)}
+	return nil
+}
+
+func (s *runtimeServer) Import(args *runtime.ImportRequest, reply *ImportReply) error {
+	rsp := s.runtime.Import(context.Background(), args)
+	*reply = ImportReply{Resource: rsp.Resource, Status: newStatus(rsp.Status)}
+	return nil
+}
+
+func (s *runtimeServer) Delete(args *runtime.DeleteRequest, reply *DeleteReply) error {
+	rsp := s.runtime.Delete(context.Background(), args)
+	*reply = DeleteReply{Status: newStatus(rsp.Status)}
+	return nil
+}
+
+func (s *runtimeServer) Watch(args *runtime.WatchRequest, reply *WatchReply) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	rsp := s.runtime.Watch(ctx, args)
+	if rsp == nil || rsp.Watchers == nil {
+		cancel()
+		if rsp != nil {
+			reply.Status = newStatus(rsp.Status)
+		}
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.seq++
+	id := fmt.Sprintf("%d", s.seq)
+	s.watches[id] = &watch{ctx: ctx, cancel: cancel, watchers: rsp.Watchers.Watchers}
+	*reply = WatchReply{ID: id, ResourceIDs: rsp.Watchers.IDs, Status: newStatus(rsp.Status)}
+	return nil
+}
+
+func (s *runtimeServer) Next(args *NextArgs, reply *NextReply) error {
+	s.lock.Lock()
+	w := s.watches[args.ID]
+	s.lock.Unlock()
+	if w == nil || args.Index < 0 || args.Index >= len(w.watchers) {
+		reply.Done = true
+		return nil
+	}
+
+	select {
+	case e, ok := <-w.watchers[args.Index]:
+		if !ok {
+			reply.Done = true
+			return nil
+		}
+		event, err := newEvent(e)
+		if err != nil {
+			return encodeError(status.Internal, fmt.Errorf("convert watch event failed: %w", err))
+		}
+		reply.Event = event
+	case <-w.ctx.Done():
+		reply.Done = true
+	}
+	return nil
+}
+
+func (s *runtimeServer) Stop(args *StopArgs, reply *StopReply) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if w := s.watches[args.ID]; w != nil {
+		w.cancel()
+		delete(s.watches, args.ID)
+	}
+	return nil
+}
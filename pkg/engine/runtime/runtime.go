@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/apimachinery/pkg/watch"
 
@@ -13,6 +14,7 @@ import (
 const (
 	Kubernetes models.Type = "Kubernetes"
 	Terraform  models.Type = "Terraform"
+	Helm       models.Type = "Helm"
 )
 
 // Runtime represents an actual infrastructure runtime managed by Kusion and every runtime implements this interface can be orchestrated
@@ -54,12 +56,40 @@ type ApplyRequest struct {
 
 	// DryRun means this a dry-run request and will not make any changes in actual infra
 	DryRun bool
+
+	// ReadinessTimeout is the max time to wait for the applied Resource to be ready, such as a Deployment completing
+	// its rollout. The Resource is not waited if it is 0, and runtimes that can't tell readiness may ignore it
+	ReadinessTimeout time.Duration
+
+	// Replace means the Resource can't be updated in place and must be replaced. Runtimes delete the old Resource
+	// before creating the new one, or the opposite if CreateBeforeDestroy is set in the Lifecycle of PlanResource
+	Replace bool
+
+	// ForceConflicts takes the ownership of fields managed by others when the runtime tracks the ownership of
+	// fields, like the Kubernetes runtime with server-side apply. Applying such fields fails without it
+	ForceConflicts bool
+
+	// Progress reports the progress of applying the Resource while it is being applied, like "Still creating...
+	// [10s elapsed]". It may be nil, and runtimes that can't tell the progress ignore it
+	Progress func(message string) `json:"-"`
 }
 
 type ApplyResponse struct {
 	// Resource is the result returned by Runtime
 	Resource *models.Resource
 
+	// RequiresReplace is reported in a dry-run request if the Resource can't be updated in place, such as
+	// changing an immutable field
+	RequiresReplace bool
+
+	// Conflicts are reported in a dry-run request with fields of the Resource owned by other managers that the
+	// request would overwrite, like ".spec.replicas (kube-controller-manager)"
+	Conflicts []string
+
+	// Deleted is reported with an error if the old Resource has been deleted but the new one failed to be created
+	// when replacing it, so that the Resource is removed from the State
+	Deleted bool
+
 	// Status contains messages will show to users
 	Status status.Status
 }
@@ -89,6 +119,10 @@ type ImportRequest struct {
 
 	// Stack contains info about where this command is invoked
 	Stack *projectstack.Stack
+
+	// ID is the identifier of this Resource in the actual infrastructure, such as the ID of a cloud resource.
+	// It is required by runtimes that can't locate the Resource by PlanResource, like the Terraform runtime
+	ID string
 }
 
 type ImportResponse struct {
@@ -115,6 +149,9 @@ type DeleteResponse struct {
 type WatchRequest struct {
 	// Resource represents the resource we want to watch from the actual infra
 	Resource *models.Resource
+
+	// Stack contains info about where this command is invoked
+	Stack *projectstack.Stack
 }
 
 type WatchResponse struct {
@@ -2,12 +2,18 @@ package terraform
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
 
+	"kusionstack.io/kusion/pkg/engine/printers"
 	"kusionstack.io/kusion/pkg/engine/runtime"
 	"kusionstack.io/kusion/pkg/engine/runtime/terraform/tfops"
 	"kusionstack.io/kusion/pkg/log"
@@ -45,17 +51,17 @@ func (t *TerraformRuntime) Apply(ctx context.Context, request *runtime.ApplyRequ
 	t.WorkSpace.SetResource(plan)
 
 	if err := t.WorkSpace.WriteHCL(); err != nil {
-		return &runtime.ApplyResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+		return &runtime.ApplyResponse{Resource: nil, Status: errorStatus(err)}
 	}
 
 	_, err := os.Stat(filepath.Join(tfCacheDir, tfops.LockHCLFile))
 	if err != nil {
 		if os.IsNotExist(err) {
 			if err := t.WorkSpace.InitWorkSpace(ctx); err != nil {
-				return &runtime.ApplyResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+				return &runtime.ApplyResponse{Resource: nil, Status: errorStatus(err)}
 			}
 		} else {
-			return &runtime.ApplyResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+			return &runtime.ApplyResponse{Resource: nil, Status: errorStatus(err)}
 		}
 	}
 
@@ -63,7 +69,7 @@ func (t *TerraformRuntime) Apply(ctx context.Context, request *runtime.ApplyRequ
 	if request.DryRun {
 		pr, err := t.WorkSpace.Plan(ctx)
 		if err != nil {
-			return &runtime.ApplyResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+			return &runtime.ApplyResponse{Resource: nil, Status: errorStatus(err)}
 		}
 		module := pr.PlannedValues.RootModule
 		if len(module.Resources) == 0 {
@@ -79,19 +85,31 @@ func (t *TerraformRuntime) Apply(ctx context.Context, request *runtime.ApplyRequ
 				DependsOn:  plan.DependsOn,
 				Extensions: plan.Extensions,
 			},
-			Status: nil,
+			RequiresReplace: pr.RequiresReplace(),
+			Status:          nil,
 		}
 	}
 
+	// report the progress with log lines of terraform apply as soon as they are output
+	if request.Progress != nil {
+		t.WorkSpace.SetApplyLogHandler(func(info *tfops.TerraformInfo) {
+			if message := progressMessage(info); message != "" {
+				request.Progress(message)
+			}
+		})
+		defer t.WorkSpace.SetApplyLogHandler(nil)
+	}
+
+	// terraform replaces the resource by itself in the order decided by the lifecycle block written in WriteHCL
 	tfstate, err := t.WorkSpace.Apply(ctx)
 	if err != nil {
-		return &runtime.ApplyResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+		return &runtime.ApplyResponse{Resource: nil, Status: errorStatus(err)}
 	}
 
 	// get terraform provider version
 	providerAddr, err := t.WorkSpace.GetProvider()
 	if err != nil {
-		return &runtime.ApplyResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+		return &runtime.ApplyResponse{Resource: nil, Status: errorStatus(err)}
 	}
 
 	r := tfops.ConvertTFState(tfstate, providerAddr)
@@ -143,27 +161,27 @@ func (t *TerraformRuntime) Read(ctx context.Context, request *runtime.ReadReques
 	t.WorkSpace.SetResource(planResource)
 
 	if err := t.WorkSpace.WriteHCL(); err != nil {
-		return &runtime.ReadResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+		return &runtime.ReadResponse{Resource: nil, Status: errorStatus(err)}
 	}
 	_, err := os.Stat(filepath.Join(tfCacheDir, tfops.LockHCLFile))
 	if err != nil {
 		if os.IsNotExist(err) {
 			if err := t.WorkSpace.InitWorkSpace(ctx); err != nil {
-				return &runtime.ReadResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+				return &runtime.ReadResponse{Resource: nil, Status: errorStatus(err)}
 			}
 		} else {
-			return &runtime.ReadResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+			return &runtime.ReadResponse{Resource: nil, Status: errorStatus(err)}
 		}
 	}
 
 	// priorResource overwrite tfstate in workspace
 	if err = t.WorkSpace.WriteTFState(priorResource); err != nil {
-		return &runtime.ReadResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+		return &runtime.ReadResponse{Resource: nil, Status: errorStatus(err)}
 	}
 
 	tfstate, err = t.WorkSpace.RefreshOnly(ctx)
 	if err != nil {
-		return &runtime.ReadResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+		return &runtime.ReadResponse{Resource: nil, Status: errorStatus(err)}
 	}
 
 	if tfstate == nil || tfstate.Values == nil {
@@ -173,7 +191,7 @@ func (t *TerraformRuntime) Read(ctx context.Context, request *runtime.ReadReques
 	// get terraform provider addr
 	providerAddr, err := t.WorkSpace.GetProvider()
 	if err != nil {
-		return &runtime.ReadResponse{Resource: nil, Status: status.NewErrorStatus(err)}
+		return &runtime.ReadResponse{Resource: nil, Status: errorStatus(err)}
 	}
 
 	r := tfops.ConvertTFState(tfstate, providerAddr)
@@ -189,10 +207,64 @@ func (t *TerraformRuntime) Read(ctx context.Context, request *runtime.ReadReques
 	}
 }
 
+// Import an existing terraform resource by the terraform import command
 func (t *TerraformRuntime) Import(ctx context.Context, request *runtime.ImportRequest) *runtime.ImportResponse {
-	// TODO change to terraform cli import
-	log.Info("skip import TF resource:%s", request.PlanResource.ID)
-	return nil
+	plan := request.PlanResource
+	if request.ID == "" {
+		return &runtime.ImportResponse{Resource: nil, Status: status.NewErrorStatusWithMsg(status.InvalidArgument,
+			fmt.Sprintf("the ID of resource %s in the actual infrastructure is required to import a terraform resource", plan.ResourceKey()))}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stackPath := request.Stack.GetPath()
+	tfCacheDir := filepath.Join(stackPath, "."+plan.ResourceKey())
+	t.WorkSpace.SetStackDir(stackPath)
+	t.WorkSpace.SetCacheDir(tfCacheDir)
+	t.WorkSpace.SetResource(plan)
+
+	if err := t.WorkSpace.WriteHCL(); err != nil {
+		return &runtime.ImportResponse{Resource: nil, Status: errorStatus(err)}
+	}
+
+	_, err := os.Stat(filepath.Join(tfCacheDir, tfops.LockHCLFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			if err := t.WorkSpace.InitWorkSpace(ctx); err != nil {
+				return &runtime.ImportResponse{Resource: nil, Status: errorStatus(err)}
+			}
+		} else {
+			return &runtime.ImportResponse{Resource: nil, Status: errorStatus(err)}
+		}
+	}
+
+	tfstate, err := t.WorkSpace.Import(ctx, request.ID)
+	if err != nil {
+		return &runtime.ImportResponse{Resource: nil, Status: errorStatus(err)}
+	}
+	if tfstate == nil || tfstate.Values == nil || len(tfstate.Values.RootModule.Resources) == 0 {
+		return &runtime.ImportResponse{Resource: nil, Status: status.NewErrorStatus(
+			fmt.Errorf("can not find resource %s with ID %s in the actual infrastructure", plan.ResourceKey(), request.ID))}
+	}
+
+	// get terraform provider addr
+	providerAddr, err := t.WorkSpace.GetProvider()
+	if err != nil {
+		return &runtime.ImportResponse{Resource: nil, Status: errorStatus(err)}
+	}
+
+	r := tfops.ConvertTFState(tfstate, providerAddr)
+	return &runtime.ImportResponse{
+		Resource: &models.Resource{
+			ID:         plan.ID,
+			Type:       plan.Type,
+			Attributes: r.Attributes,
+			DependsOn:  plan.DependsOn,
+			Extensions: plan.Extensions,
+		},
+		Status: nil,
+	}
 }
 
 // Delete terraform resource and remove workspace
@@ -206,18 +278,119 @@ func (t *TerraformRuntime) Delete(ctx context.Context, request *runtime.DeleteRe
 	t.WorkSpace.SetCacheDir(tfCacheDir)
 	t.WorkSpace.SetResource(request.Resource)
 	if err := t.WorkSpace.Destroy(ctx); err != nil {
-		return &runtime.DeleteResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.DeleteResponse{Status: errorStatus(err)}
 	}
 
 	// delete tf directory after destroy operation is success
 	err := os.RemoveAll(tfCacheDir)
 	if err != nil {
-		return &runtime.DeleteResponse{Status: status.NewErrorStatus(err)}
+		return &runtime.DeleteResponse{Status: errorStatus(err)}
 	}
 	return &runtime.DeleteResponse{Status: nil}
 }
 
-// Watch terraform resource
+// Watch terraform resource by following the JSON-formatted output of the latest terraform apply.
+// Every log line is sent as an event whose object carries the message in status.message. The progress is also
+// reported by Apply while applying, and this shows the result of the apply finished
 func (t *TerraformRuntime) Watch(ctx context.Context, request *runtime.WatchRequest) *runtime.WatchResponse {
-	return nil
+	if request.Stack == nil {
+		return nil
+	}
+	resource := request.Resource
+	tfCacheDir := filepath.Join(request.Stack.GetPath(), "."+resource.ResourceKey())
+
+	eventCh := make(chan k8swatch.Event, 1)
+	watchers := runtime.NewWatchers()
+	watchers.Insert(resource.ResourceKey(), eventCh)
+
+	// The apply log is removed when an apply starts, so there is no apply log if the resource was not applied
+	if _, err := os.Stat(filepath.Join(tfCacheDir, tfops.ApplyLogFile)); os.IsNotExist(err) {
+		eventCh <- watchEvent(resource, &tfops.TerraformInfo{
+			Message: "No changes applied by terraform",
+			Type:    tfops.MessageChangeSummary,
+		})
+		close(eventCh)
+		return &runtime.WatchResponse{Watchers: watchers}
+	}
+
+	infoCh := t.WorkSpace.WatchApplyLog(ctx, tfCacheDir)
+	go func() {
+		defer close(eventCh)
+		for info := range infoCh {
+			select {
+			case eventCh <- watchEvent(resource, info):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return &runtime.WatchResponse{Watchers: watchers}
+}
+
+// progressMessage returns the message of the terraform apply log line describing the progress of resources, or an
+// empty string for other log lines
+func progressMessage(info *tfops.TerraformInfo) string {
+	switch info.Type {
+	case tfops.MessageApplyStart, tfops.MessageApplyProgress, tfops.MessageApplyComplete, tfops.MessageApplyErrored:
+		return info.Message
+	case tfops.MessageDiagnostic:
+		if info.Diagnostic.Severity == "error" {
+			return "Error: " + info.Diagnostic.Summary
+		}
+	}
+	return ""
+}
+
+// watchEvent converts a terraform apply log line to a watch event. Events of completion are marked as printers.READY
+func watchEvent(resource *models.Resource, info *tfops.TerraformInfo) k8swatch.Event {
+	kind, _ := resource.Extensions["resourceType"].(string)
+	names := strings.Split(resource.ResourceKey(), ":")
+	name := names[len(names)-1]
+	if info.Hook != nil {
+		kind = info.Hook.Resource.ResourceType
+		name = info.Hook.Resource.ResourceName
+	}
+
+	eventType := k8swatch.Modified
+	message := info.Message
+	switch info.Type {
+	case tfops.MessageApplyStart:
+		eventType = k8swatch.Added
+	case tfops.MessageApplyComplete, tfops.MessageChangeSummary:
+		eventType = printers.READY
+	case tfops.MessageApplyErrored:
+		eventType = printers.READY
+		message = "Error: " + message
+	case tfops.MessageDiagnostic:
+		if info.Diagnostic.Severity == "error" {
+			eventType = printers.READY
+			message = "Error: " + info.Diagnostic.Summary
+		}
+	}
+
+	return k8swatch.Event{
+		Type: eventType,
+		Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind": kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"status": map[string]interface{}{
+				"message": message,
+			},
+		}},
+	}
+}
+
+// errorStatus converts the error to a status, whose code tells transient errors reported by Terraform, such as
+// throttling of provider APIs, from others
+func errorStatus(err error) status.Status {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.NewErrorStatusWithCode(status.Canceled, err)
+	case tfops.IsTransient(err):
+		return status.NewErrorStatusWithCode(status.Unavailable, err)
+	default:
+		return status.NewErrorStatus(err)
+	}
 }
@@ -3,6 +3,7 @@ package terraform
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,11 +13,15 @@ import (
 	"github.com/bytedance/mockey"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
 
+	"kusionstack.io/kusion/pkg/engine/printers"
 	"kusionstack.io/kusion/pkg/engine/runtime"
 	"kusionstack.io/kusion/pkg/engine/runtime/terraform/tfops"
 	"kusionstack.io/kusion/pkg/models"
 	"kusionstack.io/kusion/pkg/projectstack"
+	"kusionstack.io/kusion/pkg/status"
 )
 
 var testResource = models.Resource{
@@ -80,6 +85,23 @@ func TestTerraformRuntime(t *testing.T) {
 		assert.Equalf(t, nil, response.Status, "Execute(%v)", "Read")
 	})
 
+	mockey.PatchConvey("Import", t, func() {
+		mockApplySetup()
+		response := tfRuntime.Import(context.TODO(), &runtime.ImportRequest{PlanResource: &testResource, Stack: stack})
+		assert.NotNil(t, response.Status, "import without ID")
+
+		mockey.Mock((*tfops.WorkSpace).Import).To(func(ws *tfops.WorkSpace, ctx context.Context, id string) (*tfops.StateRepresentation, error) {
+			s := &tfops.StateRepresentation{}
+			err := json.Unmarshal([]byte(`{"values":{"root_module":{"resources":[{"name":"kusion_example","type":"local_file",`+
+				`"values":{"id":"`+id+`","content":"kusion","filename":"test.txt"}}]}}}`), s)
+			return s, err
+		}).Build()
+		response = tfRuntime.Import(context.TODO(), &runtime.ImportRequest{PlanResource: &testResource, Stack: stack, ID: "fake-id"})
+		assert.Equalf(t, nil, response.Status, "Execute(%v)", "Import")
+		assert.Equal(t, testResource.ID, response.Resource.ID)
+		assert.Equal(t, "fake-id", response.Resource.Attributes["id"])
+	})
+
 	mockey.PatchConvey("Delete", t, func() {
 		mockey.Mock((*tfops.WorkSpace).InitWorkSpace).To(func(ws *tfops.WorkSpace, ctx context.Context) error {
 			return nil
@@ -104,3 +126,83 @@ func mockApplySetup() {
 		return "registry.terraform.io/hashicorp/local/2.2.3", nil
 	}).Build()
 }
+
+func TestTerraformRuntime_Watch(t *testing.T) {
+	tfRuntime := TerraformRuntime{
+		WorkSpace: *tfops.NewWorkSpace(afero.Afero{Fs: afero.NewOsFs()}),
+		mu:        &sync.Mutex{},
+	}
+	assert.Nil(t, tfRuntime.Watch(context.TODO(), &runtime.WatchRequest{Resource: &testResource}))
+
+	stack := &projectstack.Stack{
+		StackConfiguration: projectstack.StackConfiguration{Name: "fakeStack"},
+		Path:               t.TempDir(),
+	}
+	// the resource is not applied by terraform if there is no apply log
+	rsp := tfRuntime.Watch(context.TODO(), &runtime.WatchRequest{Resource: &testResource, Stack: stack})
+	var events []k8swatch.Event
+	for e := range rsp.Watchers.Watchers[0] {
+		events = append(events, e)
+	}
+	assert.Len(t, events, 1)
+	assert.Equal(t, printers.READY, events[0].Type)
+
+	tfCacheDir := filepath.Join(stack.GetPath(), "."+testResource.ResourceKey())
+	assert.NoError(t, os.MkdirAll(tfCacheDir, os.ModePerm))
+	log := `{"@message":"local_file.kusion_example: Creating...","type":"apply_start","hook":{"resource":{"resource_type":"local_file","resource_name":"kusion_example"}}}
+{"@message":"Apply complete! Resources: 1 added, 0 changed, 0 destroyed.","type":"change_summary"}
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(tfCacheDir, tfops.ApplyLogFile), []byte(log), 0o600))
+
+	rsp = tfRuntime.Watch(context.TODO(), &runtime.WatchRequest{Resource: &testResource, Stack: stack})
+	assert.NotNil(t, rsp)
+	assert.Equal(t, []string{testResource.ResourceKey()}, rsp.Watchers.IDs)
+
+	events = nil
+	for e := range rsp.Watchers.Watchers[0] {
+		events = append(events, e)
+	}
+	assert.Len(t, events, 2)
+	assert.Equal(t, k8swatch.Added, events[0].Type)
+	assert.Equal(t, printers.READY, events[1].Type)
+	o := events[1].Object.(*unstructured.Unstructured)
+	assert.Equal(t, "local_file", o.GetKind())
+	assert.Equal(t, "kusion_example", o.GetName())
+	message, _, _ := unstructured.NestedString(o.Object, "status", "message")
+	assert.Equal(t, "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.", message)
+}
+
+func TestWatchEvent(t *testing.T) {
+	e := watchEvent(&testResource, &tfops.TerraformInfo{
+		Message: "local_file.kusion_example: Creation errored after 1s",
+		Type:    tfops.MessageApplyErrored,
+	})
+	assert.Equal(t, printers.READY, e.Type)
+	o := e.Object.(*unstructured.Unstructured)
+	assert.Equal(t, "local_file", o.GetKind())
+	assert.Equal(t, "kusion_example", o.GetName())
+	message, _, _ := unstructured.NestedString(o.Object, "status", "message")
+	assert.Equal(t, "Error: local_file.kusion_example: Creation errored after 1s", message)
+
+	e = watchEvent(&testResource, &tfops.TerraformInfo{Message: "Still creating... [10s elapsed]", Type: tfops.MessageApplyProgress})
+	assert.Equal(t, k8swatch.Modified, e.Type)
+}
+
+func Test_progressMessage(t *testing.T) {
+	assert.Equal(t, "local_file.kusion_example: Creating...", progressMessage(&tfops.TerraformInfo{
+		Message: "local_file.kusion_example: Creating...",
+		Type:    tfops.MessageApplyStart,
+	}))
+	assert.Equal(t, "Error: timeout", progressMessage(&tfops.TerraformInfo{
+		Type:       tfops.MessageDiagnostic,
+		Diagnostic: tfops.Diagnostic{Severity: "error", Summary: "timeout"},
+	}))
+	assert.Equal(t, "", progressMessage(&tfops.TerraformInfo{Message: "Terraform 1.3.7", Type: "version"}))
+}
+
+func Test_errorStatus(t *testing.T) {
+	throttling := []byte(`{"@level":"error","@message":"Error: creating VPC","@module":"terraform.ui","diagnostic":{"severity":"error","summary":"creating VPC","detail":"Throttling: Rate exceeded"},"type":"diagnostic"}`)
+	assert.Equal(t, status.Unavailable, errorStatus(tfops.TFError(throttling)).Code())
+	assert.Equal(t, status.Canceled, errorStatus(fmt.Errorf("apply failed: %w", context.Canceled)).Code())
+	assert.Equal(t, status.Internal, errorStatus(errors.New("exit status 1")).Code())
+}
@@ -134,3 +134,23 @@ type ResourceAttr struct {
 	Resource string          `json:"resource"`
 	Attr     json.RawMessage `json:"attribute"`
 }
+
+// RequiresReplace returns whether any resource in the plan will be replaced, which is planned as a pair of
+// delete and create actions
+func (pr *PlanRepresentation) RequiresReplace() bool {
+	for _, rc := range pr.ResourceChanges {
+		var deleted, created bool
+		for _, a := range rc.Change.Actions {
+			switch a {
+			case "delete":
+				deleted = true
+			case "create":
+				created = true
+			}
+		}
+		if deleted && created {
+			return true
+		}
+	}
+	return false
+}
@@ -19,6 +19,7 @@ type TerraformInfo struct {
 	Timestamp  time.Time  `json:"@timestamp"`
 	Diagnostic Diagnostic `json:"diagnostic"`
 	Type       string     `json:"type"`
+	Hook       *Hook      `json:"hook,omitempty"`
 }
 
 // Diagnostic schema from https://github.com/hashicorp/terraform/blob/main/internal/command/views/json/diagnostic.go.
@@ -123,8 +124,54 @@ func TFError(infos []byte) error {
 			if v.Diagnostic.Snippet != nil {
 				msg += fmt.Sprintf("Context:%s\nCode:%s", v.Diagnostic.Snippet.Context, v.Diagnostic.Snippet.Code)
 			}
-			return errors.New(msg)
+			return &DiagnosticError{Summary: v.Diagnostic.Summary, Detail: v.Diagnostic.Detail, msg: msg}
 		}
 	}
 	return nil
 }
+
+// DiagnosticError is an error diagnostic reported by Terraform CLI
+type DiagnosticError struct {
+	Summary string
+	Detail  string
+	msg     string
+}
+
+func (e *DiagnosticError) Error() string {
+	return e.msg
+}
+
+// transientErrorPatterns are lower case patterns of errors that may succeed after a while, such as throttling of
+// provider APIs and temporary network failures
+var transientErrorPatterns = []string{
+	"throttl",
+	"rate exceeded",
+	"rate limit",
+	"too many requests",
+	"request limit exceeded",
+	"service unavailable",
+	"serviceunavailable",
+	"connection reset by peer",
+	"tls handshake timeout",
+	"i/o timeout",
+}
+
+// IsTransient returns whether the error is a transient error reported by Terraform, which is worth retrying.
+// Diagnostic errors are checked by their summary and detail, and other errors are checked by their messages
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	var diagErr *DiagnosticError
+	if errors.As(err, &diagErr) {
+		msg = diagErr.Summary + " " + diagErr.Detail
+	}
+	msg = strings.ToLower(msg)
+	for _, p := range transientErrorPatterns {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package tfops
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -71,3 +72,25 @@ func TestTFError(t *testing.T) {
 		})
 	}
 }
+
+func TestIsTransient(t *testing.T) {
+	throttling := `{"@level":"error","@message":"Error: creating EC2 Instance","@module":"terraform.ui","diagnostic":{"severity":"error","summary":"creating EC2 Instance","detail":"RequestLimitExceeded: Request limit exceeded."},"type":"diagnostic"}`
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"nil":                {err: nil, want: false},
+		"throttling":         {err: TFError([]byte(throttling)), want: true},
+		"wrapped throttling": {err: fmt.Errorf("apply failed: %w", TFError([]byte(throttling))), want: true},
+		"diagnostic":         {err: TFError([]byte(applyInfos)), want: false},
+		"raw output":         {err: errors.New("dial tcp: i/o timeout"), want: true},
+		"other":              {err: errors.New("exit status 1"), want: false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsTransient(tc.err); got != tc.want {
+				t.Errorf("IsTransient() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
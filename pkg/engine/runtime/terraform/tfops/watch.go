@@ -0,0 +1,161 @@
+package tfops
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"kusionstack.io/kusion/pkg/log"
+)
+
+// ApplyLogFile records the JSON-formatted output of the latest terraform apply in the tf cache directory
+const ApplyLogFile = "apply.log"
+
+// Types of Terraform CLI JSON-formatted log lines, see https://developer.hashicorp.com/terraform/internals/machine-readable-ui
+const (
+	MessageApplyStart    = "apply_start"
+	MessageApplyProgress = "apply_progress"
+	MessageApplyComplete = "apply_complete"
+	MessageApplyErrored  = "apply_errored"
+	MessageDiagnostic    = "diagnostic"
+	MessageChangeSummary = "change_summary"
+)
+
+// Hook represents the hook info of a Terraform CLI JSON-formatted log line, which describes the progress of a resource
+type Hook struct {
+	Resource       HookResource `json:"resource"`
+	Action         string       `json:"action"`
+	IDKey          string       `json:"id_key,omitempty"`
+	IDValue        string       `json:"id_value,omitempty"`
+	ElapsedSeconds float64      `json:"elapsed_seconds,omitempty"`
+}
+
+// HookResource represents the resource in a hook
+type HookResource struct {
+	Addr         string `json:"addr"`
+	ResourceType string `json:"resource_type"`
+	ResourceName string `json:"resource_name"`
+}
+
+// watchInterval is the interval to poll the apply log
+var watchInterval = 500 * time.Millisecond
+
+// openApplyLog truncates the apply log, and returns a writer writing to both the log and the buffer. Log lines are
+// also passed to the apply log handler as soon as they are written if there is one
+func (w *WorkSpace) openApplyLog(buf *bytes.Buffer) (io.Writer, func(), error) {
+	f, err := w.fs.Create(filepath.Join(w.tfCacheDir, ApplyLogFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	writers := []io.Writer{buf, f}
+	if w.applyLogHandler != nil {
+		writers = append(writers, &logLineWriter{handler: w.applyLogHandler})
+	}
+	return io.MultiWriter(writers...), func() { _ = f.Close() }, nil
+}
+
+// logLineWriter parses JSON-formatted log lines written, and calls the handler with each of them. Invalid lines are
+// skipped
+type logLineWriter struct {
+	handler func(info *TerraformInfo)
+	line    []byte
+}
+
+func (l *logLineWriter) Write(p []byte) (int, error) {
+	l.line = append(l.line, p...)
+	for {
+		i := bytes.IndexByte(l.line, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		info := &TerraformInfo{}
+		if err := json.Unmarshal(l.line[:i], info); err == nil {
+			l.handler(info)
+		} else {
+			log.Debugf("skip invalid terraform apply log line: %v", err)
+		}
+		l.line = l.line[i+1:]
+	}
+}
+
+// WatchApplyLog follows the apply log in the tf cache directory and sends every parsed log line to the returned channel.
+// The channel is closed after the apply finishes, which is indicated by the change summary or an error diagnostic,
+// or when the context is done
+func (w *WorkSpace) WatchApplyLog(ctx context.Context, tfCacheDir string) <-chan *TerraformInfo {
+	ch := make(chan *TerraformInfo)
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		wait := func() bool {
+			select {
+			case <-ticker.C:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// wait for the apply log to be created
+		var f afero.File
+		for {
+			var err error
+			f, err = w.fs.Open(filepath.Join(tfCacheDir, ApplyLogFile))
+			if err == nil {
+				break
+			}
+			if !os.IsNotExist(err) {
+				log.Errorf("open terraform apply log failed: %v", err)
+				return
+			}
+			if !wait() {
+				return
+			}
+		}
+		defer f.Close()
+
+		reader := bufio.NewReader(f)
+		var line []byte
+		for {
+			part, err := reader.ReadBytes('\n')
+			line = append(line, part...)
+			if errors.Is(err, io.EOF) {
+				// the line is incomplete, wait for more output
+				if !wait() {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				log.Errorf("read terraform apply log failed: %v", err)
+				return
+			}
+
+			info := &TerraformInfo{}
+			err = json.Unmarshal(line, info)
+			line = nil
+			if err != nil {
+				log.Debugf("skip invalid terraform apply log line: %v", err)
+				continue
+			}
+			select {
+			case ch <- info:
+			case <-ctx.Done():
+				return
+			}
+			if info.Type == MessageChangeSummary || (info.Type == MessageDiagnostic && info.Diagnostic.Severity == "error") {
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,98 @@
+package tfops
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const testApplyLog = `{"@level":"info","@message":"Terraform 1.3.7","type":"version"}
+{"@level":"info","@message":"local_file.kusion_example: Creating...","type":"apply_start","hook":{"resource":{"addr":"local_file.kusion_example","resource_type":"local_file","resource_name":"kusion_example"},"action":"create"}}
+not a json line
+{"@level":"info","@message":"local_file.kusion_example: Creation complete after 0s [id=f3a0]","type":"apply_complete","hook":{"resource":{"addr":"local_file.kusion_example","resource_type":"local_file","resource_name":"kusion_example"},"action":"create","id_key":"id","id_value":"f3a0","elapsed_seconds":0}}
+`
+
+func TestOpenApplyLog(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWorkSpace(afero.Afero{Fs: afero.NewOsFs()})
+	w.SetCacheDir(dir)
+	var messages []string
+	w.SetApplyLogHandler(func(info *TerraformInfo) {
+		messages = append(messages, info.Message)
+	})
+
+	var buf bytes.Buffer
+	out, closeLog, err := w.openApplyLog(&buf)
+	assert.NoError(t, err)
+	// lines are handled once they are complete, even if they are written in pieces
+	_, _ = out.Write([]byte(testApplyLog[:100]))
+	assert.Equal(t, []string{"Terraform 1.3.7"}, messages)
+	_, _ = out.Write([]byte(testApplyLog[100:]))
+	closeLog()
+
+	assert.Equal(t, []string{
+		"Terraform 1.3.7",
+		"local_file.kusion_example: Creating...",
+		"local_file.kusion_example: Creation complete after 0s [id=f3a0]",
+	}, messages)
+	assert.Equal(t, testApplyLog, buf.String())
+	content, err := os.ReadFile(filepath.Join(dir, ApplyLogFile))
+	assert.NoError(t, err)
+	assert.Equal(t, testApplyLog, string(content))
+}
+
+func TestWatchApplyLog(t *testing.T) {
+	watchInterval = 10 * time.Millisecond
+	w := NewWorkSpace(afero.Afero{Fs: afero.NewOsFs()})
+
+	t.Run("follow until change summary", func(t *testing.T) {
+		dir := t.TempDir()
+		ch := w.WatchApplyLog(context.TODO(), dir)
+
+		// the log is created and written after watching
+		path := filepath.Join(dir, ApplyLogFile)
+		assert.NoError(t, os.WriteFile(path, []byte(testApplyLog+`{"@level":"info","@message":"Apply complete!`), 0o600))
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+			defer f.Close()
+			_, _ = f.WriteString(` Resources: 1 added, 0 changed, 0 destroyed.","type":"change_summary"}` + "\n")
+		}()
+
+		var types []string
+		var last *TerraformInfo
+		for info := range ch {
+			types = append(types, info.Type)
+			last = info
+		}
+		assert.Equal(t, []string{"version", MessageApplyStart, MessageApplyComplete, MessageChangeSummary}, types)
+		assert.Equal(t, "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.", last.Message)
+	})
+
+	t.Run("stop at error diagnostic", func(t *testing.T) {
+		dir := t.TempDir()
+		log := `{"@level":"error","@message":"Error: timeout","type":"diagnostic","diagnostic":{"severity":"error","summary":"timeout"}}` + "\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, ApplyLogFile), []byte(log), 0o600))
+
+		var infos []*TerraformInfo
+		for info := range w.WatchApplyLog(context.TODO(), dir) {
+			infos = append(infos, info)
+		}
+		assert.Len(t, infos, 1)
+		assert.Equal(t, "timeout", infos[0].Diagnostic.Summary)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		ch := w.WatchApplyLog(ctx, t.TempDir())
+		cancel()
+		_, ok := <-ch
+		assert.False(t, ok)
+	})
+}
@@ -1,6 +1,7 @@
 package tfops
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -44,6 +45,9 @@ type WorkSpace struct {
 	fs         afero.Afero
 	stackDir   string
 	tfCacheDir string
+
+	// applyLogHandler is called with every JSON-formatted log line while applying
+	applyLogHandler func(info *TerraformInfo)
 }
 
 // SetResource set workspace resource
@@ -66,6 +70,11 @@ func (w *WorkSpace) SetCacheDir(cacheDir string) {
 	w.tfCacheDir = cacheDir
 }
 
+// SetApplyLogHandler set the handler called with every log line of terraform apply as soon as it is output
+func (w *WorkSpace) SetApplyLogHandler(handler func(info *TerraformInfo)) {
+	w.applyLogHandler = handler
+}
+
 func NewWorkSpace(fs afero.Afero) *WorkSpace {
 	return &WorkSpace{
 		fs: fs,
@@ -75,15 +84,46 @@ func NewWorkSpace(fs afero.Afero) *WorkSpace {
 // WriteHCL convert kusion Resource to HCL json
 // and write hcl json to main.tf.json
 func (w *WorkSpace) WriteHCL() error {
+	m, err := w.HCL()
+	if err != nil {
+		return err
+	}
+	hclMain := jsonutil.Marshal2PrettyString(m)
+
+	_, err = w.fs.Stat(w.tfCacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if err := w.fs.MkdirAll(w.tfCacheDir, os.ModePerm); err != nil {
+				return fmt.Errorf("create workspace error: %v", err)
+			}
+		} else {
+			return err
+		}
+	}
+	err = w.fs.WriteFile(filepath.Join(w.tfCacheDir, mainTFFile), []byte(hclMain), 0o600)
+	if err != nil {
+		return fmt.Errorf("write hcl main.tf.json error: %v", err)
+	}
+
+	return nil
+}
+
+// HCL converts kusion Resource to HCL json with the terraform, provider and resource blocks, which is the content
+// of main.tf.json written by WriteHCL
+func (w *WorkSpace) HCL() (map[string]interface{}, error) {
 	provider := strings.Split(w.resource.Extensions["provider"].(string), "/")
 	resourceType := w.resource.Extensions["resourceType"].(string)
 	resourceNames := strings.Split(w.resource.ResourceKey(), ":")
 	if len(resourceNames) < 4 {
-		return fmt.Errorf("illegial resource id:%s in Spec. "+
+		return nil, fmt.Errorf("illegial resource id:%s in Spec. "+
 			"Resource id format: providerNamespace:providerName:resourceType:resourceName", w.resource.ResourceKey())
 	}
+	body, err := w.resourceBody()
+	if err != nil {
+		return nil, err
+	}
 
-	m := map[string]interface{}{
+	return map[string]interface{}{
 		"terraform": map[string]interface{}{
 			"required_providers": map[string]interface{}{
 				provider[len(provider)-2]: map[string]string{
@@ -97,28 +137,28 @@ func (w *WorkSpace) WriteHCL() error {
 		},
 		"resource": map[string]interface{}{
 			resourceType: map[string]interface{}{
-				resourceNames[len(resourceNames)-1]: w.resource.Attributes,
+				resourceNames[len(resourceNames)-1]: body,
 			},
 		},
-	}
-	hclMain := jsonutil.Marshal2PrettyString(m)
+	}, nil
+}
 
-	_, err := w.fs.Stat(w.tfCacheDir)
+// resourceBody returns the body of the resource block in HCL json, which contains attributes of the resource and
+// the lifecycle meta-argument if required
+func (w *WorkSpace) resourceBody() (map[string]interface{}, error) {
+	lifecycle, err := w.resource.Lifecycle()
 	if err != nil {
-		if os.IsNotExist(err) {
-			if err := w.fs.MkdirAll(w.tfCacheDir, os.ModePerm); err != nil {
-				return fmt.Errorf("create workspace error: %v", err)
-			}
-		} else {
-			return err
-		}
+		return nil, err
 	}
-	err = w.fs.WriteFile(filepath.Join(w.tfCacheDir, mainTFFile), []byte(hclMain), 0o600)
-	if err != nil {
-		return fmt.Errorf("write hcl main.tf.json error: %v", err)
+	if !lifecycle.CreateBeforeDestroy {
+		return w.resource.Attributes, nil
 	}
-
-	return nil
+	body := make(map[string]interface{}, len(w.resource.Attributes)+1)
+	for k, v := range w.resource.Attributes {
+		body[k] = v
+	}
+	body["lifecycle"] = map[string]interface{}{"create_before_destroy": true}
+	return body, nil
 }
 
 // WriteTFState writes StateRepresentation to the file, this function is for terraform apply refresh only
@@ -187,6 +227,10 @@ func (w *WorkSpace) initEnvs() ([]string, error) {
 // Apply with the terraform cli apply command
 func (w *WorkSpace) Apply(ctx context.Context) (*StateRepresentation, error) {
 	chdir := fmt.Sprintf("-chdir=%s", w.tfCacheDir)
+	// remove the apply log of the last apply first, which must not be watched as the progress of this one
+	if err := w.fs.Remove(filepath.Join(w.tfCacheDir, ApplyLogFile)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
 	err := w.CleanAndInitWorkspace(ctx)
 	if err != nil {
 		return nil, err
@@ -200,9 +244,18 @@ func (w *WorkSpace) Apply(ctx context.Context) (*StateRepresentation, error) {
 	}
 	cmd.Env = envs
 
-	out, err := cmd.CombinedOutput()
+	// record the output in the apply log, so that the progress can be watched
+	var out bytes.Buffer
+	stdout, closeLog, err := w.openApplyLog(&out)
 	if err != nil {
-		return nil, TFError(out)
+		return nil, err
+	}
+	defer closeLog()
+	cmd.Stdout = stdout
+	cmd.Stderr = &out
+
+	if err = cmd.Run(); err != nil {
+		return nil, TFError(out.Bytes())
 	}
 
 	s, err := w.RefreshOnly(ctx)
@@ -321,6 +374,54 @@ func (w *WorkSpace) RefreshOnly(ctx context.Context) (*StateRepresentation, erro
 	return s, err
 }
 
+// Import imports an existing resource with the terraform cli import command, and returns the imported state.
+// The workspace must have been initialized
+func (w *WorkSpace) Import(ctx context.Context, id string) (*StateRepresentation, error) {
+	chdir := fmt.Sprintf("-chdir=%s", w.tfCacheDir)
+
+	// terraform refuses to import a resource which is already in the tfstate
+	if err := w.fs.Remove(filepath.Join(w.tfCacheDir, tfStateFile)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	address, err := w.resourceAddress()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, "terraform", chdir, "import", "-input=false", "-lock=false", address, id)
+	cmd.Dir = w.stackDir
+	envs, err := w.initEnvs()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = envs
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, TFError(out)
+	}
+
+	s, err := w.ShowState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("terraform read state error: %v", err)
+	}
+	return s, nil
+}
+
+// resourceAddress returns the address of the resource in main.tf.json, e.g. alicloud_vpc.my_vpc
+func (w *WorkSpace) resourceAddress() (string, error) {
+	resourceType, ok := w.resource.Extensions["resourceType"].(string)
+	if !ok || resourceType == "" {
+		return "", fmt.Errorf("no resourceType in extensions of Terraform resource %s", w.resource.ResourceKey())
+	}
+	resourceNames := strings.Split(w.resource.ResourceKey(), ":")
+	if len(resourceNames) < 4 {
+		return "", fmt.Errorf("illegial resource id:%s in Spec. "+
+			"Resource id format: providerNamespace:providerName:resourceType:resourceName", w.resource.ResourceKey())
+	}
+	return resourceType + "." + resourceNames[len(resourceNames)-1], nil
+}
+
 // Destroy make terraform destroy call.
 func (w *WorkSpace) Destroy(ctx context.Context) error {
 	chdir := fmt.Sprintf("-chdir=%s", w.tfCacheDir)
@@ -443,3 +443,72 @@ func TestDestroy(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkSpace_resourceBody(t *testing.T) {
+	w := NewWorkSpace(fs)
+	w.SetResource(&resourceTest)
+	body, err := w.resourceBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body["lifecycle"]; ok {
+		t.Errorf("unexpected lifecycle block: %v", body)
+	}
+
+	r := resourceTest.DeepCopy()
+	r.Extensions[models.LifecycleKey] = map[string]interface{}{"createBeforeDestroy": true}
+	w.SetResource(r)
+	body, err = w.resourceBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"create_before_destroy": true}
+	if !reflect.DeepEqual(body["lifecycle"], want) {
+		t.Errorf("resourceBody() lifecycle = %v, want %v", body["lifecycle"], want)
+	}
+	if _, ok := r.Attributes["lifecycle"]; ok {
+		t.Errorf("attributes of the resource should not be changed")
+	}
+}
+
+func TestPlanRepresentation_RequiresReplace(t *testing.T) {
+	cases := map[string]struct {
+		actions []string
+		want    bool
+	}{
+		"update":                {actions: []string{"update"}, want: false},
+		"delete then create":    {actions: []string{"delete", "create"}, want: true},
+		"create before destroy": {actions: []string{"create", "delete"}, want: true},
+	}
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			pr := &PlanRepresentation{ResourceChanges: []ResourceChange{{Change: Change{Actions: tt.actions}}}}
+			if got := pr.RequiresReplace(); got != tt.want {
+				t.Errorf("RequiresReplace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceAddress(t *testing.T) {
+	w := NewWorkSpace(fs)
+	w.SetResource(&resourceTest)
+	address, err := w.resourceAddress()
+	if err != nil || address != "local_file.kusion_example" {
+		t.Errorf("resourceAddress() = %v, %v, want local_file.kusion_example", address, err)
+	}
+
+	noType := resourceTest
+	noType.Extensions = map[string]interface{}{"provider": "registry.terraform.io/hashicorp/local/2.2.3"}
+	w.SetResource(&noType)
+	if _, err = w.resourceAddress(); err == nil {
+		t.Error("resourceAddress() of the resource without resourceType should fail")
+	}
+
+	illegalID := resourceTest
+	illegalID.ID = "local_file:kusion_example"
+	w.SetResource(&illegalID)
+	if _, err = w.resourceAddress(); err == nil {
+		t.Error("resourceAddress() of the resource with an illegal ID should fail")
+	}
+}
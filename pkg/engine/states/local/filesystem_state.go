@@ -2,8 +2,14 @@ package local
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -23,7 +29,20 @@ func NewFileSystemState() states.StateStorage {
 	return &FileSystemState{}
 }
 
-const KusionState = "kusion_state.json"
+const (
+	KusionState = "kusion_state.json"
+
+	// LockFileSuffix is the suffix of the lock file which is placed next to the state file
+	LockFileSuffix = ".lock"
+
+	// HistoryDirSuffix is the suffix of the directory which is placed next to the state file to keep all State snapshots
+	HistoryDirSuffix = ".history"
+)
+
+// differs returns whether the name in the query differs from the one in the State, and empty names match any
+func differs(queried, saved string) bool {
+	return queried != "" && saved != "" && queried != saved
+}
 
 func (f *FileSystemState) GetLatestState(query *states.StateQuery) (*states.State, error) {
 	// create a new state file if no file exists
@@ -49,6 +68,10 @@ func (f *FileSystemState) GetLatestState(query *states.StateQuery) (*states.Stat
 		if err != nil {
 			return nil, err
 		}
+		if query != nil && (differs(query.Project, state.Project) || differs(query.Stack, state.Stack)) {
+			return nil, &states.UnsupportedQueryError{Query: query, Reason: fmt.Sprintf(
+				"the local backend only keeps the State of stack %s/%s in %s", state.Project, state.Stack, f.Path)}
+		}
 		return state, nil
 	} else {
 		log.Infof("file %s is empty. Skip unmarshal json", f.Path)
@@ -76,7 +99,67 @@ func (f *FileSystemState) Apply(state *states.State) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(f.Path, jsonByte, fs.ModePerm)
+	if err = os.WriteFile(f.Path, jsonByte, fs.ModePerm); err != nil {
+		return err
+	}
+
+	// keep a snapshot of this serial
+	if err = os.MkdirAll(f.historyDir(), fs.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(f.snapshotPath(state.Serial), jsonByte, fs.ModePerm)
+}
+
+func (f *FileSystemState) GetStateHistory(query *states.StateQuery) ([]*states.State, error) {
+	entries, err := os.ReadDir(f.historyDir())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var serials []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		serial, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			log.Warnf("skip unknown file %s in state history", name)
+			continue
+		}
+		serials = append(serials, serial)
+	}
+	sort.Slice(serials, func(i, j int) bool { return serials[i] > serials[j] })
+
+	history := make([]*states.State, 0, len(serials))
+	for _, serial := range serials {
+		state, err := f.GetStateBySerial(query, serial)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, state)
+	}
+	return history, nil
+}
+
+func (f *FileSystemState) GetStateBySerial(query *states.StateQuery, serial uint64) (*states.State, error) {
+	jsonFile, err := os.ReadFile(f.snapshotPath(serial))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	state := &states.State{}
+	// JSON is a subset of YAML. Please check FileSystemState.GetLatestState for detail explanation
+	if err = yaml.Unmarshal(jsonFile, state); err != nil {
+		return nil, err
+	}
+	return state, nil
 }
 
 func (f *FileSystemState) Delete(id string) error {
@@ -87,3 +170,67 @@ func (f *FileSystemState) Delete(id string) error {
 	}
 	return nil
 }
+
+// Lock creates a lock file next to the state file exclusively, so only one operation can hold the lock at a time
+func (f *FileSystemState) Lock(query *states.StateQuery, info *states.LockInfo) error {
+	jsonByte, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.lockPath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, fs.ModePerm)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			current, _ := f.readLockInfo()
+			return &states.LockedError{Query: query, Info: current}
+		}
+		return err
+	}
+	defer file.Close()
+
+	if _, err = file.Write(jsonByte); err != nil {
+		_ = os.Remove(f.lockPath())
+		return err
+	}
+	return nil
+}
+
+// Unlock removes the lock file if it is held by lockID
+func (f *FileSystemState) Unlock(query *states.StateQuery, lockID string) error {
+	current, err := f.readLockInfo()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if current.ID != lockID {
+		return states.LockIDMismatchError(query, lockID, current)
+	}
+	log.Infof("Delete lock file:%s", f.lockPath())
+	return os.Remove(f.lockPath())
+}
+
+func (f *FileSystemState) historyDir() string {
+	return f.Path + HistoryDirSuffix
+}
+
+func (f *FileSystemState) snapshotPath(serial uint64) string {
+	return filepath.Join(f.historyDir(), fmt.Sprintf("%d.json", serial))
+}
+
+func (f *FileSystemState) lockPath() string {
+	return f.Path + LockFileSuffix
+}
+
+func (f *FileSystemState) readLockInfo() (*states.LockInfo, error) {
+	data, err := os.ReadFile(f.lockPath())
+	if err != nil {
+		return nil, err
+	}
+	info := &states.LockInfo{}
+	if err = json.Unmarshal(data, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -88,6 +88,74 @@ func TestFileSystemState_GetLatestState(t *testing.T) {
 	}
 }
 
+// TestFileSystemState_History and the tests below it use the real file system, so they must run before
+// TestFileSystemState, whose mocks of os.WriteFile and os.Remove are kept for the rest of the package
+func TestFileSystemState_History(t *testing.T) {
+	s := &FileSystemState{Path: filepath.Join(t.TempDir(), KusionState)}
+	query := &states.StateQuery{Tenant: "test_global_tenant", Project: "test_project", Stack: "test_env"}
+
+	history, err := s.GetStateHistory(query)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+
+	for i := 1; i <= 3; i++ {
+		state := &states.State{Tenant: "test_global_tenant", Project: "test_project", Stack: "test_env", Serial: uint64(i)}
+		assert.NoError(t, s.Apply(state))
+	}
+
+	history, err = s.GetStateHistory(query)
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+	for i, state := range history {
+		assert.Equal(t, uint64(3-i), state.Serial)
+	}
+
+	state, err := s.GetStateBySerial(query, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), state.Serial)
+	assert.Equal(t, history[2].CreateTime.Unix(), state.CreateTime.Unix())
+
+	state, err = s.GetStateBySerial(query, 4)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestFileSystemState_Lock(t *testing.T) {
+	s := &FileSystemState{Path: filepath.Join(t.TempDir(), KusionState)}
+	query := &states.StateQuery{Tenant: "test_global_tenant", Project: "test_project", Stack: "test_env"}
+
+	info := states.NewLockInfo("Apply", "alice")
+	assert.NoError(t, s.Lock(query, info))
+
+	// lock again by others
+	err := s.Lock(query, states.NewLockInfo("Destroy", "bob"))
+	var lockedErr *states.LockedError
+	assert.ErrorAs(t, err, &lockedErr)
+	assert.Equal(t, info.ID, lockedErr.Info.ID)
+	assert.Equal(t, "alice", lockedErr.Info.Holder)
+
+	// unlock with a wrong lock ID
+	assert.Error(t, s.Unlock(query, "wrong-id"))
+
+	assert.NoError(t, s.Unlock(query, info.ID))
+	// unlock a state not locked
+	assert.NoError(t, s.Unlock(query, info.ID))
+	assert.NoError(t, s.Lock(query, states.NewLockInfo("Destroy", "bob")))
+}
+
+func TestFileSystemState_GetLatestStateOfOtherStack(t *testing.T) {
+	s := &FileSystemState{Path: filepath.Join(t.TempDir(), KusionState)}
+	assert.NoError(t, s.Apply(&states.State{Project: "network", Stack: "dev", Serial: 1}))
+
+	state, err := s.GetLatestState(&states.StateQuery{Project: "network", Stack: "dev"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), state.Serial)
+
+	_, err = s.GetLatestState(&states.StateQuery{Project: "network", Stack: "prod"})
+	var unsupported *states.UnsupportedQueryError
+	assert.ErrorAs(t, err, &unsupported)
+}
+
 func FileSystemStateSetUp(t *testing.T) *FileSystemState {
 	mockey.Mock(os.WriteFile).To(func(filename string, data []byte, perm fs.FileMode) error {
 		return nil
@@ -0,0 +1,94 @@
+package states
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+)
+
+// LockInfo is the lease of a State. It is held by one operation at a time to prevent concurrent
+// operations on the same project and stack from overwriting each other's State.
+type LockInfo struct {
+	// ID is a unique identifier of this lock, and it is required to release the lock
+	ID string `json:"id" yaml:"id"`
+
+	// Holder represents the person who holds this lock
+	Holder string `json:"holder" yaml:"holder"`
+
+	// Operation is the operation that acquired this lock, such as Apply or Destroy
+	Operation string `json:"operation" yaml:"operation"`
+
+	// CreateTime is the time this lock is acquired
+	CreateTime time.Time `json:"createTime" yaml:"createTime"`
+}
+
+// NewLockInfo returns a LockInfo with a random ID. If the holder is empty, it will be set to the current user and hostname
+func NewLockInfo(operation, holder string) *LockInfo {
+	if holder == "" {
+		holder = defaultHolder()
+	}
+	return &LockInfo{
+		ID:         newLockID(),
+		Holder:     holder,
+		Operation:  operation,
+		CreateTime: time.Now(),
+	}
+}
+
+// LockedError is returned when a State is already locked by others
+type LockedError struct {
+	// Query locates the locked State
+	Query *StateQuery
+
+	// Info is the lock held by others. It may be nil if the backend can't tell who holds the lock
+	Info *LockInfo
+}
+
+func (e *LockedError) Error() string {
+	stack := e.Query.Project + "/" + e.Query.Stack
+	if e.Info == nil {
+		return fmt.Sprintf("stack %s is locked by another operation", stack)
+	}
+	return fmt.Sprintf("stack %s is locked by %s. Operation: %s, created at: %s, lock ID: %s. "+
+		"If you are sure no other operation is running, release it by `kusion force-unlock %s`",
+		stack, e.Info.Holder, e.Info.Operation, e.Info.CreateTime.Format(time.RFC3339), e.Info.ID, e.Info.ID)
+}
+
+// LockUnsupportedError is returned when a backend can't prevent concurrent operations on a State,
+// and the State is not locked
+type LockUnsupportedError struct {
+	// Reason tells why the backend can't lock the State
+	Reason string
+}
+
+func (e *LockUnsupportedError) Error() string {
+	return "locking State is not supported: " + e.Reason
+}
+
+// LockIDMismatchError is returned when releasing a lock with an ID that doesn't match the current lock
+func LockIDMismatchError(query *StateQuery, expected string, current *LockInfo) error {
+	return fmt.Errorf("lock ID %s does not match the current lock of stack %s/%s, current lock ID: %s, holder: %s",
+		expected, query.Project, query.Stack, current.ID, current.Holder)
+}
+
+func newLockID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+func defaultHolder() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		name = name + "@" + hostname
+	}
+	return name
+}
@@ -8,7 +8,7 @@ import (
 	"sort"
 
 	"github.com/didi/gendry/scanner"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/copier"
 	"gopkg.in/yaml.v3"
 
@@ -53,6 +53,60 @@ func (s *DBState) Delete(id string) error {
 }
 
 func (s *DBState) GetLatestState(q *states.StateQuery) (*states.State, error) {
+	where, err := queryCondition(q)
+	if err != nil {
+		return nil, err
+	}
+	where["_orderby"] = "serial desc"
+
+	stateDO, err := mapper.GetOne(s.DB, where)
+	if errors.Is(err, scanner.ErrEmptyResult) {
+		return nil, nil
+	}
+	res := do2Bo(stateDO)
+	return res, err
+}
+
+// GetStateHistory returns all States saved in DB since Apply is an add-only strategy
+func (s *DBState) GetStateHistory(q *states.StateQuery) ([]*states.State, error) {
+	where, err := queryCondition(q)
+	if err != nil {
+		return nil, err
+	}
+	where["_orderby"] = "serial desc"
+
+	stateDOs, err := mapper.Get(s.DB, where)
+	if errors.Is(err, scanner.ErrEmptyResult) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	history := make([]*states.State, 0, len(stateDOs))
+	for _, stateDO := range stateDOs {
+		history = append(history, do2Bo(stateDO))
+	}
+	return history, nil
+}
+
+func (s *DBState) GetStateBySerial(q *states.StateQuery, serial uint64) (*states.State, error) {
+	where, err := queryCondition(q)
+	if err != nil {
+		return nil, err
+	}
+	where["serial"] = serial
+
+	stateDO, err := mapper.GetOne(s.DB, where)
+	if errors.Is(err, scanner.ErrEmptyResult) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return do2Bo(stateDO), nil
+}
+
+func queryCondition(q *states.StateQuery) (map[string]interface{}, error) {
 	where := make(map[string]interface{})
 
 	if len(q.Tenant) == 0 {
@@ -76,14 +130,7 @@ func (s *DBState) GetLatestState(q *states.StateQuery) (*states.State, error) {
 	if len(q.Cluster) != 0 {
 		where["cluster"] = q.Cluster
 	}
-	where["_orderby"] = "serial desc"
-
-	stateDO, err := mapper.GetOne(s.DB, where)
-	if errors.Is(err, scanner.ErrEmptyResult) {
-		return nil, nil
-	}
-	res := do2Bo(stateDO)
-	return res, err
+	return where, nil
 }
 
 func do2Bo(dbState *mapper.StateDO) *states.State {
@@ -99,3 +146,71 @@ func do2Bo(dbState *mapper.StateDO) *states.State {
 	res.Resources = resStateList
 	return res
 }
+
+// mysqlDuplicateEntry is the MySQL error number of inserting a duplicate entry for a unique key
+const mysqlDuplicateEntry = 1062
+
+// Lock inserts a record into table state_lock. The primary key of this table guarantees that only one operation can hold
+// the lock. The table is created by pkg/engine/dal/schema/state_lock.sql
+func (s *DBState) Lock(query *states.StateQuery, info *states.LockInfo) error {
+	data := map[string]interface{}{
+		"lock_id":     info.ID,
+		"tenant":      query.Tenant,
+		"project":     query.Project,
+		"stack":       query.Stack,
+		"cluster":     query.Cluster,
+		"holder":      info.Holder,
+		"operation":   info.Operation,
+		"create_time": info.CreateTime,
+	}
+	err := mapper.InsertLock(s.DB, data)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		current, _ := s.getLockInfo(query)
+		return &states.LockedError{Query: query, Info: current}
+	}
+	return err
+}
+
+// Unlock deletes the record in table state_lock if it is held by lockID
+func (s *DBState) Unlock(query *states.StateQuery, lockID string) error {
+	current, err := s.getLockInfo(query)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+	if current.ID != lockID {
+		return states.LockIDMismatchError(query, lockID, current)
+	}
+	where := lockCondition(query)
+	where["lock_id"] = lockID
+	return mapper.DeleteLock(s.DB, where)
+}
+
+// getLockInfo returns nil if the State is not locked
+func (s *DBState) getLockInfo(query *states.StateQuery) (*states.LockInfo, error) {
+	lockDO, err := mapper.GetLock(s.DB, lockCondition(query))
+	if errors.Is(err, scanner.ErrEmptyResult) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &states.LockInfo{
+		ID:         lockDO.LockID,
+		Holder:     lockDO.Holder,
+		Operation:  lockDO.Operation,
+		CreateTime: lockDO.CreateTime,
+	}, nil
+}
+
+func lockCondition(query *states.StateQuery) map[string]interface{} {
+	return map[string]interface{}{
+		"tenant":  query.Tenant,
+		"project": query.Project,
+		"stack":   query.Stack,
+		"cluster": query.Cluster,
+	}
+}
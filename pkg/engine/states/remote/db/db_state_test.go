@@ -8,9 +8,10 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/bytedance/mockey"
 	"github.com/didi/gendry/manager"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 
 	"kusionstack.io/kusion/pkg/engine/dal/mapper"
@@ -74,6 +75,74 @@ func TestDBState(t *testing.T) {
 	})
 }
 
+func TestDBState_History(t *testing.T) {
+	mockey.PatchConvey("test DB state history", t, func() {
+		mockey.Mock(mapper.Get).To(func(db *sql.DB, where map[string]interface{}) ([]*mapper.StateDO, error) {
+			assert.Equal(t, "serial desc", where["_orderby"])
+			return []*mapper.StateDO{{Serial: 2}, {Serial: 1}}, nil
+		}).Build()
+		mockey.Mock(mapper.GetOne).To(func(db *sql.DB, where map[string]interface{}) (*mapper.StateDO, error) {
+			assert.Equal(t, uint64(1), where["serial"])
+			return &mapper.StateDO{Serial: 1}, nil
+		}).Build()
+
+		dbState := &DBState{DB: &sql.DB{}}
+		query := &states.StateQuery{Tenant: "test_global_tenant", Stack: "test_env", Project: "test_project"}
+
+		history, err := dbState.GetStateHistory(query)
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, uint64(2), history[0].Serial)
+
+		state, err := dbState.GetStateBySerial(query, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), state.Serial)
+	})
+}
+
+func TestDBState_Lock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	dbState := &DBState{DB: db}
+	query := &states.StateQuery{Tenant: "test_global_tenant", Stack: "test_env", Project: "test_project"}
+	current := states.NewLockInfo("Apply", "alice")
+	lockColumns := []string{"lock_id", "tenant", "project", "stack", "cluster", "holder", "operation", "create_time"}
+	lockRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows(lockColumns).AddRow(current.ID, query.Tenant, query.Project, query.Stack, "",
+			current.Holder, current.Operation, current.CreateTime)
+	}
+
+	info := states.NewLockInfo("Apply", "bob")
+	mock.ExpectExec("INSERT INTO state_lock").
+		WithArgs(query.Cluster, info.CreateTime, info.Holder, info.ID, info.Operation, query.Project, query.Stack, query.Tenant).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, dbState.Lock(query, info))
+
+	// already locked
+	mock.ExpectExec("INSERT INTO state_lock").WillReturnError(&mysql.MySQLError{Number: mysqlDuplicateEntry})
+	mock.ExpectQuery("SELECT \\* FROM state_lock").WillReturnRows(lockRow())
+	err = dbState.Lock(query, info)
+	var lockedErr *states.LockedError
+	assert.ErrorAs(t, err, &lockedErr)
+	assert.Equal(t, current.ID, lockedErr.Info.ID)
+	assert.Equal(t, current.Holder, lockedErr.Info.Holder)
+
+	// ID mismatch
+	mock.ExpectQuery("SELECT \\* FROM state_lock").WillReturnRows(lockRow())
+	err = dbState.Unlock(query, info.ID)
+	assert.ErrorContains(t, err, "lock ID "+info.ID+" does not match the current lock of stack test_project/test_env")
+
+	mock.ExpectQuery("SELECT \\* FROM state_lock").WillReturnRows(lockRow())
+	mock.ExpectExec("DELETE FROM state_lock").WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, dbState.Unlock(query, current.ID))
+
+	// not locked
+	mock.ExpectQuery("SELECT \\* FROM state_lock").WillReturnRows(sqlmock.NewRows(lockColumns))
+	assert.NoError(t, dbState.Unlock(query, current.ID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDBState_do2Bo(t *testing.T) {
 	type fields struct {
 		DB *sql.DB
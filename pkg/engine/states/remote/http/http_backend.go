@@ -22,6 +22,7 @@ func (b *HTTPBackend) ConfigSchema() cty.Type {
 		"urlPrefix":          cty.String,
 		"applyURLFormat":     cty.String,
 		"getLatestURLFormat": cty.String,
+		"lockURLFormat":      cty.String,
 	}
 	return cty.Object(config)
 }
@@ -60,6 +61,17 @@ func (b *HTTPBackend) Configure(obj cty.Value) error {
 		b.getLatestURLFormat = asString
 	}
 
+	// lockURLFormat is optional, and states are not locked without it
+	if lockFormat := obj.GetAttr("lockURLFormat"); !lockFormat.IsNull() && lockFormat.AsString() != "" {
+		asString := lockFormat.AsString()
+		count := strings.Count(asString, "%s")
+		if count != ParamsCounts {
+			return errors.New("lockURLFormat must contains 4 \"%s\" placeholders for tenant, project, " +
+				"stack and cluster. Current format:" + asString)
+		}
+		b.lockURLFormat = asString
+	}
+
 	return nil
 }
 
@@ -69,5 +81,6 @@ func (b *HTTPBackend) StateStorage() states.StateStorage {
 		urlPrefix:          b.urlPrefix,
 		applyURLFormat:     b.applyURLFormat,
 		getLatestURLFormat: b.getLatestURLFormat,
+		lockURLFormat:      b.lockURLFormat,
 	}
 }
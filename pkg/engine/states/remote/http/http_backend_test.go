@@ -19,6 +19,7 @@ func TestHttpBackend_ConfigSchema(t *testing.T) {
 				"urlPrefix":          cty.String,
 				"applyURLFormat":     cty.String,
 				"getLatestURLFormat": cty.String,
+				"lockURLFormat":      cty.String,
 			}),
 		},
 	}
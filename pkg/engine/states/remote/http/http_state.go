@@ -37,10 +37,16 @@ type HTTPState struct {
 
 	// getLatestURLFormat is the suffix url format to get the latest state
 	getLatestURLFormat string
+
+	// lockURLFormat is the suffix url format to lock and unlock a state. POST to this url to acquire a lock and
+	// DELETE to release it. Locking is not supported if it is empty
+	lockURLFormat string
 }
 
 const ParamsCounts = 4
 
+var errLockURLFormatNotConfigured = &states.LockUnsupportedError{Reason: "lockURLFormat of the http backend is not configured"}
+
 // GetLatestState is an implementation of StateStorage.GetLatestState
 func (s *HTTPState) GetLatestState(query *states.StateQuery) (*states.State, error) {
 	url := fmt.Sprintf("%s"+s.getLatestURLFormat, s.urlPrefix, query.Tenant, query.Project, query.Stack, query.Cluster)
@@ -100,3 +106,83 @@ func (s *HTTPState) Apply(state *states.State) error {
 func (s *HTTPState) Delete(id string) error {
 	return errors.New("not supported")
 }
+
+// Lock is an implementation of StateStorage.Lock. The service should respond 409 Conflict or 423 Locked
+// with the current LockInfo in the body if the state is already locked. A *states.LockUnsupportedError is
+// returned if lockURLFormat is not configured
+func (s *HTTPState) Lock(query *states.StateQuery, info *states.LockInfo) error {
+	if s.lockURLFormat == "" {
+		return errLockURLFormatNotConfigured
+	}
+	jsonInfo, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s"+s.lockURLFormat, s.urlPrefix, query.Tenant, query.Project, query.Stack, query.Cluster)
+
+	req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonInfo)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusConflict, http.StatusLocked:
+		var current *states.LockInfo
+		resBody, _ := io.ReadAll(res.Body)
+		if len(resBody) != 0 {
+			current = &states.LockInfo{}
+			if err = json.Unmarshal(resBody, current); err != nil {
+				current = nil
+			}
+		}
+		return &states.LockedError{Query: query, Info: current}
+	default:
+		return fmt.Errorf("lock state failed. StatusCode:%v, Status:%s", res.StatusCode, res.Status)
+	}
+}
+
+// Unlock is an implementation of StateStorage.Unlock
+func (s *HTTPState) Unlock(query *states.StateQuery, lockID string) error {
+	if s.lockURLFormat == "" {
+		return errLockURLFormatNotConfigured
+	}
+	jsonInfo, err := json.Marshal(&states.LockInfo{ID: lockID})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s"+s.lockURLFormat, s.urlPrefix, query.Tenant, query.Project, query.Stack, query.Cluster)
+
+	req, err := http.NewRequest("DELETE", url, strings.NewReader(string(jsonInfo)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("unlock state failed. StatusCode:%v, Status:%s", res.StatusCode, res.Status)
+	}
+	return nil
+}
+
+// GetStateHistory is not support now
+func (s *HTTPState) GetStateHistory(query *states.StateQuery) ([]*states.State, error) {
+	return nil, errors.New("not supported")
+}
+
+// GetStateBySerial is not support now
+func (s *HTTPState) GetStateBySerial(query *states.StateQuery, serial uint64) (*states.State, error) {
+	return nil, errors.New("not supported")
+}
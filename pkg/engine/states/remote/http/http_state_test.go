@@ -1,10 +1,13 @@
 package http
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/bytedance/mockey"
@@ -186,3 +189,68 @@ func TestHTTPState_GetLatestState(t *testing.T) {
 		})
 	}
 }
+
+// lockServer holds locks of States like a server implementing lockURLFormat
+type lockServer struct {
+	mu    sync.Mutex
+	locks map[string]*states.LockInfo
+}
+
+func (l *lockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	info := &states.LockInfo{}
+	if err := json.NewDecoder(r.Body).Decode(info); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	current := l.locks[r.URL.Path]
+	switch r.Method {
+	case http.MethodPost:
+		if current != nil {
+			w.WriteHeader(http.StatusLocked)
+			_ = json.NewEncoder(w).Encode(current)
+			return
+		}
+		l.locks[r.URL.Path] = info
+	case http.MethodDelete:
+		if current == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if current.ID != info.ID {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		delete(l.locks, r.URL.Path)
+	}
+}
+
+func TestHTTPState_Lock(t *testing.T) {
+	server := httptest.NewServer(&lockServer{locks: map[string]*states.LockInfo{}})
+	defer server.Close()
+	s := &HTTPState{urlPrefix: server.URL, lockURLFormat: format + "lock"}
+	query := &states.StateQuery{Tenant: "t", Project: "p", Stack: "s", Cluster: "c"}
+
+	info := states.NewLockInfo("Apply", "alice")
+	assert.NoError(t, s.Lock(query, info))
+
+	// already locked
+	err := s.Lock(query, states.NewLockInfo("Apply", "bob"))
+	var lockedErr *states.LockedError
+	assert.ErrorAs(t, err, &lockedErr)
+	assert.Equal(t, info.ID, lockedErr.Info.ID)
+	assert.Equal(t, "alice", lockedErr.Info.Holder)
+
+	// ID mismatch
+	err = s.Unlock(query, "other")
+	assert.ErrorContains(t, err, "unlock state failed. StatusCode:409")
+
+	assert.NoError(t, s.Unlock(query, info.ID))
+	assert.NoError(t, s.Unlock(query, info.ID))
+
+	// locking is not supported without lockURLFormat
+	var unsupportedErr *states.LockUnsupportedError
+	assert.ErrorAs(t, (&HTTPState{urlPrefix: server.URL}).Lock(query, info), &unsupportedErr)
+}
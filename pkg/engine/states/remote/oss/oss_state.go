@@ -4,7 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 	"gopkg.in/yaml.v3"
@@ -14,7 +20,13 @@ import (
 
 var ErrOSSNoExist = errors.New("oss: key not exist")
 
-const OSSStateName = "kusion_state.json"
+const (
+	OSSStateName = "kusion_state.json"
+	OSSLockName  = "kusion_state.lock"
+
+	// OSSHistoryDir is the directory next to the state object to keep all State snapshots
+	OSSHistoryDir = "history"
+)
 
 var _ states.StateStorage = &OssState{}
 
@@ -50,9 +62,86 @@ func (s *OssState) Apply(state *states.State) error {
 	if err != nil {
 		return err
 	}
+
+	// keep a snapshot of this serial
+	err = s.bucket.PutObject(snapshotKey(state.Tenant, state.Project, state.Stack, state.Serial), bytes.NewReader(jsonByte))
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
+func (s *OssState) GetStateHistory(query *states.StateQuery) ([]*states.State, error) {
+	prefix := query.Tenant + "/" + query.Project + "/" + query.Stack + "/" + OSSHistoryDir + "/"
+
+	var serials []uint64
+	marker := ""
+	for {
+		objects, err := s.bucket.ListObjects(oss.Prefix(prefix), oss.Marker(marker))
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects.Objects {
+			name := path.Base(object.Key)
+			serial, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+			if err != nil {
+				continue
+			}
+			serials = append(serials, serial)
+		}
+		if !objects.IsTruncated {
+			break
+		}
+		marker = objects.NextMarker
+	}
+	sort.Slice(serials, func(i, j int) bool { return serials[i] > serials[j] })
+
+	history := make([]*states.State, 0, len(serials))
+	for _, serial := range serials {
+		state, err := s.getObjectState(snapshotKey(query.Tenant, query.Project, query.Stack, serial))
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, state)
+	}
+	return history, nil
+}
+
+func (s *OssState) GetStateBySerial(query *states.StateQuery, serial uint64) (*states.State, error) {
+	key := snapshotKey(query.Tenant, query.Project, query.Stack, serial)
+	exist, err := s.bucket.IsObjectExist(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, nil
+	}
+	return s.getObjectState(key)
+}
+
+func (s *OssState) getObjectState(key string) (*states.State, error) {
+	body, err := s.bucket.GetObject(key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	state := &states.State{}
+	// JSON is a subset of YAML. Please check FileSystemState.GetLatestState for detail explanation
+	if err = yaml.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func snapshotKey(tenant, project, stack string, serial uint64) string {
+	return tenant + "/" + project + "/" + stack + "/" + OSSHistoryDir + "/" + fmt.Sprintf("%d.json", serial)
+}
+
 func (s *OssState) Delete(id string) error {
 	panic("implement me")
 }
@@ -86,3 +175,67 @@ func (s *OssState) GetLatestState(query *states.StateQuery) (*states.State, erro
 	}
 	return state, nil
 }
+
+// Lock puts a lock object next to the state object with overwriting forbidden,
+// so the lock fails if the lock object already exists
+func (s *OssState) Lock(query *states.StateQuery, info *states.LockInfo) error {
+	key := query.Tenant + "/" + query.Project + "/" + query.Stack + "/" + OSSLockName
+	jsonByte, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = s.bucket.PutObject(key, bytes.NewReader(jsonByte), oss.ForbidOverWrite(true))
+	if err != nil {
+		var serviceErr oss.ServiceError
+		if errors.As(err, &serviceErr) && serviceErr.StatusCode == http.StatusConflict {
+			current, _ := s.getLockInfo(key)
+			return &states.LockedError{Query: query, Info: current}
+		}
+		return err
+	}
+	return nil
+}
+
+// Unlock deletes the lock object if it is held by lockID
+func (s *OssState) Unlock(query *states.StateQuery, lockID string) error {
+	key := query.Tenant + "/" + query.Project + "/" + query.Stack + "/" + OSSLockName
+	current, err := s.getLockInfo(key)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+	if current.ID != lockID {
+		return states.LockIDMismatchError(query, lockID, current)
+	}
+	return s.bucket.DeleteObject(key)
+}
+
+// getLockInfo returns nil if the lock object not exists
+func (s *OssState) getLockInfo(key string) (*states.LockInfo, error) {
+	exist, err := s.bucket.IsObjectExist(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, nil
+	}
+
+	body, err := s.bucket.GetObject(key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	info := &states.LockInfo{}
+	if err = json.Unmarshal(data, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -6,6 +6,10 @@ package oss
 import (
 	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -60,3 +64,66 @@ func TestOssState(t *testing.T) {
 		ossState.Delete("test")
 	})
 }
+
+// fakeOSSServer serves objects of a bucket in memory, and supports x-oss-forbid-overwrite
+type fakeOSSServer struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeOSSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// the endpoint is a cname of the bucket, so the path is the object key
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	data, exist := f.objects[key]
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		if !exist {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("<Error><Code>NoSuchKey</Code></Error>"))
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodPut:
+		if exist && r.Header.Get("X-Oss-Forbid-Overwrite") == "true" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte("<Error><Code>FileAlreadyExists</Code></Error>"))
+			return
+		}
+		f.objects[key], _ = io.ReadAll(r.Body)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestOssState_Lock(t *testing.T) {
+	fake := &fakeOSSServer{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := oss.New(server.URL, "test_access_id", "test_access_secret", oss.UseCname(true))
+	assert.NoError(t, err)
+	bucket, err := client.Bucket("testbucket")
+	assert.NoError(t, err)
+	ossState := &OssState{bucket: bucket}
+
+	query := &states.StateQuery{Tenant: "test_global_tenant", Project: "test_project", Stack: "test_env"}
+	info := states.NewLockInfo("Apply", "alice")
+	assert.NoError(t, ossState.Lock(query, info))
+
+	// already locked
+	err = ossState.Lock(query, states.NewLockInfo("Apply", "bob"))
+	var lockedErr *states.LockedError
+	assert.ErrorAs(t, err, &lockedErr)
+	assert.Equal(t, info.ID, lockedErr.Info.ID)
+
+	// ID mismatch
+	err = ossState.Unlock(query, "other")
+	assert.ErrorContains(t, err, "lock ID other does not match the current lock of stack test_project/test_env")
+
+	assert.NoError(t, ossState.Unlock(query, info.ID))
+	assert.NoError(t, ossState.Unlock(query, info.ID))
+	assert.Empty(t, fake.objects)
+}
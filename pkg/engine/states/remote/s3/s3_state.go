@@ -4,9 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -16,7 +23,13 @@ import (
 
 var ErrS3NoExist = errors.New("s3: key not exist")
 
-const S3StateName = "kusion_state.json"
+const (
+	S3StateName = "kusion_state.json"
+	S3LockName  = "kusion_state.lock"
+
+	// S3HistoryDir is the directory next to the state object to keep all State snapshots
+	S3HistoryDir = "history"
+)
 
 var _ states.StateStorage = &S3State{}
 
@@ -59,9 +72,97 @@ func (s *S3State) Apply(state *states.State) error {
 		return err
 	}
 
+	// keep a snapshot of this serial
+	_, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(snapshotKey(state.Tenant, state.Project, state.Stack, state.Serial)),
+		Body:   bytes.NewReader(jsonByte),
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+func (s *S3State) GetStateHistory(query *states.StateQuery) ([]*states.State, error) {
+	prefix := query.Tenant + "/" + query.Project + "/" + query.Stack + "/" + S3HistoryDir + "/"
+	s3Client := s3.New(s.sess)
+
+	var serials []uint64
+	err := s3Client.ListObjectsPages(&s3.ListObjectsInput{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsOutput, lastPage bool) bool {
+		for _, object := range page.Contents {
+			name := path.Base(aws.StringValue(object.Key))
+			serial, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+			if err != nil {
+				continue
+			}
+			serials = append(serials, serial)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(serials, func(i, j int) bool { return serials[i] > serials[j] })
+
+	history := make([]*states.State, 0, len(serials))
+	for _, serial := range serials {
+		state, err := s.getObjectState(s3Client, snapshotKey(query.Tenant, query.Project, query.Stack, serial))
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, state)
+	}
+	return history, nil
+}
+
+func (s *S3State) GetStateBySerial(query *states.StateQuery, serial uint64) (*states.State, error) {
+	key := snapshotKey(query.Tenant, query.Project, query.Stack, serial)
+	s3Client := s3.New(s.sess)
+
+	objects, err := s3Client.ListObjects(&s3.ListObjectsInput{
+		Bucket:    aws.String(s.bucketName),
+		Delimiter: aws.String("/"),
+		Prefix:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects.Contents) == 0 {
+		return nil, nil
+	}
+	return s.getObjectState(s3Client, key)
+}
+
+func (s *S3State) getObjectState(s3Client *s3.S3, key string) (*states.State, error) {
+	out, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, err
+	}
+	state := &states.State{}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func snapshotKey(tenant, project, stack string, serial uint64) string {
+	return tenant + "/" + project + "/" + stack + "/" + S3HistoryDir + "/" + fmt.Sprintf("%d.json", serial)
+}
+
 func (s *S3State) Delete(id string) error {
 	panic("implement me")
 }
@@ -105,3 +206,127 @@ func (s *S3State) GetLatestState(query *states.StateQuery) (*states.State, error
 	}
 	return state, nil
 }
+
+// Lock puts a lock object next to the state object with a conditional write, which fails if the lock object already
+// exists. Some S3 compatible storages ignore the condition, so the conditional write is sent again after the lock
+// object is put, and a *states.LockUnsupportedError is returned if it doesn't fail on these storages
+func (s *S3State) Lock(query *states.StateQuery, info *states.LockInfo) error {
+	key := query.Tenant + "/" + query.Project + "/" + query.Stack + "/" + S3LockName
+	s3Client := s3.New(s.sess)
+
+	current, err := s.getLockInfo(s3Client, key)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		return &states.LockedError{Query: query, Info: current}
+	}
+
+	jsonByte, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = s.putIfNotExists(s3Client, key, jsonByte); err != nil {
+		if !isPreconditionFailed(err) {
+			return err
+		}
+		// the lock object is put by others after checked
+		current, err = s.getLockInfo(s3Client, key)
+		if err != nil {
+			return err
+		}
+		return &states.LockedError{Query: query, Info: current}
+	}
+
+	// the same conditional write must fail now, otherwise the storage ignores the condition and others may
+	// have overwritten the lock object as well
+	err = s.putIfNotExists(s3Client, key, jsonByte)
+	if isPreconditionFailed(err) {
+		return nil
+	}
+	if _, deleteErr := s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	}); deleteErr != nil {
+		return deleteErr
+	}
+	if err != nil {
+		return err
+	}
+	return &states.LockUnsupportedError{
+		Reason: fmt.Sprintf("bucket %s ignores the condition If-None-Match of PutObject", s.bucketName),
+	}
+}
+
+// putIfNotExists puts the object only if it doesn't exist
+func (s *S3State) putIfNotExists(s3Client *s3.S3, key string, data []byte) error {
+	req, _ := s3Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	req.HTTPRequest.Header.Set("If-None-Match", "*")
+	return req.Send()
+}
+
+func isPreconditionFailed(err error) bool {
+	var reqErr awserr.RequestFailure
+	return errors.As(err, &reqErr) &&
+		(reqErr.StatusCode() == http.StatusPreconditionFailed || reqErr.StatusCode() == http.StatusConflict)
+}
+
+// Unlock deletes the lock object if it is held by lockID
+func (s *S3State) Unlock(query *states.StateQuery, lockID string) error {
+	key := query.Tenant + "/" + query.Project + "/" + query.Stack + "/" + S3LockName
+	s3Client := s3.New(s.sess)
+
+	current, err := s.getLockInfo(s3Client, key)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+	if current.ID != lockID {
+		return states.LockIDMismatchError(query, lockID, current)
+	}
+	_, err = s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// getLockInfo returns nil if the lock object not exists
+func (s *S3State) getLockInfo(s3Client *s3.S3, key string) (*states.LockInfo, error) {
+	objects, err := s3Client.ListObjects(&s3.ListObjectsInput{
+		Bucket:    aws.String(s.bucketName),
+		Delimiter: aws.String("/"),
+		Prefix:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects.Contents) == 0 {
+		return nil, nil
+	}
+
+	out, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, err
+	}
+	info := &states.LockInfo{}
+	if err = json.Unmarshal(data, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -5,12 +5,19 @@ package s3
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Azure/go-autorest/autorest/mocks"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/bytedance/mockey"
@@ -67,3 +74,117 @@ func TestS3State(t *testing.T) {
 		s3State.Delete("test")
 	})
 }
+
+// fakeS3Server serves objects of a bucket in memory, and supports the conditional write with If-None-Match: *
+// unless ignoreCondition is set
+type fakeS3Server struct {
+	mu              sync.Mutex
+	objects         map[string][]byte
+	ignoreCondition bool
+}
+
+func (f *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// path style: /bucket/key
+	key := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	switch {
+	case r.Method == http.MethodGet && len(key) == 1:
+		prefix := r.URL.Query().Get("prefix")
+		var contents string
+		for k := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				contents += fmt.Sprintf("<Contents><Key>%s</Key></Contents>", k)
+			}
+		}
+		_, _ = fmt.Fprintf(w, "<ListBucketResult>%s</ListBucketResult>", contents)
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("<Error><Code>NoSuchKey</Code></Error>"))
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodPut:
+		if _, ok := f.objects[key[1]]; ok && r.Header.Get("If-None-Match") == "*" && !f.ignoreCondition {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = w.Write([]byte("<Error><Code>PreconditionFailed</Code></Error>"))
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key[1]] = data
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key[1])
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3State_Lock(t *testing.T) {
+	fake := &fakeS3Server{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	sess, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials("test_access_key", "test_access_secret", ""),
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("test_region"),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	assert.NoError(t, err)
+	s3State := &S3State{sess: sess, bucketName: "test_bucket"}
+
+	query := &states.StateQuery{Tenant: "test_global_tenant", Project: "test_project", Stack: "test_env"}
+	info := states.NewLockInfo("Apply", "alice")
+	assert.NoError(t, s3State.Lock(query, info))
+
+	// already locked
+	err = s3State.Lock(query, states.NewLockInfo("Apply", "bob"))
+	var lockedErr *states.LockedError
+	assert.ErrorAs(t, err, &lockedErr)
+	assert.Equal(t, info.ID, lockedErr.Info.ID)
+
+	// ID mismatch
+	err = s3State.Unlock(query, "other")
+	assert.ErrorContains(t, err, "lock ID other does not match the current lock of stack test_project/test_env")
+
+	assert.NoError(t, s3State.Unlock(query, info.ID))
+	assert.NoError(t, s3State.Unlock(query, info.ID))
+	assert.Empty(t, fake.objects)
+
+	t.Run("lost the race of the conditional write", func(t *testing.T) {
+		key := query.Tenant + "/" + query.Project + "/" + query.Stack + "/" + S3LockName
+		other := states.NewLockInfo("Destroy", "bob")
+		data, _ := json.Marshal(other)
+		mockey.PatchConvey("the lock object is put by others after checked", t, func() {
+			var origin func(s *S3State, c *s3.S3, k string) (*states.LockInfo, error)
+			checked := false
+			mockey.Mock((*S3State).getLockInfo).To(func(s *S3State, c *s3.S3, k string) (*states.LockInfo, error) {
+				if !checked {
+					checked = true
+					fake.mu.Lock()
+					fake.objects[key] = data
+					fake.mu.Unlock()
+					return nil, nil
+				}
+				return origin(s, c, k)
+			}).Origin(&origin).Build()
+
+			err := s3State.Lock(query, states.NewLockInfo("Apply", "alice"))
+			var lockedErr *states.LockedError
+			assert.ErrorAs(t, err, &lockedErr)
+			assert.Equal(t, other.ID, lockedErr.Info.ID)
+		})
+	})
+
+	t.Run("the storage ignores the condition", func(t *testing.T) {
+		fake.objects = map[string][]byte{}
+		fake.ignoreCondition = true
+		defer func() { fake.ignoreCondition = false }()
+
+		err := s3State.Lock(query, states.NewLockInfo("Apply", "alice"))
+		var unsupportedErr *states.LockUnsupportedError
+		assert.ErrorAs(t, err, &unsupportedErr)
+		assert.Empty(t, fake.objects)
+	})
+}
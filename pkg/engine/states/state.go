@@ -1,6 +1,7 @@
 package states
 
 import (
+	"fmt"
 	"time"
 
 	"kusionstack.io/kusion/pkg/models"
@@ -9,7 +10,8 @@ import (
 
 // StateStorage represents the set of methods to manipulate State in a specified storage
 type StateStorage interface {
-	// GetLatestState return nil if state not exists
+	// GetLatestState return nil if state not exists. It returns an *UnsupportedQueryError if the storage can't
+	// locate States by the query
 	GetLatestState(query *StateQuery) (*State, error)
 
 	// Apply means update this state if it already exists or create a new one
@@ -17,6 +19,20 @@ type StateStorage interface {
 
 	// Delete State by id
 	Delete(id string) error
+
+	// GetStateHistory returns all versioned snapshots of the State located by the query, ordered by serial in descending order
+	GetStateHistory(query *StateQuery) ([]*State, error)
+
+	// GetStateBySerial returns the snapshot of the State with the specified serial, and return nil if not exists
+	GetStateBySerial(query *StateQuery, serial uint64) (*State, error)
+
+	// Lock acquires the lock of the State located by the query.
+	// It returns a *LockedError if the lock is already held by others
+	Lock(query *StateQuery, info *LockInfo) error
+
+	// Unlock releases the lock of the State located by the query.
+	// It returns an error if lockID doesn't match the current lock, and nothing happens if the State is not locked
+	Unlock(query *StateQuery, lockID string) error
 }
 
 type StateQuery struct {
@@ -33,6 +49,20 @@ type StateQuery struct {
 	Cluster string `json:"cluster,omitempty"`
 }
 
+// UnsupportedQueryError is returned by a StateStorage which can't locate States by the query, like the local
+// backend which only keeps the State of one stack
+type UnsupportedQueryError struct {
+	// Query is the unsupported query
+	Query *StateQuery
+
+	// Reason tells why the query is not supported
+	Reason string
+}
+
+func (e *UnsupportedQueryError) Error() string {
+	return fmt.Sprintf("can't get the State of stack %s/%s: %s", e.Query.Project, e.Query.Stack, e.Reason)
+}
+
 // State is a record of an operation's result. It is a mapping between resources in KCL and the actual infra resource and often used as a
 // datasource for 3-way merge/diff in operations like Apply or Preview.
 type State struct {
@@ -66,6 +96,9 @@ type State struct {
 	// Resources records all resources in this operation
 	Resources models.Resources `json:"resources" yaml:"resources"`
 
+	// Outputs records values published by the stack, which are resolved from Resources after applying
+	Outputs map[string]interface{} `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+
 	// CreateTime is the time State is created
 	CreateTime time.Time `json:"createTime" yaml:"createTime"`
 
@@ -1,8 +1,17 @@
 package engine
 
-import "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
-const Separator = ":"
+	"kusionstack.io/kusion/pkg/models"
+)
+
+const (
+	Separator = ":"
+
+	// ClusterSeparator separates the ID of a Kubernetes resource and the cluster it lives in
+	ClusterSeparator = "@"
+)
 
 func BuildID(apiVersion, kind, namespace, name string) string {
 	key := apiVersion + Separator + kind + Separator
@@ -15,3 +24,14 @@ func BuildID(apiVersion, kind, namespace, name string) string {
 func BuildIDForKubernetes(o *unstructured.Unstructured) string {
 	return BuildID(o.GetAPIVersion(), o.GetKind(), o.GetNamespace(), o.GetName())
 }
+
+// BuildIDForKubernetesInCluster returns the ID of the Kubernetes resource in the cluster. The cluster is appended
+// unless it is the default one, like "apps/v1:Deployment:default:nginx@prod", so that the same resource deployed
+// to several clusters has distinct IDs
+func BuildIDForKubernetesInCluster(o *unstructured.Unstructured, cluster *models.Cluster) string {
+	id := BuildIDForKubernetes(o)
+	if cluster.IsZero() {
+		return id
+	}
+	return id + ClusterSeparator + cluster.String()
+}
@@ -89,6 +89,8 @@ func (g *appConfigurationGenerator) Generate(spec *models.Spec) error {
 	if spec.Resources == nil {
 		spec.Resources = make(models.Resources, 0)
 	}
+	// resources generated for this app are appended after existing ones
+	start := len(spec.Resources)
 
 	gfs := []appconfiguration.NewGeneratorFunc{
 		NewNamespaceGeneratorFunc(g.project.Name),
@@ -104,5 +106,17 @@ func (g *appConfigurationGenerator) Generate(spec *models.Spec) error {
 		return err
 	}
 
+	// Set the lifecycle of the app on all resources generated for it
+	if g.app.Lifecycle != nil {
+		if err := g.app.Lifecycle.Validate(); err != nil {
+			return fmt.Errorf("invalid lifecycle of app %s: %v", g.appName, err)
+		}
+		for i := start; i < len(spec.Resources); i++ {
+			if err := spec.Resources[i].SetLifecycle(g.app.Lifecycle); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
@@ -47,6 +47,37 @@ func TestAppConfigurationGenerator_Generate(t *testing.T) {
 	assert.NotEmpty(t, spec.Resources)
 }
 
+func TestAppConfigurationGenerator_GenerateLifecycle(t *testing.T) {
+	project, stack := buildMockProjectAndStack()
+	appName, app := buildMockApp()
+	app.Lifecycle = &models.Lifecycle{PreventDestroy: true}
+
+	g := &appConfigurationGenerator{
+		project: project,
+		stack:   stack,
+		appName: appName,
+		app:     app,
+	}
+
+	existing := models.Resource{ID: "existing"}
+	spec := &models.Spec{
+		Resources: []models.Resource{existing},
+	}
+
+	err := g.Generate(spec)
+	assert.NoError(t, err)
+	assert.Nil(t, spec.Resources[0].Extensions)
+	for _, r := range spec.Resources[1:] {
+		lifecycle, err := r.Lifecycle()
+		assert.NoError(t, err)
+		assert.True(t, lifecycle.PreventDestroy, r.ID)
+	}
+
+	app.Lifecycle.DeletionPolicy = "Retain"
+	err = g.Generate(&models.Spec{})
+	assert.ErrorContains(t, err, "invalid lifecycle of app")
+}
+
 func TestNewAppConfigurationGeneratorFunc(t *testing.T) {
 	project, stack := buildMockProjectAndStack()
 	appName, app := buildMockApp()
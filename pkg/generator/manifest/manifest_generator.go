@@ -0,0 +1,204 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+
+	"kusionstack.io/kusion/pkg/engine"
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/generator"
+	appgenerator "kusionstack.io/kusion/pkg/generator/appconfiguration/generator"
+	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+// PathConfig is the key of the manifest directory in configs of the generator, which is relative to the stack
+// directory. Default to the stack directory
+const PathConfig = "path"
+
+// ignoredFiles are files of Kusion in the stack directory, which are not manifests
+var ignoredFiles = map[string]bool{
+	projectstack.StackFile:        true,
+	projectstack.ProjectFile:      true,
+	projectstack.SettingsFile:     true,
+	projectstack.KclFile:          true,
+	projectstack.StdoutGoldenFile: true,
+}
+
+// Generator generates Spec from plain Kubernetes manifests in a directory, or from the kustomization in it if there
+// is one. Resources are ordered by their kinds, like namespaces are applied before other resources
+type Generator struct{}
+
+var _ generator.Generator = (*Generator)(nil)
+
+func (g *Generator) GenerateSpec(o *generator.Options, project *projectstack.Project, stack *projectstack.Stack) (*models.Spec, error) {
+	dir, err := manifestDir(o, project, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []map[string]interface{}
+	if kustomization(dir) {
+		objects, err = kustomize(dir)
+	} else {
+		objects, err = readManifests(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &models.Spec{Resources: models.Resources{}}
+	ids := map[string]bool{}
+	for _, obj := range objects {
+		id := engine.BuildIDForKubernetes(&unstructured.Unstructured{Object: obj})
+		if ids[id] {
+			return nil, fmt.Errorf("duplicate resource %s in manifests of %s", id, dir)
+		}
+		ids[id] = true
+		spec.Resources = append(spec.Resources, models.Resource{
+			ID:         id,
+			Type:       runtime.Kubernetes,
+			Attributes: obj,
+		})
+	}
+
+	ordered, err := appgenerator.NewOrderedResourcesGenerator()
+	if err != nil {
+		return nil, err
+	}
+	if err = ordered.Generate(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// manifestDir returns the absolute path of the manifest directory
+func manifestDir(o *generator.Options, project *projectstack.Project, stack *projectstack.Stack) (string, error) {
+	dir := stack.GetPath()
+	if dir == "" {
+		dir = o.WorkDir
+	}
+	if project.Generator == nil || project.Generator.Configs[PathConfig] == nil {
+		return dir, nil
+	}
+	path, ok := project.Generator.Configs[PathConfig].(string)
+	if !ok {
+		return "", fmt.Errorf("%s of the Manifest generator must be a string", PathConfig)
+	}
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Join(dir, path), nil
+}
+
+// kustomization returns whether there is a kustomization file in the directory
+func kustomization(dir string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// kustomize builds the kustomization in the directory
+func kustomize(dir string) ([]map[string]interface{}, error) {
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := k.Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("kustomize build %s failed: %v", dir, err)
+	}
+	out, err := resMap.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+	return decodeObjects(out)
+}
+
+// readManifests reads objects in YAML files under the directory in lexical order. Hidden directories and files of
+// Kusion are skipped
+func readManifests(dir string) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == projectstack.CiTestDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if (ext != ".yaml" && ext != ".yml") || ignoredFiles[d.Name()] {
+			return nil
+		}
+
+		log.Debugf("read manifests in %s", path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		objs, err := decodeObjects(content)
+		if err != nil {
+			return fmt.Errorf("invalid manifests in %s: %v", path, err)
+		}
+		objects = append(objects, objs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// decodeObjects decodes Kubernetes objects in the multi-document YAML, and items of lists are flattened
+func decodeObjects(content []byte) ([]map[string]interface{}, error) {
+	// yaml.v3 is used to be consistent with specs generated by KCL
+	decoder := yamlv3.NewDecoder(bytes.NewReader(content))
+	var objects []map[string]interface{}
+	for {
+		obj := map[string]interface{}{}
+		err := decoder.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(obj) == 0 {
+			continue
+		}
+
+		u := &unstructured.Unstructured{Object: obj}
+		if u.GetAPIVersion() == "" || u.GetKind() == "" {
+			return nil, errors.New("apiVersion and kind are required in Kubernetes manifests")
+		}
+		if u.IsList() {
+			items, _ := obj["items"].([]interface{})
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					objects = append(objects, m)
+				}
+			}
+			continue
+		}
+		if u.GetName() == "" {
+			return nil, fmt.Errorf("no name in the %s manifest", u.GetKind())
+		}
+		objects = append(objects, obj)
+	}
+}
@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kusionstack.io/kusion/pkg/engine/runtime"
+	"kusionstack.io/kusion/pkg/generator"
+	"kusionstack.io/kusion/pkg/models"
+	"kusionstack.io/kusion/pkg/projectstack"
+)
+
+func newProject(configs map[string]interface{}) *projectstack.Project {
+	return &projectstack.Project{ProjectConfiguration: projectstack.ProjectConfiguration{
+		Name:      "demo",
+		Generator: &projectstack.GeneratorConfig{Type: projectstack.ManifestGenerator, Configs: configs},
+	}}
+}
+
+func resourceIDs(spec *models.Spec) map[string][]string {
+	ids := map[string][]string{}
+	for _, r := range spec.Resources {
+		ids[r.ID] = r.DependsOn
+	}
+	return ids
+}
+
+func TestGenerator_GenerateSpec(t *testing.T) {
+	abs, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+	g := &Generator{}
+
+	t.Run("plain manifests", func(t *testing.T) {
+		stack := &projectstack.Stack{Path: filepath.Join(abs, "plain")}
+		spec, err := g.GenerateSpec(&generator.Options{}, newProject(nil), stack)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"v1:Namespace:demo":             nil,
+			"apps/v1:Deployment:demo:nginx": {"v1:Namespace:demo", "v1:Service:demo:nginx"},
+			"v1:Service:demo:nginx":         {"v1:Namespace:demo"},
+		}, resourceIDs(spec))
+		for _, r := range spec.Resources {
+			assert.Equal(t, runtime.Kubernetes, r.Type)
+		}
+	})
+
+	t.Run("kustomization", func(t *testing.T) {
+		spec, err := g.GenerateSpec(&generator.Options{WorkDir: abs}, newProject(map[string]interface{}{PathConfig: "kustomize"}), &projectstack.Stack{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"v1:Namespace:demo":             nil,
+			"apps/v1:Deployment:demo:nginx": {"v1:Namespace:demo"},
+		}, resourceIDs(spec))
+		for _, r := range spec.Resources {
+			if r.ID == "apps/v1:Deployment:demo:nginx" {
+				assert.Equal(t, 3, r.Attributes["spec"].(map[string]interface{})["replicas"])
+			}
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, err := g.GenerateSpec(&generator.Options{WorkDir: abs}, newProject(map[string]interface{}{PathConfig: 1}), &projectstack.Stack{})
+		assert.EqualError(t, err, "path of the Manifest generator must be a string")
+	})
+}
+
+func TestDecodeObjects(t *testing.T) {
+	_, err := decodeObjects([]byte("kind: ConfigMap\nmetadata:\n  name: foo\n"))
+	assert.EqualError(t, err, "apiVersion and kind are required in Kubernetes manifests")
+
+	_, err = decodeObjects([]byte("apiVersion: v1\nkind: ConfigMap\n"))
+	assert.EqualError(t, err, "no name in the ConfigMap manifest")
+
+	objects, err := decodeObjects([]byte("---\n# empty\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n"))
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
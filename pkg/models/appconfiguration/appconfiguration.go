@@ -1,6 +1,7 @@
 package appconfiguration
 
 import (
+	"kusionstack.io/kusion/pkg/models"
 	"kusionstack.io/kusion/pkg/models/appconfiguration/accessories/database"
 	"kusionstack.io/kusion/pkg/models/appconfiguration/monitoring"
 	"kusionstack.io/kusion/pkg/models/appconfiguration/trait"
@@ -43,4 +44,8 @@ type AppConfiguration struct {
 	// as key-value pairs to resources.
 	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+
+	// Lifecycle customizes how all resources generated for the application are operated,
+	// such as preventing them from being destroyed.
+	Lifecycle *models.Lifecycle `json:"lifecycle,omitempty" yaml:"lifecycle,omitempty"`
 }
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ClusterKey is the key of the Kubernetes cluster in Resource.Extensions
+const ClusterKey = "cluster"
+
+// Cluster selects the Kubernetes cluster to operate resources in by a kubeconfig file and a context in it
+type Cluster struct {
+	// KubeConfig is the path of the kubeconfig file, and relative paths are relative to the stack directory.
+	// The kubeconfig from $KUBECONFIG or ~/.kube/config is used if it is empty
+	KubeConfig string `json:"kubeConfig,omitempty" yaml:"kubeConfig,omitempty"`
+
+	// Context is the context in the kubeconfig file. The current context is used if it is empty
+	Context string `json:"context,omitempty" yaml:"context,omitempty"`
+}
+
+// IsZero returns whether the cluster is the default one
+func (c *Cluster) IsZero() bool {
+	return c == nil || (c.KubeConfig == "" && c.Context == "")
+}
+
+// String returns the name of the cluster in IDs of resources, which is the context, the kubeconfig path, or both
+// joined by "#" like the kubeconfig path "./prod.yaml" and the context "admin" are "./prod.yaml#admin"
+func (c *Cluster) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	if c.KubeConfig == "" {
+		return c.Context
+	}
+	if c.Context == "" {
+		return c.KubeConfig
+	}
+	return c.KubeConfig + "#" + c.Context
+}
+
+// Override returns a copy of the cluster with non-empty settings of the other cluster
+func (c *Cluster) Override(other *Cluster) *Cluster {
+	out := &Cluster{}
+	if c != nil {
+		*out = *c
+	}
+	if other == nil {
+		return out
+	}
+	if other.KubeConfig != "" {
+		out.KubeConfig = other.KubeConfig
+	}
+	if other.Context != "" {
+		out.Context = other.Context
+	}
+	return out
+}
+
+// Cluster returns the cluster of the resource in Extensions, and nil if there is no setting
+func (r *Resource) Cluster() (*Cluster, error) {
+	v, ok := r.Extensions[ClusterKey]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cluster{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid cluster of resource %s: %v", r.ID, err)
+	}
+	return c, nil
+}
+
+// SetCluster saves the cluster in Extensions of the resource
+func (r *Resource) SetCluster(c *Cluster) {
+	v := map[string]interface{}{}
+	if c.KubeConfig != "" {
+		v["kubeConfig"] = c.KubeConfig
+	}
+	if c.Context != "" {
+		v["context"] = c.Context
+	}
+	if r.Extensions == nil {
+		r.Extensions = map[string]interface{}{}
+	}
+	r.Extensions[ClusterKey] = v
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LifecycleKey is the key of lifecycle settings in Resource.Extensions
+const LifecycleKey = "lifecycle"
+
+// DeletionPolicy decides what to do with the actual resource when it is deleted from the stack
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the actual resource. It is the default policy
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyAbandon only drops the resource from the State, and keeps the actual resource as it is
+	DeletionPolicyAbandon DeletionPolicy = "Abandon"
+)
+
+// Lifecycle customizes how a resource is operated during its lifecycle
+type Lifecycle struct {
+	// CreateBeforeDestroy creates the new resource before deleting the old one when the resource must be replaced.
+	// The old resource is deleted first by default
+	CreateBeforeDestroy bool `json:"createBeforeDestroy,omitempty" yaml:"createBeforeDestroy,omitempty"`
+
+	// PreventDestroy refuses any operation that deletes the resource, including destroying and replacing it
+	PreventDestroy bool `json:"preventDestroy,omitempty" yaml:"preventDestroy,omitempty"`
+
+	// IgnoreChanges contains paths of fields excluded from the diff, such as "spec.replicas"
+	IgnoreChanges []string `json:"ignoreChanges,omitempty" yaml:"ignoreChanges,omitempty"`
+
+	// DeletionPolicy decides what to do with the actual resource when it is deleted. Default to DeletionPolicyDelete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty" yaml:"deletionPolicy,omitempty"`
+}
+
+// Validate returns an error if any setting is not supported
+func (l *Lifecycle) Validate() error {
+	switch l.DeletionPolicy {
+	case "", DeletionPolicyDelete, DeletionPolicyAbandon:
+		return nil
+	default:
+		return fmt.Errorf("invalid deletionPolicy %s, supported policies: %s, %s",
+			l.DeletionPolicy, DeletionPolicyDelete, DeletionPolicyAbandon)
+	}
+}
+
+// Abandon returns whether the actual resource should be kept when it is deleted
+func (l *Lifecycle) Abandon() bool {
+	return l.DeletionPolicy == DeletionPolicyAbandon
+}
+
+// Lifecycle returns lifecycle settings of the resource in Extensions. A zero value is returned if there is no setting
+func (r *Resource) Lifecycle() (*Lifecycle, error) {
+	l := &Lifecycle{}
+	v, ok := r.Extensions[LifecycleKey]
+	if !ok || v == nil {
+		return l, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("invalid lifecycle of resource %s: %v", r.ID, err)
+	}
+	if err = l.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid lifecycle of resource %s: %v", r.ID, err)
+	}
+	return l, nil
+}
+
+// SetLifecycle saves the lifecycle settings in Extensions of the resource
+func (r *Resource) SetLifecycle(l *Lifecycle) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	v := map[string]interface{}{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if r.Extensions == nil {
+		r.Extensions = map[string]interface{}{}
+	}
+	r.Extensions[LifecycleKey] = v
+	return nil
+}
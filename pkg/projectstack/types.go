@@ -8,6 +8,7 @@ import (
 
 	"kusionstack.io/kusion/pkg/engine/backend"
 	"kusionstack.io/kusion/pkg/log"
+	"kusionstack.io/kusion/pkg/models"
 	"kusionstack.io/kusion/pkg/vals"
 )
 
@@ -27,6 +28,7 @@ const (
 	KclFile                                 = "kcl.yaml"
 	KCLGenerator              GeneratorType = "KCL"
 	AppConfigurationGenerator GeneratorType = "AppConfiguration"
+	ManifestGenerator         GeneratorType = "Manifest"
 	PodMonitorType            MonitorType   = "Pod"
 	ServiceMonitorType        MonitorType   = "Service"
 )
@@ -48,6 +50,13 @@ type PrometheusConfig struct {
 	MonitorType  MonitorType `yaml:"monitorType,omitempty" json:"monitorType,omitempty"`
 }
 
+// PluginsConfig represent runtime plugin configs saved in project.yaml
+type PluginsConfig struct {
+	// Dir is the directory to discover runtime plugins in, and plugins in it take precedence over those in the
+	// plugins directory of the Kusion data folder. Relative paths are relative to the project directory
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+}
+
 // ProjectConfiguration is the project configuration
 type ProjectConfiguration struct {
 	// Project name
@@ -67,6 +76,9 @@ type ProjectConfiguration struct {
 
 	// Secret stores
 	SecretStores *vals.SecretStores `json:"secret_stores,omitempty" yaml:"secret_stores,omitempty"`
+
+	// Runtime plugin configs
+	Plugins *PluginsConfig `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 type Project struct {
@@ -131,7 +143,55 @@ func (p *Project) TableReport() string {
 
 // StackConfiguration is the stack configuration
 type StackConfiguration struct {
-	Name string `json:"name" yaml:"name"` // Stack name
+	Name  string       `json:"name" yaml:"name"`                       // Stack name
+	Retry *RetryConfig `json:"retry,omitempty" yaml:"retry,omitempty"` // Retry configs of transient runtime errors
+
+	// Outputs are values published by the stack after applying, keyed by output names. A value is either a
+	// literal or a reference to a resource attribute like "$kusion_path.v1:Service:default:nginx.spec.clusterIP"
+	Outputs map[string]string `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+
+	// Cluster is the Kubernetes cluster to operate Kubernetes resources of the stack in, and resources can select
+	// another cluster in their Extensions
+	Cluster *models.Cluster `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+
+	// ServerSideApply applies Kubernetes resources of the stack with server-side apply instead of client-side
+	// three-way merge patches
+	ServerSideApply *ServerSideApplyConfig `json:"serverSideApply,omitempty" yaml:"serverSideApply,omitempty"`
+}
+
+// DefaultFieldManager is the field manager of Kubernetes resources applied by Kusion
+const DefaultFieldManager = "kusion"
+
+// ServerSideApplyConfig represent configs of applying Kubernetes resources with server-side apply. Fields of
+// resources applied client-side before are migrated to the field manager on the first server-side apply
+type ServerSideApplyConfig struct {
+	// Enabled turns on server-side apply
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// FieldManager is the name of the manager owning fields applied by Kusion. Default to "kusion"
+	FieldManager string `json:"fieldManager,omitempty" yaml:"fieldManager,omitempty"`
+}
+
+// GetFieldManager returns the field manager of server-side apply
+func (c *ServerSideApplyConfig) GetFieldManager() string {
+	if c == nil || c.FieldManager == "" {
+		return DefaultFieldManager
+	}
+	return c.FieldManager
+}
+
+// RetryConfig represent configs of retrying resources failed with transient errors, such as throttling of
+// the Kubernetes API server or Terraform providers. Durations are strings like "500ms" and "1m". Resources are
+// not retried if a stack doesn't configure retry
+type RetryConfig struct {
+	// MaxRetries is the max number of retries of each runtime call. Default to 3, and 0 disables retrying
+	MaxRetries *int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+
+	// InitialBackoff is the time to wait before the first retry, which is doubled for each subsequent retry
+	InitialBackoff string `json:"initialBackoff,omitempty" yaml:"initialBackoff,omitempty"`
+
+	// MaxBackoff is the max time to wait before a retry
+	MaxBackoff string `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
 }
 
 type Stack struct {
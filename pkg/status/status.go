@@ -27,6 +27,8 @@ const (
 	Internal         Code = "INTERNAL"
 	Unauthenticated  Code = "UNAUTHENTICATED"
 	IllegalManifest  Code = "ILLEGAL_MANIFEST"
+	Locked           Code = "LOCKED"
+	Throttled        Code = "THROTTLED"
 )
 
 type Status interface {
@@ -1,8 +1,11 @@
 package signals
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"kusionstack.io/kusion/pkg/log"
@@ -20,3 +23,38 @@ func HandleInterrupt() {
 		log.Info("Received termination, signaling shutdown, executing clean job")
 	}()
 }
+
+// NotifyContext returns a context canceled on the first interrupt or SIGTERM signal, so that running operations
+// can stop gracefully and save what they have done. The process exits immediately on the second signal.
+// Calling the returned stop function stops listening for signals
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	stopCh := make(chan os.Signal, 2)
+	doneCh := make(chan struct{})
+	signal.Notify(stopCh, shutdownSignals...)
+	go func() {
+		select {
+		case <-stopCh:
+			log.Info("Received termination, canceling the running operation")
+			fmt.Fprintln(os.Stderr, "\nInterrupting, waiting for resources in progress to stop. Press Ctrl-C again to exit immediately")
+			cancel()
+		case <-doneCh:
+			return
+		}
+		select {
+		case <-stopCh:
+			log.Info("Received termination again, exiting immediately")
+			os.Exit(1)
+		case <-doneCh:
+		}
+	}()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			signal.Stop(stopCh)
+			close(doneCh)
+			cancel()
+		})
+	}
+}